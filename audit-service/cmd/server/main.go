@@ -2,7 +2,7 @@ package main
 
 // @title Audit Service API
 // @version 1.0.0
-// @description A read-only microservice for accessing PowerPoint translation session audit logs
+// @description A microservice for recording and reading PowerPoint translation session audit logs
 // @termsOfService http://swagger.io/terms/
 
 // @contact.name API Support
@@ -22,15 +22,16 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"net/http"
+	"net/http/pprof"
 	"os"
 	"os/signal"
+	"path"
 	"syscall"
 	"time"
 
-	_ "audit-service/docs" // Import generated docs
+	"audit-service/docs"
 	"audit-service/internal/config"
 	"audit-service/internal/handlers"
 	"audit-service/internal/middleware"
@@ -39,6 +40,7 @@ import (
 	"audit-service/pkg/cache"
 	"audit-service/pkg/jwt"
 	"audit-service/pkg/logger"
+	"audit-service/pkg/version"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -54,15 +56,25 @@ func main() {
 	}
 
 	// Initialize logger
-	zapLogger, err := logger.New(cfg.LogLevel)
+	zapLogger, err := logger.New(cfg.LogLevel, cfg.LogFormat, logger.FileOptions{
+		Path:       cfg.LogFile,
+		MaxSizeMB:  cfg.LogMaxSizeMB,
+		MaxBackups: cfg.LogMaxBackups,
+		MaxAgeDays: cfg.LogMaxAgeDays,
+		Compress:   cfg.LogCompress,
+	})
 	if err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
 	defer zapLogger.Sync()
 
 	zapLogger.Info("starting audit service",
+		zap.String("version", version.Version),
+		zap.String("commit", version.Commit),
+		zap.String("bind_address", cfg.BindAddress),
 		zap.String("port", cfg.Port),
 		zap.String("log_level", cfg.LogLevel),
+		zap.String("log_format", cfg.LogFormat),
 	)
 
 	// Set Gin mode based on log level
@@ -72,32 +84,56 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	// Initialize dependencies
-	tokenValidator, err := jwt.NewTokenValidator(cfg.SupabaseJWTSecret)
+	// Initialize dependencies; a secret that isn't an RSA key is used for HMAC fallback
+	jwtSecret := cfg.SupabaseJWTSecret
+	if cfg.SupabaseJWTSecretBase64 {
+		if jwtSecret, err = jwt.DecodeBase64Secret(jwtSecret); err != nil {
+			zapLogger.Fatal("failed to decode SUPABASE_JWT_SECRET", zap.Error(err))
+		}
+	}
+	tokenValidator, err := jwt.NewTokenValidator(jwtSecret)
 	if err != nil {
 		zapLogger.Fatal("failed to initialize token validator", zap.Error(err))
 	}
 
-	// Set HMAC secret for fallback
-	jwt.SetHMACSecret(cfg.SupabaseJWTSecret)
-
 	tokenCache := cache.NewTokenCache(
 		cfg.CacheJWTTTL,
 		cfg.CacheShareTokenTTL,
 		cfg.CacheCleanupInterval,
 	)
+	tokenCache.SetExpirySkew(cfg.CacheExpirySkew)
+	tokenCache.SetLogger(zapLogger)
+
+	// Seed the JWT blocklist with revoked token IDs (jti)
+	for _, tokenID := range cfg.RevokedTokenIDs {
+		tokenCache.RevokeJWT(tokenID, time.Time{})
+	}
+
+	idempotencyStore := cache.NewIdempotencyStore(cfg.IdempotencyTTL, cfg.CacheCleanupInterval)
 
 	supabaseClient := repository.NewSupabaseClient(cfg, zapLogger)
 	auditRepo := repository.NewAuditRepository(supabaseClient, zapLogger)
-	auditService := service.NewAuditService(auditRepo, tokenCache, zapLogger)
-	auditHandler := handlers.NewAuditHandler(auditService, zapLogger)
+	auditService := service.NewAuditService(cfg, auditRepo, tokenCache, idempotencyStore, zapLogger)
+	auditHandler := handlers.NewAuditHandler(auditService, cfg.MaxResponseBytes, zapLogger)
+	debugHandler := handlers.NewDebugHandler(cfg, supabaseClient, auditRepo, zapLogger)
+	adminHandler := handlers.NewAdminHandler(tokenCache, zapLogger)
+	healthHandler := handlers.NewHealthHandler(cfg.ReadinessTimeout, []handlers.DependencyCheck{
+		{Name: "supabase", Check: supabaseClient.Ping},
+		{Name: "token_cache", Check: tokenCache.Ping},
+	}, zapLogger)
 
 	// Setup router
-	router := setupRouter(cfg, tokenValidator, tokenCache, auditRepo, auditHandler, zapLogger)
+	shutdownState := middleware.NewShutdownState()
+	router := setupRouter(cfg, tokenValidator, tokenCache, auditRepo, auditHandler, debugHandler, adminHandler, healthHandler, shutdownState, zapLogger)
+
+	// Only trust forwarding headers from the configured proxies when resolving the client IP
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		zapLogger.Fatal("invalid trusted proxies", zap.Error(err))
+	}
 
 	// Create server
 	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%s", cfg.Port),
+		Addr:    cfg.ListenAddr(),
 		Handler: router,
 	}
 
@@ -116,6 +152,11 @@ func main() {
 
 	zapLogger.Info("shutting down server...")
 
+	// Refuse new requests with 503 so load balancers stop routing here, and give
+	// them the drain delay to notice before the listener closes
+	shutdownState.Begin()
+	time.Sleep(cfg.ShutdownDrainDelay)
+
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -133,32 +174,113 @@ func setupRouter(
 	tokenCache *cache.TokenCache,
 	auditRepo repository.AuditRepository,
 	auditHandler *handlers.AuditHandler,
+	debugHandler *handlers.DebugHandler,
+	adminHandler *handlers.AdminHandler,
+	healthHandler *handlers.HealthHandler,
+	shutdownState *middleware.ShutdownState,
 	zapLogger *zap.Logger,
 ) *gin.Engine {
 	router := gin.New()
 
-	// Global middleware
+	// Keep the generated Swagger spec in line with where the API is mounted and what is running
+	docs.SwaggerInfo.BasePath = cfg.RoutePrefix + "/api/v1"
+	docs.SwaggerInfo.Version = version.Version
+
+	// Global middleware; panic stacks are only returned to clients at LOG_LEVEL=debug
 	router.Use(
-		gin.Recovery(),
+		middleware.Recovery(zapLogger, zapLogger.Core().Enabled(zap.DebugLevel)),
 		middleware.RequestID(),
-		middleware.Logger(zapLogger),
+		middleware.Logger(zapLogger, cfg.SlowRequestThreshold),
 		middleware.ErrorHandler(zapLogger),
+		middleware.RejectDuringShutdown(shutdownState),
 	)
+	if cfg.ServerTimingEnabled {
+		router.Use(middleware.ServerTiming())
+	}
+	if cfg.EnforceHTTPS {
+		// Probes hit the pod directly over plain HTTP
+		router.Use(middleware.RequireHTTPS(cfg.TrustedProxies,
+			cfg.RoutePrefix+"/health", cfg.RoutePrefix+"/health/live", cfg.RoutePrefix+"/health/ready"))
+	}
 
-	// Health check endpoint
-	router.GET("/health", handleHealth)
+	// All routes are mounted under the optional prefix
+	root := router.Group(cfg.RoutePrefix)
+
+	// Routes served without authentication
+	public := middleware.NewPublicRoutes()
+	registerPublic(root, public, http.MethodGet, "/health", handleHealth)
+	registerPublic(root, public, http.MethodHead, "/health", handleHealthProbe)
+	registerPublic(root, public, http.MethodOptions, "/health", handleHealthProbe)
+	registerPublic(root, public, http.MethodGet, "/health/live", healthHandler.Live)
+	registerPublic(root, public, http.MethodGet, "/health/ready", healthHandler.Ready)
+	registerPublic(root, public, http.MethodGet, "/version", handleVersion)
+	registerPublic(root, public, http.MethodGet, "/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	zapLogger.Debug("public routes registered", zap.Strings("routes", public.Patterns()))
+
+	// Debug and admin endpoints are only exposed when an admin token is configured
+	if cfg.AdminToken != "" {
+		adminAuth := middleware.AdminAuth(cfg.AdminToken, zapLogger)
+
+		debug := root.Group("/debug", adminAuth)
+		debug.GET("/config", debugHandler.GetConfig)
+		debug.GET("/metrics", debugHandler.GetMetrics)
+		if cfg.PprofEnabled {
+			registerPprof(debug.Group("/pprof"))
+		}
+
+		admin := root.Group("/admin", adminAuth)
+		admin.DELETE("/users/:userId/tokens", adminHandler.InvalidateUserTokens)
+	}
 
-	// API documentation
-	router.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// Users blocked for incident response, regardless of their JWT
+	deniedUsers := middleware.NewDeniedUsers(cfg.DeniedUserIDs)
 
 	// API v1 routes
-	v1 := router.Group("/api/v1")
+	v1 := root.Group("/api/v1")
 	{
+		// Session listing is per user rather than per session, so it takes a JWT or the admin token
+		v1.GET("/sessions", middleware.UserAuth(tokenValidator, tokenCache, cfg.AdminToken, cfg.JWTMaxLength, deniedUsers, zapLogger), auditHandler.ListSessions)
+
 		// Protected routes
+		sessionAuth := middleware.Auth(tokenValidator, tokenCache, auditRepo, cfg.ShareTokensEnabled, cfg.JWTMaxLength, deniedUsers, zapLogger)
+		// Share tokens only work from SHARE_ALLOWED_ORIGINS, when set
+		shareOrigin := middleware.ShareOrigin(cfg.ShareAllowedOrigins, zapLogger)
+		historyHandlers := []gin.HandlerFunc{}
+		if cfg.AccessAuditEnabled {
+			historyHandlers = append(historyHandlers, middleware.AccessAudit(zapLogger))
+		}
+		// History reads, single-session or batch, count against each share token's row cap
+		sessionHistoryHandlers := historyHandlers
+		if cfg.ShareTokenMaxRows > 0 {
+			quota := cache.NewRowQuota(cfg.ShareTokenMaxRows, cfg.ShareTokenRowsWindow, cfg.CacheCleanupInterval)
+			sessionHistoryHandlers = append([]gin.HandlerFunc{middleware.ShareRowLimit(quota, zapLogger)}, historyHandlers...)
+		}
+
+		// For gateways that strip the session segment, the session ID comes from X-Session-Id
+		v1.GET("/history", append([]gin.HandlerFunc{sessionAuth, shareOrigin}, append(sessionHistoryHandlers, auditHandler.GetHistory)...)...)
+
+		// Multi-session dashboards read several shared sessions at once, one share token per session
+		if cfg.ShareTokensEnabled {
+			multiShareAuth := middleware.MultiShareAuth(tokenCache, auditRepo, cfg.MaxBatchSessions, zapLogger)
+			v1.GET("/history/batch", append([]gin.HandlerFunc{multiShareAuth, shareOrigin}, append(sessionHistoryHandlers, auditHandler.GetHistoryBatch)...)...)
+		}
+
+		// Backfilling history is an admin operation, so it takes the admin token rather than a JWT
+		if cfg.AdminToken != "" {
+			v1.POST("/sessions/:sessionId/history/import", middleware.AdminAuth(cfg.AdminToken, zapLogger), middleware.RequireNDJSON(), middleware.BodyLimit(cfg.MaxImportBytes), auditHandler.ImportEntries)
+		}
+
 		sessions := v1.Group("/sessions")
-		sessions.Use(middleware.Auth(tokenValidator, tokenCache, auditRepo, zapLogger))
+		sessions.Use(sessionAuth, shareOrigin)
 		{
-			sessions.GET("/:sessionId/history", auditHandler.GetHistory)
+			sessions.GET("/:sessionId/history", append(sessionHistoryHandlers, auditHandler.GetHistory)...)
+			sessions.GET("/:sessionId/summary", append(historyHandlers, auditHandler.GetSummary)...)
+			sessions.GET("/:sessionId/history/:entryId", append(sessionHistoryHandlers, auditHandler.GetEntry)...)
+			sessions.GET("/:sessionId/shares", auditHandler.ListShares)
+			bodyLimit := middleware.BodyLimit(cfg.MaxBodyBytes)
+			requireJSON := middleware.RequireJSON()
+			sessions.POST("/:sessionId/history", requireJSON, bodyLimit, auditHandler.CreateEntry)
+			sessions.POST("/:sessionId/history/batch", requireJSON, bodyLimit, auditHandler.CreateEntries)
 		}
 	}
 
@@ -169,11 +291,44 @@ func setupRouter(
 	return router
 }
 
+// registerPprof mounts the net/http/pprof handlers on group. Named profiles
+// are routed explicitly because pprof.Index only resolves them under the
+// unprefixed /debug/pprof/ path. The package's DefaultServeMux registrations
+// are never served, since the server only uses this router.
+func registerPprof(group *gin.RouterGroup) {
+	group.GET("/", gin.WrapF(pprof.Index))
+	group.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	group.GET("/profile", gin.WrapF(pprof.Profile))
+	group.GET("/symbol", gin.WrapF(pprof.Symbol))
+	group.POST("/symbol", gin.WrapF(pprof.Symbol))
+	group.GET("/trace", gin.WrapF(pprof.Trace))
+	for _, name := range []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"} {
+		group.GET("/"+name, gin.WrapH(pprof.Handler(name)))
+	}
+}
+
 func handleHealth(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "healthy",
 		"service": "audit-service",
-		"version": "1.0.0",
+		"version": version.Version,
 		"time":    time.Now().UTC().Format(time.RFC3339),
 	})
 }
+
+// handleVersion reports the running build
+func handleVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, version.Get())
+}
+
+// registerPublic mounts a handler that needs no authentication and records it as public
+func registerPublic(group *gin.RouterGroup, public *middleware.PublicRoutes, method, relativePath string, handler gin.HandlerFunc) {
+	group.Handle(method, relativePath, handler)
+	public.Add(path.Join(group.BasePath(), relativePath))
+}
+
+// handleHealthProbe answers HEAD and OPTIONS load balancer probes without a body
+func handleHealthProbe(c *gin.Context) {
+	c.Header("Allow", "GET, HEAD, OPTIONS")
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"audit-service/internal/config"
+	"audit-service/internal/handlers"
+	"audit-service/internal/middleware"
+	"audit-service/pkg/version"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestRouter(cfg *config.Config) *gin.Engine {
+	logger := zap.NewNop()
+	return setupRouter(
+		cfg,
+		nil,
+		nil,
+		nil,
+		handlers.NewAuditHandler(nil, cfg.MaxResponseBytes, logger),
+		handlers.NewDebugHandler(cfg, nil, nil, logger),
+		handlers.NewAdminHandler(nil, logger),
+		handlers.NewHealthHandler(time.Second, nil, logger),
+		middleware.NewShutdownState(),
+		logger,
+	)
+}
+
+func TestSetupRouter_RoutePrefix(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		routePrefix    string
+		path           string
+		expectedStatus int
+	}{
+		{
+			name:           "prefixed_health",
+			routePrefix:    "/audit",
+			path:           "/audit/health",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unprefixed_health_not_found",
+			routePrefix:    "/audit",
+			path:           "/health",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "no_prefix_health",
+			routePrefix:    "",
+			path:           "/health",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(&config.Config{RoutePrefix: tt.routePrefix})
+
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestSetupRouter_HealthMethods(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		method       string
+		expectedBody bool
+	}{
+		{name: "get", method: http.MethodGet, expectedBody: true},
+		{name: "head", method: http.MethodHead, expectedBody: false},
+		{name: "options", method: http.MethodOptions, expectedBody: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(&config.Config{})
+
+			req, _ := http.NewRequest(tt.method, "/health", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			if tt.expectedBody {
+				assert.Contains(t, w.Body.String(), "healthy")
+			} else {
+				assert.Empty(t, w.Body.String())
+				assert.Equal(t, "GET, HEAD, OPTIONS", w.Header().Get("Allow"))
+			}
+		})
+	}
+}
+
+func TestSetupRouter_HealthProbes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newTestRouter(&config.Config{RoutePrefix: "/audit"})
+
+	for _, path := range []string{"/audit/health/live", "/audit/health/ready"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, path)
+	}
+}
+
+func TestSetupRouter_EnforceHTTPS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newTestRouter(&config.Config{RoutePrefix: "/audit", EnforceHTTPS: true, TrustedProxies: []string{"192.0.2.1"}})
+
+	tests := []struct {
+		path           string
+		forwardedProto string
+		expectedStatus int
+	}{
+		{path: "/audit/version", forwardedProto: "http", expectedStatus: http.StatusBadRequest},
+		{path: "/audit/version", forwardedProto: "https", expectedStatus: http.StatusOK},
+		{path: "/audit/health", forwardedProto: "http", expectedStatus: http.StatusOK},
+		{path: "/audit/health/live", forwardedProto: "http", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set("X-Forwarded-Proto", tt.forwardedProto)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.expectedStatus, w.Code, tt.path+" over "+tt.forwardedProto)
+	}
+}
+
+func TestSetupRouter_VersionIsPublic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newTestRouter(&config.Config{RoutePrefix: "/audit"})
+
+	req, _ := http.NewRequest("GET", "/audit/version", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"version":"`+version.Version+`","commit":"`+version.Commit+`","buildTime":"`+version.BuildTime+`"}`, w.Body.String())
+}
+
+func TestHandleHealth_ReportsBuildVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	original := version.Version
+	version.Version = "2.3.4-test"
+	defer func() { version.Version = original }()
+
+	router := newTestRouter(&config.Config{})
+
+	req, _ := http.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "2.3.4-test", body["version"])
+}
+
+func TestSetupRouter_Pprof(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		enabled        bool
+		routePrefix    string
+		path           string
+		authorization  string
+		expectedStatus int
+	}{
+		{name: "disabled_index", path: "/debug/pprof/", authorization: "Bearer admin-secret", expectedStatus: http.StatusNotFound},
+		{name: "disabled_heap", path: "/debug/pprof/heap", authorization: "Bearer admin-secret", expectedStatus: http.StatusNotFound},
+		{name: "enabled_index", enabled: true, path: "/debug/pprof/", authorization: "Bearer admin-secret", expectedStatus: http.StatusOK},
+		{name: "enabled_heap", enabled: true, path: "/debug/pprof/heap", authorization: "Bearer admin-secret", expectedStatus: http.StatusOK},
+		{name: "enabled_heap_with_prefix", enabled: true, routePrefix: "/audit", path: "/audit/debug/pprof/heap", authorization: "Bearer admin-secret", expectedStatus: http.StatusOK},
+		{name: "enabled_without_admin_token", enabled: true, path: "/debug/pprof/", expectedStatus: http.StatusUnauthorized},
+		{name: "enabled_with_wrong_token", enabled: true, path: "/debug/pprof/", authorization: "Bearer wrong", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(&config.Config{AdminToken: "admin-secret", PprofEnabled: tt.enabled, RoutePrefix: tt.routePrefix})
+
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestSetupRouter_ReservedSessionSegments(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		expectedStatus  int
+		expectedMessage string
+	}{
+		// A route word in the session position is rejected before authentication
+		{name: "summary_as_session", method: "GET", path: "/api/v1/sessions/summary/history", expectedStatus: http.StatusBadRequest, expectedMessage: `"summary" is a reserved path segment, not a session ID`},
+		{name: "contributors_as_session", method: "GET", path: "/api/v1/sessions/contributors/summary", expectedStatus: http.StatusBadRequest, expectedMessage: `"contributors" is a reserved path segment, not a session ID`},
+		{name: "case_insensitive", method: "POST", path: "/api/v1/sessions/History/history", expectedStatus: http.StatusBadRequest, expectedMessage: `"History" is a reserved path segment, not a session ID`},
+		// A real session ID still reaches the session routes, which then require a token
+		{name: "session_summary", method: "GET", path: "/api/v1/sessions/550e8400-e29b-41d4-a716-446655440000/summary", expectedStatus: http.StatusUnauthorized},
+		{name: "session_history", method: "GET", path: "/api/v1/sessions/550e8400-e29b-41d4-a716-446655440000/history", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(&config.Config{})
+
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedMessage != "" {
+				var body struct {
+					Message string            `json:"message"`
+					Details map[string]string `json:"details"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Equal(t, tt.expectedMessage, body.Message)
+				assert.Equal(t, "sessionId", body.Details["field"])
+			}
+		})
+	}
+}
@@ -2,6 +2,10 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -10,29 +14,189 @@ import (
 // Config holds all configuration for the audit service
 type Config struct {
 	// Server configuration
-	Port     string `mapstructure:"PORT"`
-	LogLevel string `mapstructure:"LOG_LEVEL"`
+	Port        string `mapstructure:"PORT"`
+	BindAddress string `mapstructure:"BIND_ADDRESS"`
+	LogLevel    string `mapstructure:"LOG_LEVEL"`
+	LogFormat   string `mapstructure:"LOG_FORMAT"`
+	RoutePrefix string `mapstructure:"ROUTE_PREFIX"`
+
+	// How long to keep refusing requests with 503 before closing the listener on shutdown
+	ShutdownDrainDelay time.Duration `mapstructure:"SHUTDOWN_DRAIN_DELAY"`
+
+	// Proxies (IPs or CIDRs) whose forwarding headers are trusted for the client IP; empty trusts none
+	TrustedProxies []string `mapstructure:"TRUSTED_PROXIES"`
+
+	// Refuse requests whose X-Forwarded-Proto (from a trusted proxy) or connection is not https
+	EnforceHTTPS bool `mapstructure:"ENFORCE_HTTPS"`
+
+	// Optional rotated log file, written in addition to stdout
+	LogFile       string `mapstructure:"LOG_FILE"`
+	LogMaxSizeMB  int    `mapstructure:"LOG_MAX_SIZE_MB"`
+	LogMaxBackups int    `mapstructure:"LOG_MAX_BACKUPS"`
+	LogMaxAgeDays int    `mapstructure:"LOG_MAX_AGE_DAYS"`
+	LogCompress   bool   `mapstructure:"LOG_COMPRESS"`
+
+	// Requests slower than this are logged as slow; zero disables the check
+	SlowRequestThreshold time.Duration `mapstructure:"SLOW_REQUEST_THRESHOLD"`
+
+	// Send Server-Timing headers with Supabase and total durations; exposes internal timings
+	ServerTimingEnabled bool `mapstructure:"SERVER_TIMING_ENABLED"`
 
 	// Supabase configuration
 	SupabaseURL            string `mapstructure:"SUPABASE_URL"`
+	SupabaseReadURL        string `mapstructure:"SUPABASE_READ_URL"`
 	SupabaseAnonKey        string `mapstructure:"SUPABASE_ANON_KEY"`
 	SupabaseServiceRoleKey string `mapstructure:"SUPABASE_SERVICE_ROLE_KEY"`
 	SupabaseJWTSecret      string `mapstructure:"SUPABASE_JWT_SECRET"`
+	SupabaseCountMode      string `mapstructure:"SUPABASE_COUNT_MODE"`
+
+	// Base64-decode SUPABASE_JWT_SECRET before using it as an HMAC key
+	SupabaseJWTSecretBase64 bool `mapstructure:"SUPABASE_JWT_SECRET_BASE64"`
 
 	// HTTP Client configuration
 	HTTPTimeout         time.Duration `mapstructure:"HTTP_TIMEOUT"`
 	HTTPMaxIdleConns    int           `mapstructure:"HTTP_MAX_IDLE_CONNS"`
 	HTTPMaxConnsPerHost int           `mapstructure:"HTTP_MAX_CONNS_PER_HOST"`
 	HTTPIdleConnTimeout time.Duration `mapstructure:"HTTP_IDLE_CONN_TIMEOUT"`
+	HTTPMaxConcurrent   int           `mapstructure:"HTTP_MAX_CONCURRENT"`
+	HTTPUserAgent       string        `mapstructure:"HTTP_USER_AGENT"`
+	QueryTimeout        time.Duration `mapstructure:"QUERY_TIMEOUT"`
+	ReadinessTimeout    time.Duration `mapstructure:"READINESS_TIMEOUT"`
 
 	// Cache configuration
-	CacheJWTTTL          time.Duration `mapstructure:"CACHE_JWT_TTL"`
-	CacheShareTokenTTL   time.Duration `mapstructure:"CACHE_SHARE_TOKEN_TTL"`
-	CacheCleanupInterval time.Duration `mapstructure:"CACHE_CLEANUP_INTERVAL"`
+	CacheJWTTTL           time.Duration `mapstructure:"CACHE_JWT_TTL"`
+	CacheShareTokenTTL    time.Duration `mapstructure:"CACHE_SHARE_TOKEN_TTL"`
+	CacheCleanupInterval  time.Duration `mapstructure:"CACHE_CLEANUP_INTERVAL"`
+	CacheExpirySkew       time.Duration `mapstructure:"CACHE_EXPIRY_SKEW"`
+	IdempotencyTTL        time.Duration `mapstructure:"IDEMPOTENCY_TTL"`
+	ServeStale            bool          `mapstructure:"SERVE_STALE"`
+	StaleTTL              time.Duration `mapstructure:"STALE_TTL"`
+	StaleCacheMaxSessions int           `mapstructure:"STALE_CACHE_MAX_SESSIONS"`
+
+	// Serve repeated history reads from memory, for at most ResponseCacheSessions
+	// sessions, until the TTL passes or the session is written to
+	ResponseCacheEnabled  bool          `mapstructure:"RESPONSE_CACHE_ENABLED"`
+	ResponseCacheTTL      time.Duration `mapstructure:"RESPONSE_CACHE_TTL"`
+	ResponseCacheSessions int           `mapstructure:"RESPONSE_CACHE_MAX_SESSIONS"`
+
+	// History reads with wait and sinceId hold for up to LongPollMaxWait, counting
+	// new entries every LongPollInterval; zero disables long polling
+	LongPollMaxWait  time.Duration `mapstructure:"LONG_POLL_MAX_WAIT"`
+	LongPollInterval time.Duration `mapstructure:"LONG_POLL_INTERVAL"`
 
 	// Application configuration
-	MaxPageSize     int `mapstructure:"MAX_PAGE_SIZE"`
-	DefaultPageSize int `mapstructure:"DEFAULT_PAGE_SIZE"`
+	MaxPageSize      int   `mapstructure:"MAX_PAGE_SIZE"`
+	DefaultPageSize  int   `mapstructure:"DEFAULT_PAGE_SIZE"`
+	MaxBatchSize     int   `mapstructure:"MAX_BATCH_SIZE"`
+	MaxBatchSessions int   `mapstructure:"MAX_BATCH_SESSIONS"`
+	MaxImportLines   int   `mapstructure:"MAX_IMPORT_LINES"`
+	MaxImportBytes   int64 `mapstructure:"MAX_IMPORT_BYTES"`
+	MaxActionFilters int   `mapstructure:"MAX_ACTION_FILTERS"`
+	MaxBodyBytes     int64 `mapstructure:"MAX_BODY_BYTES"`
+	MaxDetailsBytes  int64 `mapstructure:"MAX_DETAILS_BYTES"`
+	// Upper bound on a serialized history page
+	MaxResponseBytes int64 `mapstructure:"MAX_RESPONSE_BYTES"`
+	// Add hasMore and offsetBeyondTotal to history pages
+	PaginationHints bool `mapstructure:"PAGINATION_HINTS"`
+	// Reject out-of-range limit and offset with 400 instead of clamping them
+	StrictPagination bool `mapstructure:"STRICT_PAGINATION"`
+
+	// Audit actions accepted in addition to the built-in vocabulary
+	ExtraAuditActions []string `mapstructure:"AUDIT_EXTRA_ACTIONS"`
+	// Actions only the session owner may see; share-token reviewers never get them
+	ShareHiddenActions []string `mapstructure:"SHARE_HIDDEN_ACTIONS"`
+	// Reject write details that don't match the schema registered for their action
+	DetailsSchemaValidation bool `mapstructure:"DETAILS_SCHEMA_VALIDATION"`
+
+	// Summary configuration
+	SummaryDefaultWindow time.Duration `mapstructure:"SUMMARY_DEFAULT_WINDOW"`
+	SummaryMaxWindow     time.Duration `mapstructure:"SUMMARY_MAX_WINDOW"`
+
+	// Security configuration
+	AccessAuditEnabled bool     `mapstructure:"ACCESS_AUDIT_ENABLED"`
+	ShareTokensEnabled bool     `mapstructure:"SHARE_TOKENS_ENABLED"`
+	JWTMaxLength       int      `mapstructure:"JWT_MAX_LENGTH"`
+	AdminToken         string   `mapstructure:"ADMIN_TOKEN"`
+	RevokedTokenIDs    []string `mapstructure:"REVOKED_TOKEN_IDS"`
+	DeniedUserIDs      []string `mapstructure:"DENIED_USER_IDS"`
+	AdminUserIDs       []string `mapstructure:"ADMIN_USER_IDS"`
+	// Serve Go profiles under /debug/pprof; requires ADMIN_TOKEN
+	PprofEnabled bool `mapstructure:"PPROF_ENABLED"`
+	// Answer 404 rather than 403 to non-owners so session IDs can't be enumerated
+	HideForbiddenSessions bool `mapstructure:"HIDE_FORBIDDEN_SESSIONS"`
+
+	// Rows a single share token may read per window before getting 429; zero disables the cap
+	ShareTokenMaxRows    int           `mapstructure:"SHARE_TOKEN_MAX_ROWS"`
+	ShareTokenRowsWindow time.Duration `mapstructure:"SHARE_TOKEN_ROWS_WINDOW"`
+
+	// Origins share tokens may be used from, per the Origin or Referer header; empty allows any
+	ShareAllowedOrigins []string `mapstructure:"SHARE_ALLOWED_ORIGINS"`
+}
+
+// PublicConfig is the subset of Config that is safe to expose for troubleshooting.
+// Fields are listed explicitly so new secrets are never exposed by accident.
+type PublicConfig struct {
+	Port                    string   `json:"port"`
+	BindAddress             string   `json:"bind_address"`
+	TrustedProxies          []string `json:"trusted_proxies"`
+	EnforceHTTPS            bool     `json:"enforce_https"`
+	ShutdownDrainDelay      string   `json:"shutdown_drain_delay"`
+	LogLevel                string   `json:"log_level"`
+	LogFormat               string   `json:"log_format"`
+	RoutePrefix             string   `json:"route_prefix"`
+	LogFile                 string   `json:"log_file"`
+	SlowRequestThreshold    string   `json:"slow_request_threshold"`
+	ServerTimingEnabled     bool     `json:"server_timing_enabled"`
+	SupabaseURL             string   `json:"supabase_url"`
+	SupabaseReadURL         string   `json:"supabase_read_url"`
+	SupabaseCountMode       string   `json:"supabase_count_mode"`
+	SupabaseJWTSecretBase64 bool     `json:"supabase_jwt_secret_base64"`
+	HTTPTimeout             string   `json:"http_timeout"`
+	HTTPMaxIdleConns        int      `json:"http_max_idle_conns"`
+	HTTPMaxConnsPerHost     int      `json:"http_max_conns_per_host"`
+	HTTPIdleConnTimeout     string   `json:"http_idle_conn_timeout"`
+	HTTPMaxConcurrent       int      `json:"http_max_concurrent"`
+	HTTPUserAgent           string   `json:"http_user_agent"`
+	QueryTimeout            string   `json:"query_timeout"`
+	ReadinessTimeout        string   `json:"readiness_timeout"`
+	CacheJWTTTL             string   `json:"cache_jwt_ttl"`
+	CacheShareTokenTTL      string   `json:"cache_share_token_ttl"`
+	CacheCleanupInterval    string   `json:"cache_cleanup_interval"`
+	CacheExpirySkew         string   `json:"cache_expiry_skew"`
+	IdempotencyTTL          string   `json:"idempotency_ttl"`
+	ServeStale              bool     `json:"serve_stale"`
+	StaleTTL                string   `json:"stale_ttl"`
+	StaleCacheMaxSessions   int      `json:"stale_cache_max_sessions"`
+	ResponseCacheEnabled    bool     `json:"response_cache_enabled"`
+	ResponseCacheTTL        string   `json:"response_cache_ttl"`
+	ResponseCacheSessions   int      `json:"response_cache_max_sessions"`
+	LongPollMaxWait         string   `json:"long_poll_max_wait"`
+	LongPollInterval        string   `json:"long_poll_interval"`
+	MaxPageSize             int      `json:"max_page_size"`
+	DefaultPageSize         int      `json:"default_page_size"`
+	MaxBatchSize            int      `json:"max_batch_size"`
+	MaxBatchSessions        int      `json:"max_batch_sessions"`
+	MaxImportLines          int      `json:"max_import_lines"`
+	MaxImportBytes          int64    `json:"max_import_bytes"`
+	MaxActionFilters        int      `json:"max_action_filters"`
+	MaxBodyBytes            int64    `json:"max_body_bytes"`
+	MaxDetailsBytes         int64    `json:"max_details_bytes"`
+	MaxResponseBytes        int64    `json:"max_response_bytes"`
+	PaginationHints         bool     `json:"pagination_hints"`
+	StrictPagination        bool     `json:"strict_pagination"`
+	ExtraAuditActions       []string `json:"extra_audit_actions"`
+	ShareHiddenActions      []string `json:"share_hidden_actions"`
+	DetailsSchemaValidation bool     `json:"details_schema_validation"`
+	SummaryDefaultWindow    string   `json:"summary_default_window"`
+	SummaryMaxWindow        string   `json:"summary_max_window"`
+	AccessAuditEnabled      bool     `json:"access_audit_enabled"`
+	PprofEnabled            bool     `json:"pprof_enabled"`
+	HideForbiddenSessions   bool     `json:"hide_forbidden_sessions"`
+	ShareTokensEnabled      bool     `json:"share_tokens_enabled"`
+	JWTMaxLength            int      `json:"jwt_max_length"`
+	ShareTokenMaxRows       int      `json:"share_token_max_rows"`
+	ShareTokenRowsWindow    string   `json:"share_token_rows_window"`
+	ShareAllowedOrigins     []string `json:"share_allowed_origins"`
 }
 
 // Load reads configuration from environment variables
@@ -48,26 +212,99 @@ func Load() (*Config, error) {
 
 	// Set default values
 	viper.SetDefault("PORT", "4006")
+	viper.SetDefault("BIND_ADDRESS", "0.0.0.0")
+	viper.SetDefault("TRUSTED_PROXIES", "")
+	viper.SetDefault("ENFORCE_HTTPS", false)
+	viper.SetDefault("SHUTDOWN_DRAIN_DELAY", "0s")
 	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("LOG_FORMAT", "json")
+	viper.SetDefault("LOG_FILE", "")
+	viper.SetDefault("LOG_MAX_SIZE_MB", 100)
+	viper.SetDefault("LOG_MAX_BACKUPS", 5)
+	viper.SetDefault("LOG_MAX_AGE_DAYS", 28)
+	viper.SetDefault("LOG_COMPRESS", false)
+	viper.SetDefault("SLOW_REQUEST_THRESHOLD", "1s")
+	viper.SetDefault("SERVER_TIMING_ENABLED", false)
+	viper.SetDefault("ROUTE_PREFIX", "")
+
+	// Supabase defaults
+	viper.SetDefault("SUPABASE_READ_URL", "")
+	viper.SetDefault("SUPABASE_COUNT_MODE", "exact")
+	viper.SetDefault("SUPABASE_JWT_SECRET_BASE64", false)
 
 	// HTTP defaults
 	viper.SetDefault("HTTP_TIMEOUT", "30s")
 	viper.SetDefault("HTTP_MAX_IDLE_CONNS", 100)
 	viper.SetDefault("HTTP_MAX_CONNS_PER_HOST", 10)
 	viper.SetDefault("HTTP_IDLE_CONN_TIMEOUT", "90s")
+	viper.SetDefault("HTTP_MAX_CONCURRENT", 20)
+	viper.SetDefault("HTTP_USER_AGENT", "audit-service/1.0.0")
+	viper.SetDefault("QUERY_TIMEOUT", "10s")
+	viper.SetDefault("READINESS_TIMEOUT", "2s")
 
 	// Cache defaults
 	viper.SetDefault("CACHE_JWT_TTL", "5m")
 	viper.SetDefault("CACHE_SHARE_TOKEN_TTL", "1m")
 	viper.SetDefault("CACHE_CLEANUP_INTERVAL", "10m")
+	viper.SetDefault("CACHE_EXPIRY_SKEW", "5s")
+	viper.SetDefault("IDEMPOTENCY_TTL", "24h")
+	viper.SetDefault("SERVE_STALE", false)
+	viper.SetDefault("STALE_TTL", "1m")
+	viper.SetDefault("STALE_CACHE_MAX_SESSIONS", 1000)
+	viper.SetDefault("RESPONSE_CACHE_ENABLED", false)
+	viper.SetDefault("RESPONSE_CACHE_TTL", "5s")
+	viper.SetDefault("RESPONSE_CACHE_MAX_SESSIONS", 1000)
+	viper.SetDefault("LONG_POLL_MAX_WAIT", "30s")
+	viper.SetDefault("LONG_POLL_INTERVAL", "1s")
 
 	// Pagination defaults
 	viper.SetDefault("MAX_PAGE_SIZE", 100)
 	viper.SetDefault("DEFAULT_PAGE_SIZE", 50)
+	viper.SetDefault("MAX_BATCH_SIZE", 100)
+	viper.SetDefault("MAX_BATCH_SESSIONS", 10)
+	viper.SetDefault("MAX_IMPORT_LINES", 10000)
+	viper.SetDefault("MAX_IMPORT_BYTES", 32<<20)
+	viper.SetDefault("MAX_ACTION_FILTERS", 10)
+	viper.SetDefault("PAGINATION_HINTS", false)
+	viper.SetDefault("STRICT_PAGINATION", false)
+	viper.SetDefault("MAX_BODY_BYTES", 1<<20)
+	viper.SetDefault("MAX_DETAILS_BYTES", 64<<10)
+	viper.SetDefault("MAX_RESPONSE_BYTES", 10<<20)
+	viper.SetDefault("AUDIT_EXTRA_ACTIONS", "")
+	viper.SetDefault("SHARE_HIDDEN_ACTIONS", "")
+	viper.SetDefault("DETAILS_SCHEMA_VALIDATION", false)
+
+	// Summary defaults
+	viper.SetDefault("SUMMARY_DEFAULT_WINDOW", "168h")
+	viper.SetDefault("SUMMARY_MAX_WINDOW", "720h")
+
+	// Security defaults
+	viper.SetDefault("ACCESS_AUDIT_ENABLED", false)
+	viper.SetDefault("SHARE_TOKENS_ENABLED", true)
+	viper.SetDefault("JWT_MAX_LENGTH", 8192)
+	viper.SetDefault("SHARE_TOKEN_MAX_ROWS", 0)
+	viper.SetDefault("SHARE_TOKEN_ROWS_WINDOW", "1h")
+	viper.SetDefault("SHARE_ALLOWED_ORIGINS", "")
+	viper.SetDefault("ADMIN_TOKEN", "")
+	viper.SetDefault("REVOKED_TOKEN_IDS", "")
+	viper.SetDefault("DENIED_USER_IDS", "")
+	viper.SetDefault("ADMIN_USER_IDS", "")
+	viper.SetDefault("PPROF_ENABLED", false)
+	viper.SetDefault("HIDE_FORBIDDEN_SESSIONS", false)
 
 	// Read from environment (this will override .env file values)
 	viper.AutomaticEnv()
 
+	// Keys without defaults must be bound explicitly for Unmarshal to see them
+	for _, key := range []string{"SUPABASE_URL", "SUPABASE_ANON_KEY", "SUPABASE_SERVICE_ROLE_KEY", "SUPABASE_JWT_SECRET"} {
+		_ = viper.BindEnv(key)
+	}
+
+	// Allow secrets to be mounted as files (e.g. Kubernetes secrets)
+	if err := loadSecretFiles(secretKeys); err != nil {
+		return nil, err
+	}
+
 	var cfg Config
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
@@ -81,11 +318,44 @@ func Load() (*Config, error) {
 	return &cfg, nil
 }
 
+// secretKeys lists the settings that may be supplied via a *_FILE variant
+var secretKeys = []string{
+	"SUPABASE_ANON_KEY",
+	"SUPABASE_SERVICE_ROLE_KEY",
+	"SUPABASE_JWT_SECRET",
+	"ADMIN_TOKEN",
+}
+
+// loadSecretFiles reads each key from the file named by its *_FILE variant.
+// A value set directly in the environment takes precedence over the file.
+func loadSecretFiles(keys []string) error {
+	for _, key := range keys {
+		if _, ok := os.LookupEnv(key); ok {
+			continue
+		}
+
+		path := viper.GetString(key + "_FILE")
+		if path == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s_FILE: %w", key, err)
+		}
+		viper.Set(key, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
 // Validate ensures all required configuration is present
 func (c *Config) Validate() error {
 	if c.SupabaseURL == "" {
 		return fmt.Errorf("SUPABASE_URL is required")
 	}
+	if err := c.normalizeSupabaseURL(); err != nil {
+		return err
+	}
 	if c.SupabaseServiceRoleKey == "" {
 		return fmt.Errorf("SUPABASE_SERVICE_ROLE_KEY is required")
 	}
@@ -95,24 +365,271 @@ func (c *Config) Validate() error {
 	if c.Port == "" {
 		return fmt.Errorf("PORT is required")
 	}
+	if net.ParseIP(c.BindAddress) == nil {
+		return fmt.Errorf("BIND_ADDRESS must be an IP address, got %q", c.BindAddress)
+	}
+	for _, proxy := range c.TrustedProxies {
+		if !isIPOrCIDR(proxy) {
+			return fmt.Errorf("TRUSTED_PROXIES must contain IP addresses or CIDRs, got %q", proxy)
+		}
+	}
+	if c.LogFormat != "json" && c.LogFormat != "console" {
+		return fmt.Errorf("LOG_FORMAT must be json or console")
+	}
+	if c.ShutdownDrainDelay < 0 {
+		return fmt.Errorf("SHUTDOWN_DRAIN_DELAY must not be negative")
+	}
+	if c.PprofEnabled && c.AdminToken == "" {
+		return fmt.Errorf("PPROF_ENABLED requires ADMIN_TOKEN")
+	}
+	if c.SlowRequestThreshold < 0 {
+		return fmt.Errorf("SLOW_REQUEST_THRESHOLD must not be negative")
+	}
+	if c.LogFile != "" && c.LogMaxSizeMB <= 0 {
+		return fmt.Errorf("LOG_MAX_SIZE_MB must be positive when LOG_FILE is set")
+	}
+	c.normalizeRoutePrefix()
+	switch c.countMode() {
+	case "exact", "estimated", "planned":
+	default:
+		return fmt.Errorf("SUPABASE_COUNT_MODE must be exact, estimated or planned")
+	}
 	if c.HTTPTimeout <= 0 {
 		return fmt.Errorf("HTTP_TIMEOUT must be positive")
 	}
+	if c.HTTPMaxConcurrent <= 0 {
+		return fmt.Errorf("HTTP_MAX_CONCURRENT must be positive")
+	}
+	if c.QueryTimeout <= 0 {
+		return fmt.Errorf("QUERY_TIMEOUT must be positive")
+	}
+	if c.ReadinessTimeout <= 0 {
+		return fmt.Errorf("READINESS_TIMEOUT must be positive")
+	}
 	if c.CacheJWTTTL <= 0 {
 		return fmt.Errorf("CACHE_JWT_TTL must be positive")
 	}
 	if c.CacheShareTokenTTL <= 0 {
 		return fmt.Errorf("CACHE_SHARE_TOKEN_TTL must be positive")
 	}
+	if c.CacheExpirySkew < 0 {
+		return fmt.Errorf("CACHE_EXPIRY_SKEW must not be negative")
+	}
+	if c.IdempotencyTTL <= 0 {
+		return fmt.Errorf("IDEMPOTENCY_TTL must be positive")
+	}
+	if c.ServeStale && c.StaleTTL <= 0 {
+		return fmt.Errorf("STALE_TTL must be positive when SERVE_STALE is enabled")
+	}
+	if c.ServeStale && c.StaleCacheMaxSessions <= 0 {
+		return fmt.Errorf("STALE_CACHE_MAX_SESSIONS must be positive when SERVE_STALE is enabled")
+	}
+	if c.ResponseCacheEnabled && c.ResponseCacheTTL <= 0 {
+		return fmt.Errorf("RESPONSE_CACHE_TTL must be positive when RESPONSE_CACHE_ENABLED is set")
+	}
+	if c.ResponseCacheEnabled && c.ResponseCacheSessions <= 0 {
+		return fmt.Errorf("RESPONSE_CACHE_MAX_SESSIONS must be positive when RESPONSE_CACHE_ENABLED is set")
+	}
+	if c.LongPollMaxWait < 0 {
+		return fmt.Errorf("LONG_POLL_MAX_WAIT must not be negative")
+	}
+	if c.LongPollMaxWait > 0 && c.LongPollInterval <= 0 {
+		return fmt.Errorf("LONG_POLL_INTERVAL must be positive when LONG_POLL_MAX_WAIT is set")
+	}
+	if c.MaxPageSize <= 0 {
+		return fmt.Errorf("MAX_PAGE_SIZE must be positive")
+	}
+	if c.DefaultPageSize <= 0 || c.DefaultPageSize > c.MaxPageSize {
+		return fmt.Errorf("DEFAULT_PAGE_SIZE must be between 1 and MAX_PAGE_SIZE")
+	}
+	if c.MaxBatchSize <= 0 {
+		return fmt.Errorf("MAX_BATCH_SIZE must be positive")
+	}
+	if c.MaxBatchSessions <= 0 {
+		return fmt.Errorf("MAX_BATCH_SESSIONS must be positive")
+	}
+	if c.MaxImportLines <= 0 {
+		return fmt.Errorf("MAX_IMPORT_LINES must be positive")
+	}
+	if c.MaxImportBytes <= 0 {
+		return fmt.Errorf("MAX_IMPORT_BYTES must be positive")
+	}
+	if c.MaxActionFilters <= 0 {
+		return fmt.Errorf("MAX_ACTION_FILTERS must be positive")
+	}
+	if c.MaxBodyBytes <= 0 {
+		return fmt.Errorf("MAX_BODY_BYTES must be positive")
+	}
+	if c.MaxDetailsBytes <= 0 {
+		return fmt.Errorf("MAX_DETAILS_BYTES must be positive")
+	}
+	if c.MaxResponseBytes <= 0 {
+		return fmt.Errorf("MAX_RESPONSE_BYTES must be positive")
+	}
+	if c.ShareTokenMaxRows < 0 {
+		return fmt.Errorf("SHARE_TOKEN_MAX_ROWS must not be negative")
+	}
+	if c.ShareTokenMaxRows > 0 && c.ShareTokenRowsWindow <= 0 {
+		return fmt.Errorf("SHARE_TOKEN_ROWS_WINDOW must be positive when SHARE_TOKEN_MAX_ROWS is set")
+	}
+	for i, origin := range c.ShareAllowedOrigins {
+		normalized, err := normalizeBaseURL("SHARE_ALLOWED_ORIGINS", origin)
+		if err != nil {
+			return err
+		}
+		if u, _ := url.Parse(normalized); u.Path != "" || u.RawQuery != "" || u.Fragment != "" {
+			return fmt.Errorf("SHARE_ALLOWED_ORIGINS must contain origins without a path, got %q", origin)
+		}
+		c.ShareAllowedOrigins[i] = strings.ToLower(normalized)
+	}
+	if c.JWTMaxLength <= 0 {
+		return fmt.Errorf("JWT_MAX_LENGTH must be positive")
+	}
+	if c.SummaryDefaultWindow <= 0 || c.SummaryDefaultWindow > c.SummaryMaxWindow {
+		return fmt.Errorf("SUMMARY_DEFAULT_WINDOW must be positive and not exceed SUMMARY_MAX_WINDOW")
+	}
 	return nil
 }
 
+// normalizeSupabaseURL checks that SUPABASE_URL, and SUPABASE_READ_URL when
+// set, are absolute http(s) URLs and strips trailing slashes so request URLs
+// are built without "//"
+func (c *Config) normalizeSupabaseURL() error {
+	var err error
+	if c.SupabaseURL, err = normalizeBaseURL("SUPABASE_URL", c.SupabaseURL); err != nil {
+		return err
+	}
+	if c.SupabaseReadURL == "" {
+		return nil
+	}
+	c.SupabaseReadURL, err = normalizeBaseURL("SUPABASE_READ_URL", c.SupabaseReadURL)
+	return err
+}
+
+func normalizeBaseURL(key, raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("%s is not a valid URL: %w", key, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("%s must use http or https scheme, got %q", key, raw)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("%s must include a host, got %q", key, raw)
+	}
+	return strings.TrimRight(raw, "/"), nil
+}
+
+// isIPOrCIDR reports whether s is an IP address or a CIDR range
+func isIPOrCIDR(s string) bool {
+	if net.ParseIP(s) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(s)
+	return err == nil
+}
+
+// ListenAddr returns the host:port the server listens on
+func (c *Config) ListenAddr() string {
+	return net.JoinHostPort(c.BindAddress, c.Port)
+}
+
+// normalizeRoutePrefix ensures ROUTE_PREFIX is either empty or starts with
+// a slash and has no trailing slash, e.g. "audit/" becomes "/audit"
+func (c *Config) normalizeRoutePrefix() {
+	prefix := strings.Trim(c.RoutePrefix, "/")
+	if prefix == "" {
+		c.RoutePrefix = ""
+		return
+	}
+	c.RoutePrefix = "/" + prefix
+}
+
+// Public returns the non-secret configuration values
+func (c *Config) Public() PublicConfig {
+	return PublicConfig{
+		Port:                    c.Port,
+		BindAddress:             c.BindAddress,
+		TrustedProxies:          c.TrustedProxies,
+		EnforceHTTPS:            c.EnforceHTTPS,
+		ShutdownDrainDelay:      c.ShutdownDrainDelay.String(),
+		LogLevel:                c.LogLevel,
+		LogFormat:               c.LogFormat,
+		LogFile:                 c.LogFile,
+		SlowRequestThreshold:    c.SlowRequestThreshold.String(),
+		ServerTimingEnabled:     c.ServerTimingEnabled,
+		RoutePrefix:             c.RoutePrefix,
+		SupabaseURL:             c.SupabaseURL,
+		SupabaseReadURL:         c.SupabaseReadURL,
+		SupabaseCountMode:       c.SupabaseCountMode,
+		SupabaseJWTSecretBase64: c.SupabaseJWTSecretBase64,
+		HTTPTimeout:             c.HTTPTimeout.String(),
+		HTTPMaxIdleConns:        c.HTTPMaxIdleConns,
+		HTTPMaxConnsPerHost:     c.HTTPMaxConnsPerHost,
+		HTTPIdleConnTimeout:     c.HTTPIdleConnTimeout.String(),
+		HTTPMaxConcurrent:       c.HTTPMaxConcurrent,
+		HTTPUserAgent:           c.HTTPUserAgent,
+		QueryTimeout:            c.QueryTimeout.String(),
+		ReadinessTimeout:        c.ReadinessTimeout.String(),
+		CacheJWTTTL:             c.CacheJWTTTL.String(),
+		CacheShareTokenTTL:      c.CacheShareTokenTTL.String(),
+		CacheCleanupInterval:    c.CacheCleanupInterval.String(),
+		CacheExpirySkew:         c.CacheExpirySkew.String(),
+		IdempotencyTTL:          c.IdempotencyTTL.String(),
+		ServeStale:              c.ServeStale,
+		StaleTTL:                c.StaleTTL.String(),
+		StaleCacheMaxSessions:   c.StaleCacheMaxSessions,
+		ResponseCacheEnabled:    c.ResponseCacheEnabled,
+		ResponseCacheTTL:        c.ResponseCacheTTL.String(),
+		ResponseCacheSessions:   c.ResponseCacheSessions,
+		LongPollMaxWait:         c.LongPollMaxWait.String(),
+		LongPollInterval:        c.LongPollInterval.String(),
+		MaxPageSize:             c.MaxPageSize,
+		DefaultPageSize:         c.DefaultPageSize,
+		MaxBatchSize:            c.MaxBatchSize,
+		MaxBatchSessions:        c.MaxBatchSessions,
+		MaxImportLines:          c.MaxImportLines,
+		MaxImportBytes:          c.MaxImportBytes,
+		MaxActionFilters:        c.MaxActionFilters,
+		MaxBodyBytes:            c.MaxBodyBytes,
+		MaxDetailsBytes:         c.MaxDetailsBytes,
+		MaxResponseBytes:        c.MaxResponseBytes,
+		PaginationHints:         c.PaginationHints,
+		StrictPagination:        c.StrictPagination,
+		ExtraAuditActions:       c.ExtraAuditActions,
+		ShareHiddenActions:      c.ShareHiddenActions,
+		DetailsSchemaValidation: c.DetailsSchemaValidation,
+		SummaryDefaultWindow:    c.SummaryDefaultWindow.String(),
+		SummaryMaxWindow:        c.SummaryMaxWindow.String(),
+		AccessAuditEnabled:      c.AccessAuditEnabled,
+		PprofEnabled:            c.PprofEnabled,
+		HideForbiddenSessions:   c.HideForbiddenSessions,
+		ShareTokensEnabled:      c.ShareTokensEnabled,
+		JWTMaxLength:            c.JWTMaxLength,
+		ShareTokenMaxRows:       c.ShareTokenMaxRows,
+		ShareTokenRowsWindow:    c.ShareTokenRowsWindow.String(),
+		ShareAllowedOrigins:     c.ShareAllowedOrigins,
+	}
+}
+
 // GetSupabaseHeaders returns the required headers for Supabase REST API calls
 func (c *Config) GetSupabaseHeaders() map[string]string {
-	return map[string]string{
+	headers := map[string]string{
 		"apikey":        c.SupabaseServiceRoleKey,
 		"Authorization": "Bearer " + c.SupabaseServiceRoleKey,
 		"Content-Type":  "application/json",
-		"Prefer":        "count=exact",
+		"Prefer":        "count=" + c.countMode(),
+	}
+	if c.HTTPUserAgent != "" {
+		headers["User-Agent"] = c.HTTPUserAgent
+	}
+	return headers
+}
+
+// countMode returns the PostgREST count strategy, defaulting to exact
+func (c *Config) countMode() string {
+	if c.SupabaseCountMode == "" {
+		return "exact"
 	}
+	return c.SupabaseCountMode
 }
@@ -0,0 +1,374 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func validConfig() *Config {
+	return &Config{
+		Port:                   "4006",
+		BindAddress:            "0.0.0.0",
+		LogFormat:              "json",
+		SupabaseURL:            "https://example.supabase.co",
+		SupabaseServiceRoleKey: "service-role-key",
+		SupabaseJWTSecret:      "jwt-secret",
+		HTTPTimeout:            30 * time.Second,
+		HTTPMaxConcurrent:      20,
+		QueryTimeout:           10 * time.Second,
+		ReadinessTimeout:       2 * time.Second,
+		CacheJWTTTL:            5 * time.Minute,
+		CacheShareTokenTTL:     1 * time.Minute,
+		IdempotencyTTL:         24 * time.Hour,
+		MaxPageSize:            100,
+		DefaultPageSize:        50,
+		MaxBatchSize:           100,
+		MaxBatchSessions:       10,
+		MaxImportLines:         10000,
+		MaxImportBytes:         32 << 20,
+		MaxActionFilters:       10,
+		MaxBodyBytes:           1 << 20,
+		MaxDetailsBytes:        64 << 10,
+		MaxResponseBytes:       10 << 20,
+		JWTMaxLength:           8192,
+		SummaryDefaultWindow:   168 * time.Hour,
+		SummaryMaxWindow:       720 * time.Hour,
+	}
+}
+
+func TestConfig_Validate_SupabaseURL(t *testing.T) {
+	tests := []struct {
+		name          string
+		supabaseURL   string
+		expectedURL   string
+		expectedError string
+	}{
+		{
+			name:        "valid_https_url",
+			supabaseURL: "https://example.supabase.co",
+			expectedURL: "https://example.supabase.co",
+		},
+		{
+			name:        "valid_http_url_with_port",
+			supabaseURL: "http://localhost:54321",
+			expectedURL: "http://localhost:54321",
+		},
+		{
+			name:        "trailing_slash_trimmed",
+			supabaseURL: "https://example.supabase.co/",
+			expectedURL: "https://example.supabase.co",
+		},
+		{
+			name:        "multiple_trailing_slashes_trimmed",
+			supabaseURL: "https://example.supabase.co//",
+			expectedURL: "https://example.supabase.co",
+		},
+		{
+			name:          "missing_scheme",
+			supabaseURL:   "example.supabase.co",
+			expectedError: "SUPABASE_URL must use http or https scheme",
+		},
+		{
+			name:          "unsupported_scheme",
+			supabaseURL:   "ftp://example.supabase.co",
+			expectedError: "SUPABASE_URL must use http or https scheme",
+		},
+		{
+			name:          "missing_host",
+			supabaseURL:   "https://",
+			expectedError: "SUPABASE_URL must include a host",
+		},
+		{
+			name:          "empty",
+			supabaseURL:   "",
+			expectedError: "SUPABASE_URL is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.SupabaseURL = tt.supabaseURL
+
+			err := cfg.Validate()
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedURL, cfg.SupabaseURL)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_SupabaseReadURL(t *testing.T) {
+	tests := []struct {
+		name          string
+		readURL       string
+		expectedURL   string
+		expectedError string
+	}{
+		{name: "unset", readURL: "", expectedURL: ""},
+		{name: "trailing_slash_trimmed", readURL: "https://replica.supabase.co/", expectedURL: "https://replica.supabase.co"},
+		{name: "missing_scheme", readURL: "replica.supabase.co", expectedError: "SUPABASE_READ_URL must use http or https scheme"},
+		{name: "missing_host", readURL: "https://", expectedError: "SUPABASE_READ_URL must include a host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.SupabaseReadURL = tt.readURL
+
+			err := cfg.Validate()
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedURL, cfg.SupabaseReadURL)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_ShareAllowedOrigins(t *testing.T) {
+	tests := []struct {
+		name            string
+		origins         []string
+		expectedOrigins []string
+		expectedError   string
+	}{
+		{name: "unset", origins: nil, expectedOrigins: nil},
+		{name: "normalized", origins: []string{"https://App.Example.com/", "http://localhost:3000"}, expectedOrigins: []string{"https://app.example.com", "http://localhost:3000"}},
+		{name: "missing_scheme", origins: []string{"app.example.com"}, expectedError: "SHARE_ALLOWED_ORIGINS must use http or https scheme"},
+		{name: "with_path", origins: []string{"https://app.example.com/embed"}, expectedError: "SHARE_ALLOWED_ORIGINS must contain origins without a path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.ShareAllowedOrigins = tt.origins
+
+			err := cfg.Validate()
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedOrigins, cfg.ShareAllowedOrigins)
+			}
+		})
+	}
+}
+
+func TestLoad_SecretFiles(t *testing.T) {
+	secretPath := filepath.Join(t.TempDir(), "service_role_key")
+	assert.NoError(t, os.WriteFile(secretPath, []byte("key-from-file\n"), 0o600))
+
+	tests := []struct {
+		name          string
+		env           map[string]string
+		expectedKey   string
+		expectedError string
+	}{
+		{
+			name: "value_read_from_file",
+			env: map[string]string{
+				"SUPABASE_SERVICE_ROLE_KEY_FILE": secretPath,
+			},
+			expectedKey: "key-from-file",
+		},
+		{
+			name: "env_var_takes_precedence",
+			env: map[string]string{
+				"SUPABASE_SERVICE_ROLE_KEY":      "key-from-env",
+				"SUPABASE_SERVICE_ROLE_KEY_FILE": secretPath,
+			},
+			expectedKey: "key-from-env",
+		},
+		{
+			name: "missing_file",
+			env: map[string]string{
+				"SUPABASE_SERVICE_ROLE_KEY_FILE": filepath.Join(t.TempDir(), "missing"),
+			},
+			expectedError: "failed to read SUPABASE_SERVICE_ROLE_KEY_FILE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+
+			t.Setenv("SUPABASE_URL", "https://example.supabase.co")
+			t.Setenv("SUPABASE_JWT_SECRET", "jwt-secret")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := Load()
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedKey, cfg.SupabaseServiceRoleKey)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_RoutePrefix(t *testing.T) {
+	tests := []struct {
+		name           string
+		routePrefix    string
+		expectedPrefix string
+	}{
+		{name: "empty", routePrefix: "", expectedPrefix: ""},
+		{name: "root_only", routePrefix: "/", expectedPrefix: ""},
+		{name: "leading_slash", routePrefix: "/audit", expectedPrefix: "/audit"},
+		{name: "missing_leading_slash", routePrefix: "audit", expectedPrefix: "/audit"},
+		{name: "trailing_slash", routePrefix: "/audit/", expectedPrefix: "/audit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.RoutePrefix = tt.routePrefix
+
+			assert.NoError(t, cfg.Validate())
+			assert.Equal(t, tt.expectedPrefix, cfg.RoutePrefix)
+		})
+	}
+}
+
+func TestConfig_Validate_LogFormat(t *testing.T) {
+	for _, format := range []string{"json", "console"} {
+		cfg := validConfig()
+		cfg.LogFormat = format
+		assert.NoError(t, cfg.Validate(), format)
+	}
+
+	cfg := validConfig()
+	cfg.LogFormat = "xml"
+	assert.EqualError(t, cfg.Validate(), "LOG_FORMAT must be json or console")
+}
+
+func TestConfig_GetSupabaseHeaders_CountMode(t *testing.T) {
+	tests := []struct {
+		name           string
+		countMode      string
+		expectedPrefer string
+		expectedError  string
+	}{
+		{name: "default", countMode: "", expectedPrefer: "count=exact"},
+		{name: "exact", countMode: "exact", expectedPrefer: "count=exact"},
+		{name: "estimated", countMode: "estimated", expectedPrefer: "count=estimated"},
+		{name: "planned", countMode: "planned", expectedPrefer: "count=planned"},
+		{name: "invalid", countMode: "approximate", expectedError: "SUPABASE_COUNT_MODE must be exact, estimated or planned"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.SupabaseCountMode = tt.countMode
+
+			err := cfg.Validate()
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedPrefer, cfg.GetSupabaseHeaders()["Prefer"])
+		})
+	}
+}
+
+func TestLoad_ExtraAuditActions(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	t.Setenv("SUPABASE_URL", "https://example.supabase.co")
+	t.Setenv("SUPABASE_SERVICE_ROLE_KEY", "service-role-key")
+	t.Setenv("SUPABASE_JWT_SECRET", "jwt-secret")
+	t.Setenv("AUDIT_EXTRA_ACTIONS", "translate,review")
+
+	cfg, err := Load()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"translate", "review"}, cfg.ExtraAuditActions)
+}
+
+func TestConfig_Validate_BindAddress(t *testing.T) {
+	tests := []struct {
+		name          string
+		bindAddress   string
+		expectedAddr  string
+		expectedError string
+	}{
+		{name: "all interfaces", bindAddress: "0.0.0.0", expectedAddr: "0.0.0.0:4006"},
+		{name: "loopback", bindAddress: "127.0.0.1", expectedAddr: "127.0.0.1:4006"},
+		{name: "ipv6", bindAddress: "::1", expectedAddr: "[::1]:4006"},
+		{name: "hostname", bindAddress: "localhost", expectedError: `BIND_ADDRESS must be an IP address, got "localhost"`},
+		{name: "with port", bindAddress: "0.0.0.0:4006", expectedError: `BIND_ADDRESS must be an IP address, got "0.0.0.0:4006"`},
+		{name: "empty", bindAddress: "", expectedError: `BIND_ADDRESS must be an IP address, got ""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.BindAddress = tt.bindAddress
+
+			err := cfg.Validate()
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedAddr, cfg.ListenAddr())
+		})
+	}
+}
+
+func TestConfig_Validate_TrustedProxies(t *testing.T) {
+	cfg := validConfig()
+	cfg.TrustedProxies = []string{"10.0.0.1", "172.16.0.0/12", "::1"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.TrustedProxies = []string{"proxy.internal"}
+	assert.EqualError(t, cfg.Validate(), `TRUSTED_PROXIES must contain IP addresses or CIDRs, got "proxy.internal"`)
+}
+
+func TestConfig_Validate_Pprof(t *testing.T) {
+	cfg := validConfig()
+	cfg.PprofEnabled = true
+	assert.EqualError(t, cfg.Validate(), "PPROF_ENABLED requires ADMIN_TOKEN")
+
+	cfg.AdminToken = "admin-secret"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestLoad_InvalidBindAddress(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	t.Setenv("SUPABASE_URL", "https://example.supabase.co")
+	t.Setenv("SUPABASE_SERVICE_ROLE_KEY", "service-role-key")
+	t.Setenv("SUPABASE_JWT_SECRET", "jwt-secret")
+	t.Setenv("BIND_ADDRESS", "not-an-ip")
+
+	cfg, err := Load()
+
+	assert.Nil(t, cfg)
+	assert.EqualError(t, err, `config validation failed: BIND_ADDRESS must be an IP address, got "not-an-ip"`)
+}
@@ -1,8 +1,16 @@
 package domain
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // AuditEntry represents a single audit log entry
@@ -15,12 +23,137 @@ type AuditEntry struct {
 	Details   json.RawMessage `json:"details,omitempty" swaggertype:"object"`
 	IPAddress string          `json:"ipAddress,omitempty" example:"192.168.1.1"`
 	UserAgent string          `json:"userAgent,omitempty" example:"Mozilla/5.0"`
+	// DeletedAt is set on tombstoned entries, which are only returned for includeDeleted
+	DeletedAt *time.Time `json:"deletedAt,omitempty" example:"2023-12-01T11:00:00Z"`
+	// PerformedBy is who actually performed the action when that differs from
+	// UserID, e.g. an admin correcting history on the user's behalf
+	PerformedBy string `json:"performedBy,omitempty" example:"550e8400-e29b-41d4-a716-446655440003"`
+	// Session is the embedded session metadata, only loaded for include=session
+	Session *SessionMetadata `json:"-"`
+}
+
+// SessionMetadata describes the session an audit history page belongs to
+type SessionMetadata struct {
+	Title   string `json:"title" example:"Quarterly review deck"`
+	OwnerID string `json:"ownerId" example:"550e8400-e29b-41d4-a716-446655440002"`
+}
+
+// MarshalJSON omits details that are null or an empty object, so absent,
+// null and empty details all serialize the same way
+func (e AuditEntry) MarshalJSON() ([]byte, error) {
+	type plain AuditEntry
+	entry := plain(e)
+	entry.Details = normalizeDetails(entry.Details)
+	return json.Marshal(entry)
+}
+
+// normalizeDetails returns nil for empty, null or {} details and the details unchanged otherwise
+func normalizeDetails(details json.RawMessage) json.RawMessage {
+	trimmed := bytes.TrimSpace(details)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil
+	}
+	if trimmed[0] == '{' && trimmed[len(trimmed)-1] == '}' && len(bytes.TrimSpace(trimmed[1:len(trimmed)-1])) == 0 {
+		return nil
+	}
+	return details
+}
+
+// ETag returns a strong entity tag for the entry, derived from its id and timestamp
+func (e AuditEntry) ETag() string {
+	sum := sha256.Sum256([]byte(e.ID + "|" + e.Timestamp.UTC().Format(time.RFC3339Nano)))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
 }
 
 // AuditResponse represents the paginated audit log response
 type AuditResponse struct {
 	TotalCount int          `json:"totalCount" example:"42"`
 	Items      []AuditEntry `json:"items"`
+	// Session is only set for include=session
+	Session *SessionMetadata `json:"session,omitempty"`
+	// HasMore and OffsetBeyondTotal are only set with PAGINATION_HINTS
+	HasMore           *bool `json:"hasMore,omitempty"`
+	OffsetBeyondTotal bool  `json:"offsetBeyondTotal,omitempty"`
+	// Pagination is the page that was served, after clamping
+	Pagination PaginationParams `json:"-"`
+	// CountMode is the PostgREST count strategy behind TotalCount (exact, estimated or planned)
+	CountMode string `json:"-"`
+	// Stale is set when the response was served from cache because Supabase was unavailable
+	Stale bool `json:"-"`
+}
+
+// SetPaginationHints records whether entries remain past this page and
+// whether the page starts beyond the last entry
+func (r *AuditResponse) SetPaginationHints() {
+	hasMore := r.Pagination.Offset+len(r.Items) < r.TotalCount
+	r.HasMore = &hasMore
+	r.OffsetBeyondTotal = r.Pagination.Offset > 0 && r.Pagination.Offset >= r.TotalCount
+}
+
+// SessionHistory is one session's result in a multi-session history read:
+// either a page of entries or the error that session's share token hit
+type SessionHistory struct {
+	TotalCount int          `json:"totalCount" example:"42"`
+	Items      []AuditEntry `json:"items"`
+	Error      *APIError    `json:"error,omitempty"`
+}
+
+// MultiSessionHistoryResponse maps each requested session ID to its history
+type MultiSessionHistoryResponse struct {
+	Sessions map[string]SessionHistory `json:"sessions"`
+}
+
+// CreateAuditEntryRequest represents the payload for recording a new audit entry
+type CreateAuditEntryRequest struct {
+	Action    string          `json:"action" binding:"required" example:"edit"`
+	Details   json.RawMessage `json:"details,omitempty" swaggertype:"object"`
+	IPAddress string          `json:"-"`
+	UserAgent string          `json:"-"`
+	// ActorUserID records the entry for another user; only admins may set it
+	ActorUserID string `json:"actorUserId,omitempty" example:"550e8400-e29b-41d4-a716-446655440002"`
+}
+
+// BatchCreateResponse represents the response for a batch of created audit entries
+type BatchCreateResponse struct {
+	IDs []string `json:"ids"`
+}
+
+// ImportLine is one line of an ndjson audit log import. Unlike live writes,
+// the user and timestamp come from the line, since the events are historical.
+type ImportLine struct {
+	UserID    string          `json:"userId"`
+	Action    string          `json:"action"`
+	Timestamp time.Time       `json:"timestamp"`
+	Details   json.RawMessage `json:"details,omitempty"`
+	IPAddress string          `json:"ipAddress,omitempty"`
+	UserAgent string          `json:"userAgent,omitempty"`
+}
+
+// ImportSummary reports the outcome of an ndjson import
+type ImportSummary struct {
+	Inserted int           `json:"inserted"`
+	Failed   int           `json:"failed"`
+	Errors   []ImportError `json:"errors"`
+	// Truncated is set when reading stopped early, e.g. at the line limit
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// ImportError describes why a line of an import was not inserted
+type ImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// MaxImportErrors bounds the errors listed in an ImportSummary; further
+// failures are still counted in Failed
+const MaxImportErrors = 100
+
+// AddError counts a failed line, listing it while there is room
+func (s *ImportSummary) AddError(line int, reason string) {
+	s.Failed++
+	if len(s.Errors) < MaxImportErrors {
+		s.Errors = append(s.Errors, ImportError{Line: line, Error: reason})
+	}
 }
 
 // AuditAction represents the type of action performed
@@ -39,21 +172,96 @@ const (
 	ActionView    AuditAction = "view"
 )
 
+// IsValid reports whether the action is one of the known audit actions
+func (a AuditAction) IsValid() bool {
+	switch a {
+	case ActionCreate, ActionEdit, ActionMerge, ActionReorder, ActionComment,
+		ActionExport, ActionShare, ActionUnshare, ActionView:
+		return true
+	}
+	return false
+}
+
+// ActionSet is the vocabulary of audit actions accepted by a deployment
+type ActionSet map[AuditAction]struct{}
+
+// NewActionSet returns the built-in actions merged with any extra actions.
+// Blank entries are ignored and surrounding whitespace is trimmed.
+func NewActionSet(extra []string) ActionSet {
+	set := ActionSet{}
+	for _, action := range []AuditAction{
+		ActionCreate, ActionEdit, ActionMerge, ActionReorder, ActionComment,
+		ActionExport, ActionShare, ActionUnshare, ActionView,
+	} {
+		set[action] = struct{}{}
+	}
+	for _, action := range extra {
+		if action = strings.TrimSpace(action); action != "" {
+			set[AuditAction(action)] = struct{}{}
+		}
+	}
+	return set
+}
+
+// Contains reports whether the action is part of the set
+func (s ActionSet) Contains(action string) bool {
+	_, ok := s[AuditAction(action)]
+	return ok
+}
+
 // Pagination parameters
 type PaginationParams struct {
 	Limit  int
 	Offset int
 }
 
-// Validate ensures pagination parameters are within acceptable bounds
-func (p *PaginationParams) Validate() {
+// Validate applies defaultLimit to a missing limit, caps it at maxLimit and
+// clears a negative offset
+func (p *PaginationParams) Validate(defaultLimit, maxLimit int) {
 	if p.Limit <= 0 {
-		p.Limit = 50 // default
+		p.Limit = defaultLimit
 	}
-	if p.Limit > 100 {
-		p.Limit = 100 // max
+	if p.Limit > maxLimit {
+		p.Limit = maxLimit
 	}
 	if p.Offset < 0 {
 		p.Offset = 0
 	}
 }
+
+// ValidateStrict applies defaultLimit to a missing limit like Validate, but
+// returns ErrInvalidPagination for a negative limit or offset or a limit above
+// maxLimit instead of correcting them
+func (p *PaginationParams) ValidateStrict(defaultLimit, maxLimit int) error {
+	if p.Limit < 0 || p.Limit > maxLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidPagination, maxLimit)
+	}
+	if p.Offset < 0 {
+		return fmt.Errorf("%w: offset must not be negative", ErrInvalidPagination)
+	}
+	if p.Limit == 0 {
+		p.Limit = defaultLimit
+	}
+	return nil
+}
+
+// reservedPathSegments are route words that land in the session ID position
+// when a client drops or misplaces a path segment, e.g. /sessions/summary/history
+var reservedPathSegments = []string{"batch", "contributors", "history", "import", "shares", "summary"}
+
+// IsReservedPathSegment reports whether s is a route word rather than a session ID
+func IsReservedPathSegment(s string) bool {
+	return slices.Contains(reservedPathSegments, strings.ToLower(s))
+}
+
+// IsValidUUID reports whether s is a UUID in the canonical hyphenated
+// 8-4-4-4-12 form. Letter case, version and variant are not restricted, so
+// the nil and max UUIDs are accepted; the braced, urn:uuid: and unhyphenated
+// forms that uuid.Parse also understands are not.
+func IsValidUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	_, err := uuid.Parse(s)
+	return err == nil
+}
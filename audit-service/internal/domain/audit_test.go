@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAuditEntry_JSONSerialization(t *testing.T) {
@@ -37,6 +38,74 @@ func TestAuditEntry_JSONSerialization(t *testing.T) {
 	assert.Equal(t, entry.Action, unmarshaled.Action)
 }
 
+func TestAuditEntry_PerformedBySerialization(t *testing.T) {
+	tests := []struct {
+		name        string
+		performedBy string
+	}{
+		{name: "self_performed", performedBy: ""},
+		{name: "delegated", performedBy: "admin-789"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := AuditEntry{ID: "a1", UserID: "user-456", Action: "edit", PerformedBy: tt.performedBy}
+
+			data, err := json.Marshal(entry)
+			require.NoError(t, err)
+
+			var fields map[string]json.RawMessage
+			require.NoError(t, json.Unmarshal(data, &fields))
+			if tt.performedBy == "" {
+				assert.NotContains(t, fields, "performedBy")
+			} else {
+				assert.JSONEq(t, `"admin-789"`, string(fields["performedBy"]))
+			}
+
+			var decoded AuditEntry
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			assert.Equal(t, tt.performedBy, decoded.PerformedBy)
+		})
+	}
+}
+
+func TestAuditEntry_DetailsSerialization(t *testing.T) {
+	tests := []struct {
+		name            string
+		row             string
+		expectedDetails string
+	}{
+		{name: "absent", row: `{"id":"a1"}`},
+		{name: "null", row: `{"id":"a1","details":null}`},
+		{name: "empty object", row: `{"id":"a1","details":{}}`},
+		{name: "empty object with whitespace", row: `{"id":"a1","details":{ }}`},
+		{name: "populated", row: `{"id":"a1","details":{"slide":3}}`, expectedDetails: `{"slide":3}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entry AuditEntry
+			require.NoError(t, json.Unmarshal([]byte(tt.row), &entry))
+
+			data, err := json.Marshal(entry)
+			require.NoError(t, err)
+
+			var fields map[string]json.RawMessage
+			require.NoError(t, json.Unmarshal(data, &fields))
+			if tt.expectedDetails == "" {
+				assert.NotContains(t, fields, "details")
+				return
+			}
+			assert.JSONEq(t, tt.expectedDetails, string(fields["details"]))
+		})
+	}
+
+	// Entries nested in a response go through the same normalization
+	data, err := json.Marshal(AuditResponse{Items: []AuditEntry{{ID: "a1", Details: json.RawMessage("null")}}})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "details")
+}
+
 func TestPaginationParams_Validate(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -63,12 +132,75 @@ func TestPaginationParams_Validate(t *testing.T) {
 			input:    PaginationParams{Limit: 25, Offset: 10},
 			expected: PaginationParams{Limit: 25, Offset: 10},
 		},
+		{
+			name:     "limit at maximum unchanged",
+			input:    PaginationParams{Limit: 100, Offset: 0},
+			expected: PaginationParams{Limit: 100, Offset: 0},
+		},
+		{
+			name:     "limit one above maximum clamped",
+			input:    PaginationParams{Limit: 101, Offset: 0},
+			expected: PaginationParams{Limit: 100, Offset: 0},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			pagination := tt.input
-			pagination.Validate()
+			pagination.Validate(50, 100)
+			assert.Equal(t, tt.expected, pagination)
+		})
+	}
+}
+
+func TestPaginationParams_ValidateStrict(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       PaginationParams
+		expected    PaginationParams
+		expectError bool
+	}{
+		{
+			name:     "default limit when zero",
+			input:    PaginationParams{Limit: 0, Offset: 0},
+			expected: PaginationParams{Limit: 50, Offset: 0},
+		},
+		{
+			name:     "limit at maximum",
+			input:    PaginationParams{Limit: 100, Offset: 10},
+			expected: PaginationParams{Limit: 100, Offset: 10},
+		},
+		{
+			name:     "limit of one",
+			input:    PaginationParams{Limit: 1, Offset: 0},
+			expected: PaginationParams{Limit: 1, Offset: 0},
+		},
+		{
+			name:        "limit one above maximum",
+			input:       PaginationParams{Limit: 101, Offset: 0},
+			expectError: true,
+		},
+		{
+			name:        "negative limit",
+			input:       PaginationParams{Limit: -1, Offset: 0},
+			expectError: true,
+		},
+		{
+			name:        "negative offset",
+			input:       PaginationParams{Limit: 25, Offset: -1},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pagination := tt.input
+			err := pagination.ValidateStrict(50, 100)
+			if tt.expectError {
+				assert.ErrorIs(t, err, ErrInvalidPagination)
+				return
+			}
+			assert.NoError(t, err)
 			assert.Equal(t, tt.expected, pagination)
 		})
 	}
@@ -91,9 +223,16 @@ func TestAuditAction_Constants(t *testing.T) {
 	for _, action := range actions {
 		assert.NotEmpty(t, string(action))
 		assert.IsType(t, AuditAction(""), action)
+		assert.True(t, action.IsValid())
 	}
 }
 
+func TestAuditAction_IsValid_Unknown(t *testing.T) {
+	assert.False(t, AuditAction("").IsValid())
+	assert.False(t, AuditAction("delete").IsValid())
+	assert.False(t, AuditAction("EDIT").IsValid())
+}
+
 func TestAuditResponse_Structure(t *testing.T) {
 	// Test AuditResponse structure
 	entries := []AuditEntry{
@@ -131,3 +270,170 @@ func TestAuditResponse_Structure(t *testing.T) {
 	assert.Equal(t, response.TotalCount, unmarshaled.TotalCount)
 	assert.Len(t, unmarshaled.Items, 2)
 }
+
+func TestAuditResponse_SetPaginationHints(t *testing.T) {
+	tests := []struct {
+		name              string
+		offset            int
+		items             int
+		totalCount        int
+		hasMore           bool
+		offsetBeyondTotal bool
+	}{
+		{name: "first_page_of_many", offset: 0, items: 10, totalCount: 25, hasMore: true},
+		{name: "last_page", offset: 20, items: 5, totalCount: 25, hasMore: false},
+		{name: "offset_at_total", offset: 25, items: 0, totalCount: 25, offsetBeyondTotal: true},
+		{name: "offset_past_total", offset: 100, items: 0, totalCount: 25, offsetBeyondTotal: true},
+		{name: "empty_history", offset: 0, items: 0, totalCount: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := AuditResponse{
+				TotalCount: tt.totalCount,
+				Items:      make([]AuditEntry, tt.items),
+				Pagination: PaginationParams{Limit: 10, Offset: tt.offset},
+			}
+
+			response.SetPaginationHints()
+
+			if assert.NotNil(t, response.HasMore) {
+				assert.Equal(t, tt.hasMore, *response.HasMore)
+			}
+			assert.Equal(t, tt.offsetBeyondTotal, response.OffsetBeyondTotal)
+		})
+	}
+}
+
+func TestNewActionSet(t *testing.T) {
+	builtIn := NewActionSet(nil)
+	assert.True(t, builtIn.Contains("edit"))
+	assert.False(t, builtIn.Contains("translate"))
+
+	custom := NewActionSet([]string{"translate", " review ", ""})
+	assert.True(t, custom.Contains("translate"))
+	assert.True(t, custom.Contains("review"))
+	assert.True(t, custom.Contains("edit"))
+	assert.False(t, custom.Contains(""))
+	assert.False(t, custom.Contains("delete"))
+}
+
+func TestIsValidUUID(t *testing.T) {
+	tests := []struct {
+		name  string
+		uuid  string
+		valid bool
+	}{
+		{
+			name:  "valid UUID",
+			uuid:  "550e8400-e29b-41d4-a716-446655440000",
+			valid: true,
+		},
+		{
+			name:  "valid UUID with uppercase",
+			uuid:  "550E8400-E29B-41D4-A716-446655440000",
+			valid: true,
+		},
+		{
+			name:  "invalid length",
+			uuid:  "550e8400-e29b-41d4-a716",
+			valid: false,
+		},
+		{
+			name:  "missing hyphens",
+			uuid:  "550e8400e29b41d4a716446655440000",
+			valid: false,
+		},
+		{
+			name:  "invalid characters",
+			uuid:  "550e8400-e29b-41d4-a716-44665544000g",
+			valid: false,
+		},
+		{
+			name:  "empty string",
+			uuid:  "",
+			valid: false,
+		},
+		{
+			name:  "nil UUID",
+			uuid:  "00000000-0000-0000-0000-000000000000",
+			valid: true,
+		},
+		{
+			name:  "max UUID",
+			uuid:  "ffffffff-ffff-ffff-ffff-ffffffffffff",
+			valid: true,
+		},
+		{
+			name:  "non RFC 4122 variant",
+			uuid:  "550e8400-e29b-41d4-c716-446655440000",
+			valid: true,
+		},
+		{
+			name:  "hyphens in wrong positions",
+			uuid:  "550e840-0e29b-41d4-a716-446655440000",
+			valid: false,
+		},
+		{
+			name:  "braced",
+			uuid:  "{550e8400-e29b-41d4-a716-446655440000}",
+			valid: false,
+		},
+		{
+			name:  "urn prefix",
+			uuid:  "urn:uuid:550e8400-e29b-41d4-a716-446655440000",
+			valid: false,
+		},
+		{
+			name:  "too long",
+			uuid:  "550e8400-e29b-41d4-a716-4466554400000",
+			valid: false,
+		},
+		{
+			name:  "surrounding whitespace",
+			uuid:  " 550e8400-e29b-41d4-a716-44665544000",
+			valid: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := IsValidUUID(tt.uuid)
+			assert.Equal(t, tt.valid, result)
+		})
+	}
+}
+
+func TestAuditEntry_ETag(t *testing.T) {
+	timestamp := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	entry := AuditEntry{ID: "audit-1", Action: "edit", Timestamp: timestamp}
+
+	etag := entry.ETag()
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+
+	// Only the id and timestamp contribute, and the timestamp's zone doesn't matter
+	assert.Equal(t, etag, AuditEntry{ID: "audit-1", Action: "merge", Timestamp: timestamp.In(time.FixedZone("IST", 19800))}.ETag())
+	assert.NotEqual(t, etag, AuditEntry{ID: "audit-2", Timestamp: timestamp}.ETag())
+	assert.NotEqual(t, etag, AuditEntry{ID: "audit-1", Timestamp: timestamp.Add(time.Microsecond)}.ETag())
+}
+
+func TestIsReservedPathSegment(t *testing.T) {
+	tests := []struct {
+		segment  string
+		reserved bool
+	}{
+		{segment: "summary", reserved: true},
+		{segment: "contributors", reserved: true},
+		{segment: "History", reserved: true},
+		{segment: "batch", reserved: true},
+		{segment: "550e8400-e29b-41d4-a716-446655440000", reserved: false},
+		{segment: "summary-2024", reserved: false},
+		{segment: "", reserved: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.segment, func(t *testing.T) {
+			assert.Equal(t, tt.reserved, IsReservedPathSegment(tt.segment))
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// DetailsFieldType is the JSON type a details field must have
+type DetailsFieldType string
+
+// Supported details field types
+const (
+	DetailsNumber  DetailsFieldType = "number"
+	DetailsString  DetailsFieldType = "string"
+	DetailsBoolean DetailsFieldType = "boolean"
+	DetailsArray   DetailsFieldType = "array"
+	DetailsObject  DetailsFieldType = "object"
+)
+
+// DetailsSchema lists the fields an action's details must contain, by type
+type DetailsSchema map[string]DetailsFieldType
+
+// DetailsSchemas maps audit actions to the schema their details must satisfy.
+// Actions without a schema accept any details.
+type DetailsSchemas map[AuditAction]DetailsSchema
+
+// NewDetailsSchemas returns the built-in schemas for merge and edit details
+func NewDetailsSchemas() DetailsSchemas {
+	return DetailsSchemas{
+		ActionMerge: {"slides": DetailsArray},
+		ActionEdit:  {"slide": DetailsNumber},
+	}
+}
+
+// Register sets the schema for an action, replacing any existing one
+func (s DetailsSchemas) Register(action AuditAction, schema DetailsSchema) {
+	s[action] = schema
+}
+
+// Validate checks details against the action's schema, if one is registered
+func (s DetailsSchemas) Validate(action string, details json.RawMessage) error {
+	schema, ok := s[AuditAction(action)]
+	if !ok || len(schema) == 0 {
+		return nil
+	}
+
+	var fields map[string]interface{}
+	if len(details) > 0 {
+		if err := json.Unmarshal(details, &fields); err != nil {
+			return &DetailsValidationError{Index: -1, Reason: "must be a JSON object"}
+		}
+	}
+
+	// Check fields in a stable order so the reported field is deterministic
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fieldType := schema[name]
+		value, present := fields[name]
+		if !present {
+			return &DetailsValidationError{Index: -1, Field: name, Reason: "is required"}
+		}
+		if !fieldType.matches(value) {
+			return &DetailsValidationError{Index: -1, Field: name, Reason: fmt.Sprintf("must be of type %s", fieldType)}
+		}
+	}
+	return nil
+}
+
+// matches reports whether a decoded JSON value has the field type
+func (t DetailsFieldType) matches(value interface{}) bool {
+	switch value.(type) {
+	case float64:
+		return t == DetailsNumber
+	case string:
+		return t == DetailsString
+	case bool:
+		return t == DetailsBoolean
+	case []interface{}:
+		return t == DetailsArray
+	case map[string]interface{}:
+		return t == DetailsObject
+	}
+	return false
+}
+
+// DetailsValidationError reports the details field that failed its action's schema
+type DetailsValidationError struct {
+	// Index is the position of the entry within a batch, or -1 for a single entry
+	Index int
+	// Field is empty when details as a whole is not a JSON object
+	Field  string
+	Reason string
+}
+
+// Path returns the failing field as seen in the request body, e.g. details.slides
+func (e *DetailsValidationError) Path() string {
+	if e.Field == "" {
+		return "details"
+	}
+	return "details." + e.Field
+}
+
+// Error implements the error interface
+func (e *DetailsValidationError) Error() string {
+	return fmt.Sprintf("%s: %s %s", ErrInvalidDetails, e.Path(), e.Reason)
+}
+
+// Unwrap allows errors.Is to match ErrInvalidDetails
+func (e *DetailsValidationError) Unwrap() error {
+	return ErrInvalidDetails
+}
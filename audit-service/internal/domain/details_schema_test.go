@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetailsSchemas_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		action        string
+		details       string
+		expectedField string
+		wantErr       bool
+	}{
+		{name: "valid merge", action: "merge", details: `{"slides":[1,2]}`},
+		{name: "merge missing slides", action: "merge", details: `{"slide":1}`, expectedField: "slides", wantErr: true},
+		{name: "merge slides not an array", action: "merge", details: `{"slides":"1,2"}`, expectedField: "slides", wantErr: true},
+		{name: "merge without details", action: "merge", details: ``, expectedField: "slides", wantErr: true},
+		{name: "merge details not an object", action: "merge", details: `[1,2]`, expectedField: "", wantErr: true},
+		{name: "valid edit", action: "edit", details: `{"slide":3,"text":"updated"}`},
+		{name: "edit slide not a number", action: "edit", details: `{"slide":"3"}`, expectedField: "slide", wantErr: true},
+		{name: "action without schema", action: "view", details: `"anything"`},
+	}
+
+	schemas := NewDetailsSchemas()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schemas.Validate(tt.action, json.RawMessage(tt.details))
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidDetails)
+			var detailsErr *DetailsValidationError
+			if assert.ErrorAs(t, err, &detailsErr) {
+				assert.Equal(t, tt.expectedField, detailsErr.Field)
+				assert.Equal(t, -1, detailsErr.Index)
+			}
+		})
+	}
+}
+
+func TestDetailsSchemas_Register(t *testing.T) {
+	schemas := NewDetailsSchemas()
+	schemas.Register("translate", DetailsSchema{"language": DetailsString})
+
+	assert.NoError(t, schemas.Validate("translate", json.RawMessage(`{"language":"de"}`)))
+	assert.ErrorIs(t, schemas.Validate("translate", json.RawMessage(`{"language":7}`)), ErrInvalidDetails)
+
+	// A nil registry accepts everything
+	var disabled DetailsSchemas
+	assert.NoError(t, disabled.Validate("merge", nil))
+}
+
+func TestDetailsValidationError_ToAPIError(t *testing.T) {
+	apiErr := ToAPIError(&DetailsValidationError{Index: 2, Field: "slides", Reason: "must be of type array"})
+	assert.Equal(t, 400, apiErr.Status)
+	assert.Equal(t, "details.slides must be of type array", apiErr.Message)
+	assert.Equal(t, map[string]interface{}{"field": "details.slides", "index": 2}, apiErr.Details)
+
+	apiErr = ToAPIError(&DetailsValidationError{Index: -1, Reason: "must be a JSON object"})
+	assert.Equal(t, map[string]interface{}{"field": "details"}, apiErr.Details)
+}
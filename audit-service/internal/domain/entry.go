@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// NewEntry builds an entry for a write from a request, checking what every
+// entry needs regardless of action: a session, a user and an action from the
+// set. Details are checked separately, against the deployment's schemas.
+// With ActorUserID set, which must be a UUID, the entry is recorded for that
+// user and userID is kept as PerformedBy; whether userID may do so is up to the
+// caller.
+func (s ActionSet) NewEntry(sessionID, userID string, req CreateAuditEntryRequest) (AuditEntry, error) {
+	if strings.TrimSpace(sessionID) == "" {
+		return AuditEntry{}, ErrInvalidSessionID
+	}
+	if strings.TrimSpace(userID) == "" {
+		return AuditEntry{}, ErrUnauthorized
+	}
+	if !s.Contains(req.Action) {
+		return AuditEntry{}, fmt.Errorf("%w: %q", ErrInvalidAction, req.Action)
+	}
+	if req.ActorUserID != "" && !IsValidUUID(req.ActorUserID) {
+		return AuditEntry{}, fmt.Errorf("%w: %q", ErrInvalidActorID, req.ActorUserID)
+	}
+	entry := AuditEntry{
+		SessionID: sessionID,
+		UserID:    userID,
+		Action:    req.Action,
+		Details:   req.Details,
+		IPAddress: req.IPAddress,
+		UserAgent: req.UserAgent,
+	}
+	if req.ActorUserID != "" {
+		entry.UserID = req.ActorUserID
+		entry.PerformedBy = userID
+	}
+	return entry, nil
+}
+
+// NewEditEntry returns an edit entry for a single slide. Slides are numbered from 1.
+func NewEditEntry(sessionID, userID string, slide int) (AuditEntry, error) {
+	if err := checkSlide(float64(slide)); err != nil {
+		return AuditEntry{}, err
+	}
+	return newBuiltinEntry(sessionID, userID, ActionEdit, map[string]interface{}{"slide": slide})
+}
+
+// NewMergeEntry returns a merge entry for two or more slides merged together
+func NewMergeEntry(sessionID, userID string, slides []int) (AuditEntry, error) {
+	numbers := make([]float64, len(slides))
+	for i, slide := range slides {
+		numbers[i] = float64(slide)
+	}
+	if err := checkSlides(numbers); err != nil {
+		return AuditEntry{}, err
+	}
+	return newBuiltinEntry(sessionID, userID, ActionMerge, map[string]interface{}{"slides": slides})
+}
+
+// ValidateBuiltinDetails applies the checks NewEditEntry and NewMergeEntry
+// make to request details, such as slides being numbered from 1, so entries
+// written from a request meet the same rules. Details are expected to have
+// passed the action's schema; actions without a constructor are not checked.
+func ValidateBuiltinDetails(action string, details json.RawMessage) error {
+	switch AuditAction(action) {
+	case ActionEdit:
+		var fields struct {
+			Slide float64 `json:"slide"`
+		}
+		if err := json.Unmarshal(details, &fields); err != nil {
+			return &DetailsValidationError{Index: -1, Field: "slide", Reason: "must be a number"}
+		}
+		return checkSlide(fields.Slide)
+	case ActionMerge:
+		var fields struct {
+			Slides []float64 `json:"slides"`
+		}
+		if err := json.Unmarshal(details, &fields); err != nil {
+			return &DetailsValidationError{Index: -1, Field: "slides", Reason: "must only contain numbers"}
+		}
+		return checkSlides(fields.Slides)
+	}
+	return nil
+}
+
+// checkSlide rejects an edit's slide unless it is a whole number from 1
+func checkSlide(slide float64) error {
+	if slide < 1 || slide != math.Trunc(slide) {
+		return &DetailsValidationError{Index: -1, Field: "slide", Reason: "must be a positive whole number"}
+	}
+	return nil
+}
+
+// checkSlides rejects a merge unless it lists two or more slides numbered from 1
+func checkSlides(slides []float64) error {
+	if len(slides) < 2 {
+		return &DetailsValidationError{Index: -1, Field: "slides", Reason: "must list at least two slides"}
+	}
+	for _, slide := range slides {
+		if slide < 1 || slide != math.Trunc(slide) {
+			return &DetailsValidationError{Index: -1, Field: "slides", Reason: "must only contain positive whole numbers"}
+		}
+	}
+	return nil
+}
+
+// newBuiltinEntry marshals details for a built-in action and checks them
+// against its built-in schema, so the constructors can't drift from it
+func newBuiltinEntry(sessionID, userID string, action AuditAction, details map[string]interface{}) (AuditEntry, error) {
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return AuditEntry{}, fmt.Errorf("%w: %v", ErrInvalidDetails, err)
+	}
+	if err := NewDetailsSchemas().Validate(string(action), raw); err != nil {
+		return AuditEntry{}, err
+	}
+	return NewActionSet(nil).NewEntry(sessionID, userID, CreateAuditEntryRequest{Action: string(action), Details: raw})
+}
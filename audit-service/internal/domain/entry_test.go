@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionSet_NewEntry(t *testing.T) {
+	actions := NewActionSet([]string{"translate"})
+
+	tests := []struct {
+		name          string
+		sessionID     string
+		userID        string
+		action        string
+		expectedError error
+	}{
+		{name: "built_in_action", sessionID: "session-1", userID: "user-1", action: "edit"},
+		{name: "extra_action", sessionID: "session-1", userID: "user-1", action: "translate"},
+		{name: "unknown_action", sessionID: "session-1", userID: "user-1", action: "delete", expectedError: ErrInvalidAction},
+		{name: "missing_session", sessionID: " ", userID: "user-1", action: "edit", expectedError: ErrInvalidSessionID},
+		{name: "missing_user", sessionID: "session-1", userID: "", action: "edit", expectedError: ErrUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := actions.NewEntry(tt.sessionID, tt.userID, CreateAuditEntryRequest{
+				Action:    tt.action,
+				Details:   json.RawMessage(`{"slide":1}`),
+				IPAddress: "10.0.0.1",
+			})
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, AuditEntry{
+				SessionID: tt.sessionID,
+				UserID:    tt.userID,
+				Action:    tt.action,
+				Details:   json.RawMessage(`{"slide":1}`),
+				IPAddress: "10.0.0.1",
+			}, entry)
+		})
+	}
+}
+
+func TestActionSet_NewEntry_ActorOverride(t *testing.T) {
+	const actorID = "550e8400-e29b-41d4-a716-446655440002"
+	entry, err := NewActionSet(nil).NewEntry("session-1", "admin-1", CreateAuditEntryRequest{Action: "edit", ActorUserID: actorID})
+
+	require.NoError(t, err)
+	assert.Equal(t, actorID, entry.UserID)
+	assert.Equal(t, "admin-1", entry.PerformedBy)
+
+	// An actor that isn't a UUID is rejected rather than stored
+	_, err = NewActionSet(nil).NewEntry("session-1", "admin-1", CreateAuditEntryRequest{Action: "edit", ActorUserID: "user-1"})
+	assert.ErrorIs(t, err, ErrInvalidActorID)
+	assert.Equal(t, "actorUserId must be a UUID", ToAPIError(err).Message)
+}
+
+func TestNewEditEntry(t *testing.T) {
+	entry, err := NewEditEntry("session-1", "user-1", 3)
+	require.NoError(t, err)
+	assert.Equal(t, string(ActionEdit), entry.Action)
+	assert.JSONEq(t, `{"slide":3}`, string(entry.Details))
+
+	for _, slide := range []int{0, -1} {
+		_, err := NewEditEntry("session-1", "user-1", slide)
+		assert.ErrorIs(t, err, ErrInvalidDetails)
+	}
+
+	_, err = NewEditEntry("", "user-1", 3)
+	assert.ErrorIs(t, err, ErrInvalidSessionID)
+}
+
+func TestNewMergeEntry(t *testing.T) {
+	entry, err := NewMergeEntry("session-1", "user-1", []int{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, string(ActionMerge), entry.Action)
+	assert.JSONEq(t, `{"slides":[1,2]}`, string(entry.Details))
+	require.NoError(t, NewDetailsSchemas().Validate(entry.Action, entry.Details))
+
+	tests := []struct {
+		name   string
+		slides []int
+	}{
+		{name: "nil", slides: nil},
+		{name: "single_slide", slides: []int{1}},
+		{name: "non_positive_slide", slides: []int{1, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMergeEntry("session-1", "user-1", tt.slides)
+			assert.ErrorIs(t, err, ErrInvalidDetails)
+			var detailsErr *DetailsValidationError
+			if assert.ErrorAs(t, err, &detailsErr) {
+				assert.Equal(t, "slides", detailsErr.Field)
+			}
+		})
+	}
+}
+
+func TestValidateBuiltinDetails(t *testing.T) {
+	tests := []struct {
+		name          string
+		action        string
+		details       string
+		expectedField string
+	}{
+		{name: "edit", action: "edit", details: `{"slide":3}`},
+		{name: "edit_zero_slide", action: "edit", details: `{"slide":0}`, expectedField: "slide"},
+		{name: "edit_fractional_slide", action: "edit", details: `{"slide":1.5}`, expectedField: "slide"},
+		{name: "merge", action: "merge", details: `{"slides":[1,2]}`},
+		{name: "merge_single_slide", action: "merge", details: `{"slides":[4]}`, expectedField: "slides"},
+		{name: "merge_negative_slide", action: "merge", details: `{"slides":[1,-2]}`, expectedField: "slides"},
+		{name: "other_action", action: "view", details: `{"slide":0}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBuiltinDetails(tt.action, json.RawMessage(tt.details))
+
+			if tt.expectedField == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var detailsErr *DetailsValidationError
+			require.ErrorAs(t, err, &detailsErr)
+			assert.Equal(t, tt.expectedField, detailsErr.Field)
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package domain
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Common domain errors
@@ -14,27 +16,95 @@ var (
 	ErrMissingToken = errors.New("missing authentication token")
 
 	// Authorization errors
-	ErrForbidden    = errors.New("forbidden")
-	ErrAccessDenied = errors.New("access denied to this resource")
+	ErrForbidden         = errors.New("forbidden")
+	ErrAccessDenied      = errors.New("access denied to this resource")
+	ErrShareTokenExpired = errors.New("share token expired")
 
 	// Resource errors
 	ErrNotFound        = errors.New("resource not found")
 	ErrSessionNotFound = errors.New("session not found")
+	ErrEntryNotFound   = errors.New("audit entry not found")
 
 	// Validation errors
-	ErrInvalidSessionID  = errors.New("invalid session ID format")
-	ErrInvalidPagination = errors.New("invalid pagination parameters")
+	ErrInvalidSessionID     = errors.New("invalid session ID format")
+	ErrInvalidPagination    = errors.New("invalid pagination parameters")
+	ErrInvalidWindow        = errors.New("invalid summary window")
+	ErrInvalidAction        = errors.New("invalid audit action")
+	ErrInvalidBatch         = errors.New("invalid batch")
+	ErrInvalidFields        = errors.New("invalid fields selection")
+	ErrInvalidInclude       = errors.New("invalid include")
+	ErrInvalidDetailsFilter = errors.New("invalid details filter")
+	ErrInvalidFilter        = errors.New("invalid filter expression")
+	ErrTooManyActions       = errors.New("too many action filters")
+	ErrInvalidDetails       = errors.New("invalid details")
+	ErrInvalidActorID       = errors.New("invalid actor user ID")
+	ErrResponseTooLarge     = errors.New("response too large")
+
+	// Conflict errors
+	ErrIdempotencyConflict = errors.New("idempotency key reused with a different request")
+	ErrPreconditionFailed  = errors.New("precondition failed")
 
 	// Service errors
 	ErrServiceUnavailable = errors.New("service temporarily unavailable")
+	ErrInternalServer     = errors.New("internal server error")
 	ErrTimeout            = errors.New("request timeout")
 )
 
+// RetryAfterError marks a failure the caller may retry once RetryAfter has
+// passed, e.g. when Supabase rate-limits us
+type RetryAfterError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+// Error implements the error interface
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap allows errors.Is to match the wrapped error
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// BatchValidationError reports which entries of a batch failed validation
+type BatchValidationError struct {
+	InvalidIndices []int
+}
+
+// Error implements the error interface
+func (e *BatchValidationError) Error() string {
+	return fmt.Sprintf("%s at indices %v", ErrInvalidAction, e.InvalidIndices)
+}
+
+// Unwrap allows errors.Is to match ErrInvalidAction
+func (e *BatchValidationError) Unwrap() error {
+	return ErrInvalidAction
+}
+
+// ActionFilterLimitError reports an action filter with more values than allowed
+type ActionFilterLimitError struct {
+	Max int
+}
+
+// Error implements the error interface
+func (e *ActionFilterLimitError) Error() string {
+	return fmt.Sprintf("%s: at most %d allowed", ErrTooManyActions, e.Max)
+}
+
+// Unwrap allows errors.Is to match ErrTooManyActions
+func (e *ActionFilterLimitError) Unwrap() error {
+	return ErrTooManyActions
+}
+
 // APIError represents an error response to be returned to the client
 type APIError struct {
-	Code    string `json:"error"`
-	Message string `json:"message"`
-	Status  int    `json:"-"`
+	Code    string      `json:"error"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty" swaggertype:"object"`
+	Status  int         `json:"-"`
+	// RetryAfter is sent as the Retry-After header when positive
+	RetryAfter time.Duration `json:"-"`
 }
 
 // Error implements the error interface
@@ -62,6 +132,12 @@ var (
 		Status:  403,
 	}
 
+	APIErrShareTokenExpired = &APIError{
+		Code:    "share_token_expired",
+		Message: "The share link has expired",
+		Status:  403,
+	}
+
 	APIErrNotFound = &APIError{
 		Code:    "not_found",
 		Message: "The requested resource was not found",
@@ -74,12 +150,54 @@ var (
 		Status:  405,
 	}
 
+	APIErrConflict = &APIError{
+		Code:    "conflict",
+		Message: "The request conflicts with a previous request",
+		Status:  409,
+	}
+
+	APIErrPreconditionFailed = &APIError{
+		Code:    "precondition_failed",
+		Message: "The entry has changed since it was last fetched",
+		Status:  412,
+	}
+
 	APIErrBadRequest = &APIError{
 		Code:    "bad_request",
 		Message: "Invalid request parameters",
 		Status:  400,
 	}
 
+	APIErrHTTPSRequired = &APIError{
+		Code:    "https_required",
+		Message: "Requests must be made over HTTPS",
+		Status:  400,
+	}
+
+	APIErrResponseTooLarge = &APIError{
+		Code:    "response_too_large",
+		Message: "The response is too large; request a smaller limit",
+		Status:  400,
+	}
+
+	APIErrPayloadTooLarge = &APIError{
+		Code:    "payload_too_large",
+		Message: "Request body exceeds the maximum allowed size",
+		Status:  413,
+	}
+
+	APIErrUnsupportedMediaType = &APIError{
+		Code:    "unsupported_media_type",
+		Message: "Content-Type must be application/json",
+		Status:  415,
+	}
+
+	APIErrTooManyRequests = &APIError{
+		Code:    "too_many_requests",
+		Message: "This share link has reached its read limit; try again later",
+		Status:  429,
+	}
+
 	APIErrInternalServer = &APIError{
 		Code:    "internal_server_error",
 		Message: "An internal server error occurred",
@@ -102,8 +220,59 @@ func NewAPIError(code string, message string, status int) *APIError {
 	}
 }
 
+// NewReservedSegmentError explains a route word, such as "summary", found
+// where the session ID belongs
+func NewReservedSegmentError(segment string) *APIError {
+	apiErr := NewAPIError("bad_request", fmt.Sprintf("%q is a reserved path segment, not a session ID", segment), 400)
+	apiErr.Details = map[string]string{"field": "sessionId"}
+	return apiErr
+}
+
 // ToAPIError converts domain errors to API errors
 func ToAPIError(err error) *APIError {
+	var batchErr *BatchValidationError
+	if errors.As(err, &batchErr) {
+		apiErr := NewAPIError("bad_request", "One or more entries have an invalid action", 400)
+		apiErr.Details = map[string]interface{}{"invalid_indices": batchErr.InvalidIndices}
+		return apiErr
+	}
+
+	var actionsErr *ActionFilterLimitError
+	if errors.As(err, &actionsErr) {
+		apiErr := NewAPIError("too_many_actions", fmt.Sprintf("At most %d action filters are allowed", actionsErr.Max), 400)
+		apiErr.Details = map[string]interface{}{"field": "action", "max": actionsErr.Max}
+		return apiErr
+	}
+
+	if errors.Is(err, ErrInvalidActorID) {
+		apiErr := NewAPIError(APIErrBadRequest.Code, "actorUserId must be a UUID", APIErrBadRequest.Status)
+		apiErr.Details = map[string]interface{}{"field": "actorUserId"}
+		return apiErr
+	}
+
+	var retryErr *RetryAfterError
+	if errors.As(err, &retryErr) {
+		apiErr := NewAPIError(APIErrServiceUnavailable.Code, APIErrServiceUnavailable.Message, APIErrServiceUnavailable.Status)
+		apiErr.RetryAfter = retryErr.RetryAfter
+		return apiErr
+	}
+
+	// Say which bound a strict page request broke, e.g. the maximum limit
+	if errors.Is(err, ErrInvalidPagination) {
+		return NewAPIError(APIErrBadRequest.Code, err.Error(), APIErrBadRequest.Status)
+	}
+
+	var detailsErr *DetailsValidationError
+	if errors.As(err, &detailsErr) {
+		apiErr := NewAPIError("bad_request", fmt.Sprintf("%s %s", detailsErr.Path(), detailsErr.Reason), 400)
+		details := map[string]interface{}{"field": detailsErr.Path()}
+		if detailsErr.Index >= 0 {
+			details["index"] = detailsErr.Index
+		}
+		apiErr.Details = details
+		return apiErr
+	}
+
 	switch {
 	case errors.Is(err, ErrUnauthorized),
 		errors.Is(err, ErrInvalidToken),
@@ -111,22 +280,47 @@ func ToAPIError(err error) *APIError {
 		errors.Is(err, ErrMissingToken):
 		return APIErrUnauthorized
 
+	case errors.Is(err, ErrShareTokenExpired):
+		return APIErrShareTokenExpired
+
 	case errors.Is(err, ErrForbidden),
 		errors.Is(err, ErrAccessDenied):
 		return APIErrForbidden
 
 	case errors.Is(err, ErrNotFound),
-		errors.Is(err, ErrSessionNotFound):
+		errors.Is(err, ErrSessionNotFound),
+		errors.Is(err, ErrEntryNotFound):
 		return APIErrNotFound
 
 	case errors.Is(err, ErrInvalidSessionID),
-		errors.Is(err, ErrInvalidPagination):
+		errors.Is(err, ErrInvalidWindow),
+		errors.Is(err, ErrInvalidAction),
+		errors.Is(err, ErrInvalidBatch),
+		errors.Is(err, ErrInvalidFields),
+		errors.Is(err, ErrInvalidInclude),
+		errors.Is(err, ErrInvalidDetailsFilter),
+		errors.Is(err, ErrInvalidFilter),
+		errors.Is(err, ErrTooManyActions),
+		errors.Is(err, ErrInvalidDetails):
 		return APIErrBadRequest
 
+	case errors.Is(err, ErrResponseTooLarge):
+		return APIErrResponseTooLarge
+
+	case errors.Is(err, ErrIdempotencyConflict):
+		return APIErrConflict
+
+	case errors.Is(err, ErrPreconditionFailed):
+		return APIErrPreconditionFailed
+
 	case errors.Is(err, ErrServiceUnavailable):
 		return APIErrServiceUnavailable
 
-	case errors.Is(err, ErrTimeout):
+	case errors.Is(err, ErrInternalServer):
+		return APIErrInternalServer
+
+	case errors.Is(err, ErrTimeout),
+		errors.Is(err, context.DeadlineExceeded):
 		return NewAPIError("timeout", "Request timeout", 504)
 
 	default:
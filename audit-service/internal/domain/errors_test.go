@@ -1,7 +1,10 @@
 package domain
 
 import (
+	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -65,6 +68,31 @@ func TestToAPIError(t *testing.T) {
 			inputError:  ErrAccessDenied,
 			expectedErr: APIErrForbidden,
 		},
+		{
+			name:        "share token expired error",
+			inputError:  ErrShareTokenExpired,
+			expectedErr: APIErrShareTokenExpired,
+		},
+		{
+			name:        "wrapped share token expired error",
+			inputError:  fmt.Errorf("validate share token: %w", ErrShareTokenExpired),
+			expectedErr: APIErrShareTokenExpired,
+		},
+		{
+			name:        "retry after error",
+			inputError:  &RetryAfterError{RetryAfter: 30 * time.Second, Err: ErrServiceUnavailable},
+			expectedErr: &APIError{Code: "service_unavailable", Message: "Service temporarily unavailable", Status: 503, RetryAfter: 30 * time.Second},
+		},
+		{
+			name:        "precondition failed error",
+			inputError:  ErrPreconditionFailed,
+			expectedErr: APIErrPreconditionFailed,
+		},
+		{
+			name:        "response too large error",
+			inputError:  fmt.Errorf("%w: 2048 bytes exceeds the 1024 byte limit", ErrResponseTooLarge),
+			expectedErr: APIErrResponseTooLarge,
+		},
 		{
 			name:        "not found error",
 			inputError:  ErrNotFound,
@@ -81,10 +109,34 @@ func TestToAPIError(t *testing.T) {
 			expectedErr: APIErrBadRequest,
 		},
 		{
-			name:        "invalid pagination error",
-			inputError:  ErrInvalidPagination,
+			name:       "invalid pagination error",
+			inputError: fmt.Errorf("%w: limit must be between 1 and 100", ErrInvalidPagination),
+			expectedErr: &APIError{
+				Code:    "bad_request",
+				Message: "invalid pagination parameters: limit must be between 1 and 100",
+				Status:  400,
+			},
+		},
+		{
+			name:        "invalid window error",
+			inputError:  ErrInvalidWindow,
 			expectedErr: APIErrBadRequest,
 		},
+		{
+			name:        "invalid action error",
+			inputError:  ErrInvalidAction,
+			expectedErr: APIErrBadRequest,
+		},
+		{
+			name:        "invalid batch error",
+			inputError:  ErrInvalidBatch,
+			expectedErr: APIErrBadRequest,
+		},
+		{
+			name:        "idempotency conflict error",
+			inputError:  ErrIdempotencyConflict,
+			expectedErr: APIErrConflict,
+		},
 		{
 			name:        "service unavailable error",
 			inputError:  ErrServiceUnavailable,
@@ -99,6 +151,15 @@ func TestToAPIError(t *testing.T) {
 				Status:  504,
 			},
 		},
+		{
+			name:       "context deadline exceeded",
+			inputError: fmt.Errorf("failed to fetch audit logs: %w", context.DeadlineExceeded),
+			expectedErr: &APIError{
+				Code:    "timeout",
+				Message: "Request timeout",
+				Status:  504,
+			},
+		},
 		{
 			name:        "unknown error",
 			inputError:  assert.AnError,
@@ -121,8 +182,11 @@ func TestCommonAPIErrors(t *testing.T) {
 	errors := []*APIError{
 		APIErrUnauthorized,
 		APIErrForbidden,
+		APIErrShareTokenExpired,
 		APIErrNotFound,
+		APIErrConflict,
 		APIErrBadRequest,
+		APIErrPayloadTooLarge,
 		APIErrInternalServer,
 		APIErrServiceUnavailable,
 	}
@@ -144,10 +208,15 @@ func TestDomainErrors(t *testing.T) {
 		ErrMissingToken,
 		ErrForbidden,
 		ErrAccessDenied,
+		ErrShareTokenExpired,
 		ErrNotFound,
 		ErrSessionNotFound,
 		ErrInvalidSessionID,
 		ErrInvalidPagination,
+		ErrInvalidWindow,
+		ErrInvalidAction,
+		ErrInvalidBatch,
+		ErrIdempotencyConflict,
 		ErrServiceUnavailable,
 		ErrTimeout,
 	}
@@ -157,3 +226,28 @@ func TestDomainErrors(t *testing.T) {
 		assert.NotEmpty(t, err.Error())
 	}
 }
+
+func TestActionFilterLimitError(t *testing.T) {
+	err := &ActionFilterLimitError{Max: 10}
+
+	assert.Equal(t, "too many action filters: at most 10 allowed", err.Error())
+	assert.ErrorIs(t, err, ErrTooManyActions)
+
+	apiErr := ToAPIError(fmt.Errorf("wrapped: %w", err))
+	assert.Equal(t, 400, apiErr.Status)
+	assert.Equal(t, "too_many_actions", apiErr.Code)
+	assert.Equal(t, "At most 10 action filters are allowed", apiErr.Message)
+	assert.Equal(t, map[string]interface{}{"field": "action", "max": 10}, apiErr.Details)
+}
+
+func TestBatchValidationError(t *testing.T) {
+	err := &BatchValidationError{InvalidIndices: []int{0, 2}}
+
+	assert.Equal(t, "invalid audit action at indices [0 2]", err.Error())
+	assert.ErrorIs(t, err, ErrInvalidAction)
+
+	apiErr := ToAPIError(fmt.Errorf("wrapped: %w", err))
+	assert.Equal(t, 400, apiErr.Status)
+	assert.Equal(t, "bad_request", apiErr.Code)
+	assert.Equal(t, map[string]interface{}{"invalid_indices": []int{0, 2}}, apiErr.Details)
+}
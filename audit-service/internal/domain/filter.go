@@ -0,0 +1,219 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HistoryFilter narrows down the audit history returned for a session
+type HistoryFilter struct {
+	// Fields lists the AuditEntry json fields to include; empty means all
+	Fields []string
+	// Details matches top-level keys of the details JSON by exact value
+	Details map[string]string
+	// SinceID restricts results to entries newer than this entry, oldest first
+	SinceID string
+	// Actions restricts results to any of these actions; empty means all
+	Actions []string
+	// ExcludeActions drops these actions from the results; it is only
+	// applied when Actions is empty
+	ExcludeActions []string
+	// From and To bound the entry timestamp to [From, To); zero means unbounded
+	From time.Time
+	To   time.Time
+	// IncludeSession embeds the session's metadata in the response
+	IncludeSession bool
+	// CountOnly counts the matching entries without fetching them
+	CountOnly bool
+	// IncludeDeleted also returns tombstoned entries, which are hidden by default
+	IncludeDeleted bool
+	// Where is a composite and/or filter applied on top of the others
+	Where *FilterExpr
+}
+
+// Key returns a canonical representation of the filter for use in cache keys
+func (f HistoryFilter) Key() string {
+	keys := make([]string, 0, len(f.Details))
+	for key := range f.Details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("fields=")
+	b.WriteString(strings.Join(f.Fields, ","))
+	if f.SinceID != "" {
+		b.WriteString("&since=" + f.SinceID)
+	}
+	if len(f.Actions) > 0 {
+		b.WriteString("&actions=" + strings.Join(f.Actions, ","))
+	}
+	if len(f.ExcludeActions) > 0 {
+		b.WriteString("&exclude=" + strings.Join(f.ExcludeActions, ","))
+	}
+	if !f.From.IsZero() {
+		b.WriteString("&from=" + f.From.UTC().Format(time.RFC3339Nano))
+	}
+	if !f.To.IsZero() {
+		b.WriteString("&to=" + f.To.UTC().Format(time.RFC3339Nano))
+	}
+	for _, key := range keys {
+		fmt.Fprintf(&b, "&details.%s=%s", key, f.Details[key])
+	}
+	if f.IncludeSession {
+		b.WriteString("&include=session")
+	}
+	if f.CountOnly {
+		b.WriteString("&count")
+	}
+	if f.IncludeDeleted {
+		b.WriteString("&deleted")
+	}
+	if f.Where != nil {
+		b.WriteString("&where=" + f.Where.String())
+	}
+	return b.String()
+}
+
+// IncludeSession is the include value that embeds session metadata
+const IncludeSession = "session"
+
+// ParseInclude parses include values given as repeated and/or
+// comma-separated parameters, reporting whether session metadata was
+// requested. Unknown values are rejected.
+func ParseInclude(values []string) (includeSession bool, err error) {
+	for _, value := range values {
+		for _, include := range strings.Split(value, ",") {
+			switch strings.TrimSpace(include) {
+			case "":
+			case IncludeSession:
+				includeSession = true
+			default:
+				return false, fmt.Errorf("%w: unknown value %q", ErrInvalidInclude, include)
+			}
+		}
+	}
+	return includeSession, nil
+}
+
+// ParseActions collects action filter values given as repeated and/or
+// comma-separated parameters, trimming blanks and dropping duplicates
+func ParseActions(values []string) []string {
+	seen := make(map[string]bool)
+	var actions []string
+	for _, value := range values {
+		for _, action := range strings.Split(value, ",") {
+			action = strings.TrimSpace(action)
+			if action == "" || seen[action] {
+				continue
+			}
+			seen[action] = true
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// detailsFilterKeys lists the details keys that may be filtered on and the
+// values each accepts. Keys end up in the PostgREST query, so only these are allowed.
+var detailsFilterKeys = map[string]*regexp.Regexp{
+	"slide": regexp.MustCompile(`^[0-9]{1,6}$`),
+}
+
+// ParseDetailsFilter validates a details filter of the form "key:value"
+func ParseDetailsFilter(raw string) (key, value string, err error) {
+	key, value, ok := strings.Cut(strings.TrimSpace(raw), ":")
+	if !ok {
+		return "", "", fmt.Errorf("%w: details filter must be key:value", ErrInvalidDetailsFilter)
+	}
+	if err := ValidateDetailsFilter(key, value); err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+// ValidateDetailsFilter checks that key is filterable and value is acceptable for it
+func ValidateDetailsFilter(key, value string) error {
+	pattern, ok := detailsFilterKeys[key]
+	if !ok {
+		return fmt.Errorf("%w: key %q is not filterable", ErrInvalidDetailsFilter, key)
+	}
+	if !pattern.MatchString(value) {
+		return fmt.Errorf("%w: invalid value for %q", ErrInvalidDetailsFilter, key)
+	}
+	return nil
+}
+
+// auditEntryFields lists the json field names of AuditEntry in declaration order
+var auditEntryFields = jsonFieldNames(reflect.TypeOf(AuditEntry{}))
+
+// AuditEntryFields returns the json field names that may be selected from an AuditEntry
+func AuditEntryFields() []string {
+	return append([]string(nil), auditEntryFields...)
+}
+
+// ParseFields parses a comma-separated list of AuditEntry json field names.
+// An empty value selects all fields; unknown or duplicate names are rejected.
+func ParseFields(raw string) ([]string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool)
+	var fields []string
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if !isAuditEntryField(field) {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidFields, field)
+		}
+		if seen[field] {
+			continue
+		}
+		seen[field] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// OmitField returns the field selection without name. An empty selection
+// stands for every field, so it is expanded first.
+func OmitField(fields []string, name string) []string {
+	if len(fields) == 0 {
+		fields = auditEntryFields
+	}
+	var kept []string
+	for _, field := range fields {
+		if field != name {
+			kept = append(kept, field)
+		}
+	}
+	return kept
+}
+
+// isAuditEntryField reports whether name is a json field of AuditEntry
+func isAuditEntryField(name string) bool {
+	for _, field := range auditEntryFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonFieldNames returns the json tag names of a struct type's exported fields
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// FilterExpr is a composite history filter such as
+// or(action.eq.edit,and(action.eq.merge,userId.eq.X)). It is either a logical
+// node (Logic and Children set) or a single condition (Column, Operator, Value).
+type FilterExpr struct {
+	Logic    string
+	Children []FilterExpr
+
+	Column   string
+	Operator string
+	Value    string
+}
+
+const (
+	// maxFilterConditions bounds the number of conditions in one expression
+	maxFilterConditions = 20
+	// maxFilterDepth bounds how deeply logical groups may nest
+	maxFilterDepth = 3
+)
+
+// filterColumns maps the AuditEntry json names that may be filtered on to
+// their database columns. Columns end up in the PostgREST query, so only these are allowed.
+var filterColumns = map[string]string{
+	"action":    "action",
+	"userId":    "user_id",
+	"timestamp": "timestamp",
+}
+
+// filterOperators lists the PostgREST operators a condition may use
+var filterOperators = map[string]bool{
+	"eq": true, "neq": true,
+	"gt": true, "gte": true,
+	"lt": true, "lte": true,
+}
+
+// filterValuePattern keeps values free of the characters that structure a
+// PostgREST logical expression: commas, parentheses, quotes and whitespace
+var filterValuePattern = regexp.MustCompile(`^[A-Za-z0-9_:.+-]{1,128}$`)
+
+// ParseFilterExpr parses a composite filter. Conditions are column.operator.value;
+// and(...) and or(...) group comma-separated conditions or further groups.
+func ParseFilterExpr(raw string) (*FilterExpr, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	conditions := 0
+	expr, err := parseFilterExpr(raw, 0, &conditions)
+	if err != nil {
+		return nil, err
+	}
+	return &expr, nil
+}
+
+func parseFilterExpr(raw string, depth int, conditions *int) (FilterExpr, error) {
+	for _, logic := range []string{"and", "or"} {
+		if !strings.HasPrefix(raw, logic+"(") {
+			continue
+		}
+		if !strings.HasSuffix(raw, ")") {
+			return FilterExpr{}, fmt.Errorf("%w: unbalanced parentheses", ErrInvalidFilter)
+		}
+		if depth >= maxFilterDepth {
+			return FilterExpr{}, fmt.Errorf("%w: groups nest deeper than %d", ErrInvalidFilter, maxFilterDepth)
+		}
+		parts, err := splitFilterGroup(raw[len(logic)+1 : len(raw)-1])
+		if err != nil {
+			return FilterExpr{}, err
+		}
+		expr := FilterExpr{Logic: logic, Children: make([]FilterExpr, 0, len(parts))}
+		for _, part := range parts {
+			child, err := parseFilterExpr(part, depth+1, conditions)
+			if err != nil {
+				return FilterExpr{}, err
+			}
+			expr.Children = append(expr.Children, child)
+		}
+		return expr, nil
+	}
+
+	*conditions++
+	if *conditions > maxFilterConditions {
+		return FilterExpr{}, fmt.Errorf("%w: more than %d conditions", ErrInvalidFilter, maxFilterConditions)
+	}
+	column, rest, _ := strings.Cut(raw, ".")
+	operator, value, _ := strings.Cut(rest, ".")
+	expr := FilterExpr{Column: column, Operator: operator, Value: value}
+	if err := expr.validateCondition(); err != nil {
+		return FilterExpr{}, err
+	}
+	return expr, nil
+}
+
+// splitFilterGroup splits the inside of a group on its top-level commas
+func splitFilterGroup(inner string) ([]string, error) {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range inner {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("%w: unbalanced parentheses", ErrInvalidFilter)
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, inner[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("%w: unbalanced parentheses", ErrInvalidFilter)
+	}
+	parts = append(parts, inner[start:])
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("%w: empty condition", ErrInvalidFilter)
+		}
+	}
+	return parts, nil
+}
+
+// validateCondition checks a single condition against the allowed columns,
+// operators and value characters
+func (e FilterExpr) validateCondition() error {
+	if _, ok := filterColumns[e.Column]; !ok {
+		return fmt.Errorf("%w: column %q is not filterable", ErrInvalidFilter, e.Column)
+	}
+	if !filterOperators[e.Operator] {
+		return fmt.Errorf("%w: operator %q is not allowed", ErrInvalidFilter, e.Operator)
+	}
+	if !filterValuePattern.MatchString(e.Value) {
+		return fmt.Errorf("%w: invalid value for %q", ErrInvalidFilter, e.Column)
+	}
+	return nil
+}
+
+// PostgREST renders the expression for use inside a PostgREST and=()/or=()
+// parameter, e.g. or(action.eq.edit,user_id.eq.X). Every condition is
+// validated again, since the result is interpolated into the query.
+func (e FilterExpr) PostgREST() (string, error) {
+	if e.Logic == "" {
+		if err := e.validateCondition(); err != nil {
+			return "", err
+		}
+		return filterColumns[e.Column] + "." + e.Operator + "." + e.Value, nil
+	}
+	if e.Logic != "and" && e.Logic != "or" {
+		return "", fmt.Errorf("%w: unknown group %q", ErrInvalidFilter, e.Logic)
+	}
+	children := make([]string, 0, len(e.Children))
+	for _, child := range e.Children {
+		rendered, err := child.PostgREST()
+		if err != nil {
+			return "", err
+		}
+		children = append(children, rendered)
+	}
+	return e.Logic + "(" + strings.Join(children, ",") + ")", nil
+}
+
+// String returns the expression in the syntax ParseFilterExpr accepts
+func (e FilterExpr) String() string {
+	if e.Logic == "" {
+		return e.Column + "." + e.Operator + "." + e.Value
+	}
+	children := make([]string, 0, len(e.Children))
+	for _, child := range e.Children {
+		children = append(children, child.String())
+	}
+	return e.Logic + "(" + strings.Join(children, ",") + ")"
+}
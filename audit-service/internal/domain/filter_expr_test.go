@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilterExpr(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		postgres string
+		wantErr  bool
+	}{
+		{name: "single condition", raw: "action.eq.edit", postgres: "action.eq.edit"},
+		{name: "or of conditions", raw: "or(action.eq.edit,action.eq.merge)", postgres: "or(action.eq.edit,action.eq.merge)"},
+		{name: "nested and maps columns", raw: "or(action.eq.edit,and(action.eq.merge,userId.eq.user-1))", postgres: "or(action.eq.edit,and(action.eq.merge,user_id.eq.user-1))"},
+		{name: "timestamp value", raw: "timestamp.gte.2024-01-15T10:00:00.5+05:30", postgres: "timestamp.gte.2024-01-15T10:00:00.5+05:30"},
+		{name: "disallowed column", raw: "session_id.eq.x", wantErr: true},
+		{name: "disallowed operator", raw: "action.like.edit", wantErr: true},
+		{name: "missing value", raw: "action.eq", wantErr: true},
+		{name: "comma smuggled into value", raw: "action.eq.edit,session_id.neq.x", wantErr: true},
+		{name: "group closed early", raw: "or(action.eq.edit),and(session_id.neq.x)", wantErr: true},
+		{name: "unbalanced parentheses", raw: "or(action.eq.edit", wantErr: true},
+		{name: "empty group", raw: "and()", wantErr: true},
+		{name: "quoted value", raw: `action.eq."edit"`, wantErr: true},
+		{name: "too deep", raw: "or(and(or(and(action.eq.edit))))", wantErr: true},
+		{name: "too many conditions", raw: "or(" + strings.TrimSuffix(strings.Repeat("action.eq.edit,", 21), ",") + ")", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseFilterExpr(tt.raw)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidFilter)
+				return
+			}
+			require.NoError(t, err)
+			rendered, err := expr.PostgREST()
+			require.NoError(t, err)
+			assert.Equal(t, tt.postgres, rendered)
+			assert.Equal(t, tt.raw, expr.String())
+		})
+	}
+}
+
+func TestParseFilterExpr_Empty(t *testing.T) {
+	expr, err := ParseFilterExpr("  ")
+	assert.NoError(t, err)
+	assert.Nil(t, expr)
+}
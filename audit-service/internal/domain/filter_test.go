@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected []string
+		wantErr  bool
+	}{
+		{name: "empty selects all", raw: "", expected: nil},
+		{name: "subset", raw: "id,action,timestamp", expected: []string{"id", "action", "timestamp"}},
+		{name: "whitespace trimmed", raw: " id , userId ", expected: []string{"id", "userId"}},
+		{name: "duplicates collapsed", raw: "id,id,action", expected: []string{"id", "action"}},
+		{name: "unknown field", raw: "id,password", wantErr: true},
+		{name: "column name instead of json name", raw: "session_id", wantErr: true},
+		{name: "empty element", raw: "id,,action", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := ParseFields(tt.raw)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidFields)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, fields)
+		})
+	}
+}
+
+func TestAuditEntryFields(t *testing.T) {
+	assert.Equal(t, []string{
+		"id", "sessionId", "userId", "action", "timestamp", "details", "ipAddress", "userAgent", "deletedAt", "performedBy",
+	}, AuditEntryFields())
+}
+
+func TestOmitField(t *testing.T) {
+	assert.Equal(t, []string{
+		"id", "sessionId", "userId", "action", "timestamp", "ipAddress", "userAgent", "deletedAt", "performedBy",
+	}, OmitField(nil, "details"))
+	assert.Equal(t, []string{"id", "action"}, OmitField([]string{"id", "details", "action"}, "details"))
+	assert.Empty(t, OmitField([]string{"details"}, "details"))
+
+	// The shared field list is left intact
+	assert.Contains(t, AuditEntryFields(), "details")
+}
+
+func TestParseDetailsFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		wantKey   string
+		wantValue string
+		wantErr   bool
+	}{
+		{name: "slide", raw: "slide:3", wantKey: "slide", wantValue: "3"},
+		{name: "missing separator", raw: "slide3", wantErr: true},
+		{name: "unknown key", raw: "author:bob", wantErr: true},
+		{name: "operator injection in key", raw: "slide->>x:1", wantErr: true},
+		{name: "non-numeric slide", raw: "slide:3,4", wantErr: true},
+		{name: "operator injection in value", raw: "slide:eq.3", wantErr: true},
+		{name: "empty value", raw: "slide:", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, value, err := ParseDetailsFilter(tt.raw)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidDetailsFilter)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantKey, key)
+			assert.Equal(t, tt.wantValue, value)
+		})
+	}
+}
+
+func TestParseActions(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected []string
+	}{
+		{name: "none", values: nil, expected: nil},
+		{name: "comma_separated", values: []string{"edit,view"}, expected: []string{"edit", "view"}},
+		{name: "repeated", values: []string{"edit", "view"}, expected: []string{"edit", "view"}},
+		{name: "duplicates_removed", values: []string{"edit,view,edit", "view"}, expected: []string{"edit", "view"}},
+		{name: "blanks_dropped", values: []string{" edit , ,", ""}, expected: []string{"edit"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseActions(tt.values))
+		})
+	}
+}
+
+func TestParseInclude(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected bool
+		wantErr  bool
+	}{
+		{name: "none", values: nil, expected: false},
+		{name: "session", values: []string{"session"}, expected: true},
+		{name: "repeated_and_blank", values: []string{" session ,", "session"}, expected: true},
+		{name: "unknown", values: []string{"session,owner"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			includeSession, err := ParseInclude(tt.values)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidInclude)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, includeSession)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// ShareGrant describes an active share token without revealing it
+type ShareGrant struct {
+	// Token is masked down to its last four characters
+	Token     string     `json:"token" example:"****f3a9"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" example:"2023-12-08T10:30:00Z"`
+}
+
+// ShareListResponse lists who can access a session through share tokens
+type ShareListResponse struct {
+	Items []ShareGrant `json:"items"`
+}
+
+// MaskToken hides all but the last four characters of a token. Tokens of
+// eight characters or fewer are hidden entirely, since four would be half of them.
+func MaskToken(token string) string {
+	const visible = 4
+	if len(token) <= 2*visible {
+		return strings.Repeat("*", visible)
+	}
+	return strings.Repeat("*", visible) + token[len(token)-visible:]
+}
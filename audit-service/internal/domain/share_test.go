@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		expected string
+	}{
+		{name: "long_token", token: "share-token-abcdef123456", expected: "****3456"},
+		{name: "just_over_threshold", token: "abcdefghi", expected: "****fghi"},
+		{name: "short_token", token: "abcd1234", expected: "****"},
+		{name: "empty", token: "", expected: "****"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MaskToken(tt.token))
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuditSummary aggregates a session's audit activity over a time window
+type AuditSummary struct {
+	SessionID      string         `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Window         string         `json:"window" example:"168h0m0s"`
+	TotalCount     int            `json:"totalCount" example:"42"`
+	ActionCounts   map[string]int `json:"actionCounts"`
+	LastActivity   *time.Time     `json:"lastActivity,omitempty" example:"2023-12-01T10:30:00Z"`
+	OldestActivity *time.Time     `json:"oldestActivity,omitempty" example:"2023-11-01T09:00:00Z"`
+}
+
+// SessionSummary describes one of a user's sessions and when it was last audited
+type SessionSummary struct {
+	SessionID    string     `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	UserID       string     `json:"userId" example:"550e8400-e29b-41d4-a716-446655440002"`
+	LastActivity *time.Time `json:"lastActivity,omitempty" example:"2023-12-01T10:30:00Z"`
+}
+
+// SessionListResponse lists a user's sessions, most recently active first
+type SessionListResponse struct {
+	Items []SessionSummary `json:"items"`
+}
+
+// ParseWindow parses a summary time window such as "7d", "30d" or "12h".
+// An empty value resolves to defaultWindow; values beyond maxWindow are rejected.
+func ParseWindow(raw string, defaultWindow, maxWindow time.Duration) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return defaultWindow, nil
+	}
+
+	var window time.Duration
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidWindow, raw)
+		}
+		window = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidWindow, raw)
+		}
+		window = d
+	}
+
+	if window <= 0 {
+		return 0, fmt.Errorf("%w: must be positive", ErrInvalidWindow)
+	}
+	if maxWindow > 0 && window > maxWindow {
+		return 0, fmt.Errorf("%w: exceeds maximum of %s", ErrInvalidWindow, maxWindow)
+	}
+
+	return window, nil
+}
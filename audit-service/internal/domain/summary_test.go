@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWindow(t *testing.T) {
+	defaultWindow := 7 * 24 * time.Hour
+	maxWindow := 30 * 24 * time.Hour
+
+	tests := []struct {
+		name        string
+		raw         string
+		expected    time.Duration
+		expectError bool
+	}{
+		{
+			name:     "default_when_empty",
+			raw:      "",
+			expected: defaultWindow,
+		},
+		{
+			name:     "days_suffix",
+			raw:      "7d",
+			expected: 7 * 24 * time.Hour,
+		},
+		{
+			name:     "at_max",
+			raw:      "30d",
+			expected: maxWindow,
+		},
+		{
+			name:     "go_duration",
+			raw:      "12h",
+			expected: 12 * time.Hour,
+		},
+		{
+			name:        "over_max",
+			raw:         "31d",
+			expectError: true,
+		},
+		{
+			name:        "zero_window",
+			raw:         "0d",
+			expectError: true,
+		},
+		{
+			name:        "negative_window",
+			raw:         "-1h",
+			expectError: true,
+		},
+		{
+			name:        "malformed",
+			raw:         "week",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, err := ParseWindow(tt.raw, defaultWindow, maxWindow)
+			if tt.expectError {
+				assert.ErrorIs(t, err, ErrInvalidWindow)
+				assert.Equal(t, APIErrBadRequest, ToAPIError(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, window)
+		})
+	}
+}
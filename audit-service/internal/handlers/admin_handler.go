@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+	"audit-service/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AdminHandler handles administrative requests
+type AdminHandler struct {
+	tokenCache *cache.TokenCache
+	logger     *zap.Logger
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(tokenCache *cache.TokenCache, logger *zap.Logger) *AdminHandler {
+	return &AdminHandler{
+		tokenCache: tokenCache,
+		logger:     logger,
+	}
+}
+
+// InvalidateUserTokensResponse reports how many cached tokens were dropped
+type InvalidateUserTokensResponse struct {
+	UserID      string `json:"userId"`
+	Invalidated int    `json:"invalidated"`
+}
+
+// InvalidateUserTokens handles DELETE /admin/users/{userId}/tokens
+// @Summary Invalidate a user's cached tokens
+// @Description Drops every cached JWT for the user so the next request must be revalidated
+// @Tags Admin
+// @Produce json
+// @Param userId path string true "User ID"
+// @Security BearerAuth
+// @Success 200 {object} InvalidateUserTokensResponse
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Router /admin/users/{userId}/tokens [delete]
+func (h *AdminHandler) InvalidateUserTokens(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", "User ID is required", http.StatusBadRequest))
+		return
+	}
+
+	invalidated := h.tokenCache.InvalidateUser(userID)
+
+	h.logger.Info("invalidated cached tokens for user",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.String("user_id", userID),
+		zap.Int("invalidated", invalidated),
+	)
+
+	c.JSON(http.StatusOK, InvalidateUserTokensResponse{
+		UserID:      userID,
+		Invalidated: invalidated,
+	})
+}
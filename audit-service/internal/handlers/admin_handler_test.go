@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"audit-service/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestAdminHandler_InvalidateUserTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	expiresAt := time.Now().Add(time.Hour)
+	tokenCache.SetJWT("token-a", &cache.CachedTokenInfo{UserID: "user-1", ExpiresAt: expiresAt})
+	tokenCache.SetJWT("token-b", &cache.CachedTokenInfo{UserID: "user-1", ExpiresAt: expiresAt})
+
+	handler := NewAdminHandler(tokenCache, zap.NewNop())
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("DELETE", "/admin/users/user-1/tokens", nil)
+	c.Params = []gin.Param{{Key: "userId", Value: "user-1"}}
+
+	handler.InvalidateUserTokens(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response InvalidateUserTokensResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "user-1", response.UserID)
+	assert.Equal(t, 2, response.Invalidated)
+
+	_, found := tokenCache.GetJWT("token-a")
+	assert.False(t, found)
+	_, found = tokenCache.GetJWT("token-b")
+	assert.False(t, found)
+}
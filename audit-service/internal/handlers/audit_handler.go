@@ -1,8 +1,13 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"audit-service/internal/domain"
 	"audit-service/internal/middleware"
@@ -16,13 +21,16 @@ import (
 type AuditHandler struct {
 	service service.AuditService
 	logger  *zap.Logger
+	// maxResponseBytes caps a serialized history page (MAX_RESPONSE_BYTES)
+	maxResponseBytes int64
 }
 
 // NewAuditHandler creates a new audit handler
-func NewAuditHandler(service service.AuditService, logger *zap.Logger) *AuditHandler {
+func NewAuditHandler(service service.AuditService, maxResponseBytes int64, logger *zap.Logger) *AuditHandler {
 	return &AuditHandler{
-		service: service,
-		logger:  logger,
+		service:          service,
+		logger:           logger,
+		maxResponseBytes: maxResponseBytes,
 	}
 }
 
@@ -33,11 +41,28 @@ func NewAuditHandler(service service.AuditService, logger *zap.Logger) *AuditHan
 // @Accept json
 // @Produce json
 // @Param sessionId path string true "Session ID"
-// @Param limit query int false "Number of items to return (default: 50, max: 100)"
+// @Param limit query int false "Number of items to return (default and max are configurable; 50 and 100 unless changed)"
 // @Param offset query int false "Number of items to skip (default: 0)"
+// @Param fields query string false "Comma-separated entry fields to include, e.g. id,action,timestamp (default: all)"
+// @Param details query bool false "Set to false to leave details out of the query and the response (default: true)"
+// @Param action query []string false "Only return these actions; comma-separated or repeatable" collectionFormat(multi)
+// @Param sinceId query string false "Only return entries newer than this entry ID, oldest first (for incremental sync)"
+// @Param wait query string false "With sinceId, hold the request up to this long (e.g. 30s, capped at LONG_POLL_MAX_WAIT) until newer entries exist; an empty page is returned if none arrive"
+// @Param from query string false "Only return entries at or after this RFC3339 timestamp"
+// @Param to query string false "Only return entries before this RFC3339 timestamp; must be after from"
+// @Param tz query string false "IANA time zone (e.g. Europe/Berlin) for from/to values given without an offset"
+// @Param include query []string false "Related data to embed; currently only session (title and owner)" collectionFormat(multi)
+// @Param count query bool false "Only return totalCount, without fetching any entries"
+// @Param includeDeleted query bool false "Also return tombstoned entries, with deletedAt set; owner only"
+// @Param filter query string false "Composite filter, e.g. or(action.eq.edit,and(action.eq.merge,userId.eq.<id>)); columns action, userId, timestamp; operators eq, neq, gt, gte, lt, lte"
+// @Param slide query int false "Only include entries whose details reference this slide"
+// @Param detailsFilter query []string false "Details filter as key:value; repeatable" collectionFormat(multi)
 // @Param share_token query string false "Share token for reviewer access"
 // @Security BearerAuth
 // @Success 200 {object} domain.AuditResponse
+// @Header 200 {string} Link "Pagination links (rel=next, rel=prev)"
+// @Header 200 {string} X-Count-Mode "How totalCount was computed: exact, estimated or planned"
+// @Header 200 {string} X-Served-Stale "Set to true when cached data is served during a Supabase outage"
 // @Failure 400 {object} domain.APIError
 // @Failure 401 {object} domain.APIError
 // @Failure 403 {object} domain.APIError
@@ -47,37 +72,69 @@ func NewAuditHandler(service service.AuditService, logger *zap.Logger) *AuditHan
 func (h *AuditHandler) GetHistory(c *gin.Context) {
 	requestID := middleware.GetRequestID(c)
 
-	// Extract session ID from path
-	sessionID := c.Param("sessionId")
-	if sessionID == "" {
-		c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", "Session ID is required", http.StatusBadRequest))
-		return
-	}
-
-	// Validate UUID format
-	if !isValidUUID(sessionID) {
-		c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", "Invalid session ID format", http.StatusBadRequest))
+	// Extract session ID from the path, or the X-Session-Id header on GET /history
+	sessionID, ok := sessionIDParam(c)
+	if !ok {
 		return
 	}
 
-	// Parse pagination parameters
-	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
-	if err != nil || limit < 0 {
-		c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", "Invalid limit parameter", http.StatusBadRequest))
+	if !singleValuedParams(c, historySingleParams...) {
 		return
 	}
 
-	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
-	if err != nil || offset < 0 {
-		c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", "Invalid offset parameter", http.StatusBadRequest))
+	// Bind and validate pagination, action, sinceId and time range parameters
+	query, ok := bindHistoryQuery(c)
+	if !ok {
 		return
 	}
+	limit, offset := query.Limit, query.Offset
 
 	pagination := domain.PaginationParams{
 		Limit:  limit,
 		Offset: offset,
 	}
 
+	fields, err := domain.ParseFields(c.Query("fields"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", "Invalid fields parameter", http.StatusBadRequest))
+		return
+	}
+	// Lightweight views skip the details blob, in the Supabase select as well
+	if query.omitDetails() {
+		fields = domain.OmitField(fields, "details")
+		if len(fields) == 0 {
+			writeQueryError(c, "details", "excludes every selected field")
+			return
+		}
+	}
+	details, err := parseDetailsFilters(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", "Invalid details filter", http.StatusBadRequest))
+		return
+	}
+	includeSession, err := domain.ParseInclude(query.Include)
+	if err != nil {
+		writeQueryError(c, "include", "")
+		return
+	}
+	where, err := domain.ParseFilterExpr(c.Query("filter"))
+	if err != nil {
+		writeQueryError(c, "filter", strings.TrimPrefix(err.Error(), domain.ErrInvalidFilter.Error()+": "))
+		return
+	}
+	filter := domain.HistoryFilter{
+		Fields:         fields,
+		Details:        details,
+		SinceID:        query.SinceID,
+		Actions:        domain.ParseActions(query.Actions),
+		From:           query.From,
+		To:             query.To,
+		IncludeSession: includeSession,
+		CountOnly:      query.Count,
+		IncludeDeleted: query.IncludeDeleted,
+		Where:          where,
+	}
+
 	// Get auth info from context
 	userID := middleware.GetAuthUserID(c)
 	tokenType := middleware.GetAuthTokenType(c)
@@ -88,44 +145,729 @@ func (h *AuditHandler) GetHistory(c *gin.Context) {
 		zap.String("session_id", sessionID),
 		zap.String("user_id", userID),
 		zap.Bool("share_token", isShareToken),
-		zap.Int("limit", limit),
-		zap.Int("offset", offset),
+		zap.Int("requested_limit", limit),
+		zap.Int("requested_offset", offset),
+		zap.Strings("fields", fields),
+		zap.Any("details_filter", details),
 	)
 
+	// Long polling holds the read until entries newer than sinceId exist
+	if query.Wait > 0 {
+		if filter.SinceID == "" {
+			writeQueryError(c, "wait", "requires sinceId")
+			return
+		}
+		found, err := h.service.WaitForEntries(c.Request.Context(), sessionID, userID, isShareToken, filter, query.Wait)
+		if err != nil {
+			apiErr := domain.ToAPIError(err)
+			middleware.WriteAPIError(c, apiErr)
+			return
+		}
+		if !found {
+			if filter.CountOnly {
+				c.JSON(http.StatusOK, gin.H{"totalCount": 0})
+				return
+			}
+			c.JSON(http.StatusOK, domain.AuditResponse{Items: []domain.AuditEntry{}})
+			return
+		}
+	}
+
 	// Call service
-	response, err := h.service.GetAuditLogs(c.Request.Context(), sessionID, userID, isShareToken, pagination)
+	response, err := h.service.GetAuditLogs(c.Request.Context(), sessionID, userID, isShareToken, pagination, filter)
 	if err != nil {
 		// Handle specific errors
 		apiErr := domain.ToAPIError(err)
-		c.JSON(apiErr.Status, apiErr)
+		middleware.WriteAPIError(c, apiErr)
+		return
+	}
+
+	// Log the pagination the service actually applied after clamping
+	h.logger.Debug("audit history resolved",
+		zap.String("request_id", requestID),
+		zap.String("session_id", sessionID),
+		zap.Int("limit", response.Pagination.Limit),
+		zap.Int("offset", response.Pagination.Offset),
+		zap.Int("count", len(response.Items)),
+	)
+	middleware.SetRowsServed(c, len(response.Items))
+
+	// Let clients know whether totalCount is exact or an estimate
+	if response.CountMode != "" {
+		c.Header("X-Count-Mode", response.CountMode)
+	}
+
+	// Let clients know the data may be out of date
+	if response.Stale {
+		c.Header("X-Served-Stale", "true")
+	}
+
+	// Count-only reads have no page to link or project
+	if filter.CountOnly {
+		c.JSON(http.StatusOK, gin.H{"totalCount": response.TotalCount})
+		return
+	}
+
+	// Add pagination links for hypermedia clients
+	if link := buildLinkHeader(c.Request.URL, response.Pagination, response.TotalCount); link != "" {
+		c.Header("Link", link)
+	}
+
+	// Only echo the selected fields so unselected ones don't show up as zero values
+	if len(fields) > 0 {
+		items, err := projectEntries(response.Items, fields)
+		if err != nil {
+			apiErr := domain.ToAPIError(err)
+			middleware.WriteAPIError(c, apiErr)
+			return
+		}
+		body := gin.H{"totalCount": response.TotalCount, "items": items}
+		if response.Session != nil {
+			body["session"] = response.Session
+		}
+		if response.HasMore != nil {
+			body["hasMore"] = *response.HasMore
+		}
+		if response.OffsetBeyondTotal {
+			body["offsetBeyondTotal"] = true
+		}
+		h.writeHistory(c, body)
 		return
 	}
 
 	// Success response
-	c.JSON(http.StatusOK, response)
+	h.writeHistory(c, response)
+}
+
+// writeHistory serializes a history body once and writes it, or rejects it
+// with 400 when the encoding exceeds MAX_RESPONSE_BYTES. The size is taken
+// after projection, so fields= can bring a page back under the limit.
+func (h *AuditHandler) writeHistory(c *gin.Context, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		middleware.WriteAPIError(c, domain.ToAPIError(fmt.Errorf("failed to encode audit logs: %w", err)))
+		return
+	}
+	if int64(len(data)) > h.maxResponseBytes {
+		h.logger.Warn("audit history response too large",
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.String("path", c.FullPath()),
+			zap.Int("bytes", len(data)),
+			zap.Int64("limit", h.maxResponseBytes),
+		)
+		middleware.WriteAPIError(c, domain.APIErrResponseTooLarge)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// parseDetailsFilters collects the slide shorthand and detailsFilter params
+func parseDetailsFilters(c *gin.Context) (map[string]string, error) {
+	var details map[string]string
+	add := func(key, value string) {
+		if details == nil {
+			details = make(map[string]string)
+		}
+		details[key] = value
+	}
+
+	if slide, ok := c.GetQuery("slide"); ok {
+		if err := domain.ValidateDetailsFilter("slide", slide); err != nil {
+			return nil, err
+		}
+		add("slide", slide)
+	}
+	for _, raw := range c.QueryArray("detailsFilter") {
+		key, value, err := domain.ParseDetailsFilter(raw)
+		if err != nil {
+			return nil, err
+		}
+		add(key, value)
+	}
+	return details, nil
+}
+
+// projectEntries reduces each entry to the requested json fields
+func projectEntries(entries []domain.AuditEntry, fields []string) ([]map[string]json.RawMessage, error) {
+	items := make([]map[string]json.RawMessage, 0, len(entries))
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, err
+		}
+		item := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, ok := all[field]; ok {
+				item[field] = value
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
 }
 
-// isValidUUID validates if a string is a valid UUID
-func isValidUUID(uuid string) bool {
-	// Simple UUID validation - check format
-	if len(uuid) != 36 {
-		return false
+// GetSummary handles GET /sessions/{sessionId}/summary
+// @Summary Get an activity summary for a session
+// @Description Counts audit actions within a time window and reports the oldest and most recent retained activity
+// @Tags Audit
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param window query string false "Aggregation window such as 7d or 12h (default and max are configurable)"
+// @Param share_token query string false "Share token for reviewer access"
+// @Security BearerAuth
+// @Success 200 {object} domain.AuditSummary
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /sessions/{sessionId}/summary [get]
+func (h *AuditHandler) GetSummary(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	sessionID, ok := sessionIDParam(c)
+	if !ok {
+		return
 	}
 
-	// Check for hyphens at correct positions
-	if uuid[8] != '-' || uuid[13] != '-' || uuid[18] != '-' || uuid[23] != '-' {
-		return false
+	if !singleValuedParams(c, "window") {
+		return
 	}
 
-	// Check that all other characters are hex
-	for i, char := range uuid {
-		if i == 8 || i == 13 || i == 18 || i == 23 {
+	userID := middleware.GetAuthUserID(c)
+	isShareToken := middleware.GetAuthTokenType(c) == middleware.TokenTypeShare
+	window := c.Query("window")
+
+	h.logger.Debug("processing audit summary request",
+		zap.String("request_id", requestID),
+		zap.String("session_id", sessionID),
+		zap.String("user_id", userID),
+		zap.Bool("share_token", isShareToken),
+		zap.String("window", window),
+	)
+
+	summary, err := h.service.GetSummary(c.Request.Context(), sessionID, userID, isShareToken, window)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteAPIError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// GetEntry handles GET /sessions/{sessionId}/history/{entryId}
+// @Summary Get a single audit entry
+// @Description Returns one audit entry with an ETag for optimistic concurrency. A request whose If-Match doesn't match the current ETag fails with 412.
+// @Tags Audit
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param entryId path string true "Audit entry ID"
+// @Param If-Match header string false "Only succeed if the entry still has this ETag"
+// @Param share_token query string false "Share token for reviewer access"
+// @Security BearerAuth
+// @Success 200 {object} domain.AuditEntry
+// @Header 200 {string} ETag "Entity tag derived from the entry's id and timestamp"
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 412 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /sessions/{sessionId}/history/{entryId} [get]
+func (h *AuditHandler) GetEntry(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	sessionID, ok := sessionIDParam(c)
+	if !ok {
+		return
+	}
+
+	entryID := c.Param("entryId")
+	if !domain.IsValidUUID(entryID) {
+		c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", "Invalid entry ID format", http.StatusBadRequest))
+		return
+	}
+
+	userID := middleware.GetAuthUserID(c)
+	isShareToken := middleware.GetAuthTokenType(c) == middleware.TokenTypeShare
+
+	h.logger.Debug("processing audit entry request",
+		zap.String("request_id", requestID),
+		zap.String("session_id", sessionID),
+		zap.String("entry_id", entryID),
+		zap.String("user_id", userID),
+		zap.Bool("share_token", isShareToken),
+	)
+
+	entry, err := h.service.GetAuditEntry(c.Request.Context(), sessionID, userID, isShareToken, entryID)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteAPIError(c, apiErr)
+		return
+	}
+
+	etag := entry.ETag()
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" && !etagMatches(ifMatch, etag) {
+		apiErr := domain.ToAPIError(domain.ErrPreconditionFailed)
+		middleware.WriteAPIError(c, apiErr)
+		return
+	}
+
+	c.Header("ETag", etag)
+	middleware.SetRowsServed(c, 1)
+	c.JSON(http.StatusOK, entry)
+}
+
+// etagMatches reports whether an If-Match header value matches the current
+// ETag, using the strong comparison RFC 9110 requires (weak tags never match)
+func etagMatches(ifMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// GetHistoryBatch handles GET /history/batch
+// @Summary Get audit history for several shared sessions
+// @Description Retrieves one page of audit history per session for multi-session dashboards. Share tokens pair with the sessions by position and each is checked against its own session; sessions whose token is invalid carry an error instead of entries.
+// @Tags Audit
+// @Produce json
+// @Param sessions query string true "Comma-separated session IDs (at most MAX_BATCH_SESSIONS)"
+// @Param share_token query []string false "Share token for each session, in the same order; repeatable" collectionFormat(multi)
+// @Param X-Share-Token header string false "Share token for each session, in the same order; repeatable instead of share_token"
+// @Param limit query int false "Number of items to return per session"
+// @Param offset query int false "Number of items to skip per session"
+// @Param action query []string false "Only return these actions; comma-separated or repeatable" collectionFormat(multi)
+// @Param from query string false "Only return entries at or after this RFC3339 timestamp"
+// @Param to query string false "Only return entries before this RFC3339 timestamp; must be after from"
+// @Param tz query string false "IANA time zone (e.g. Europe/Berlin) for from/to values given without an offset"
+// @Success 200 {object} domain.MultiSessionHistoryResponse
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /history/batch [get]
+func (h *AuditHandler) GetHistoryBatch(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	if !singleValuedParams(c, "sessions", "limit", "offset", "from", "to", "tz") {
+		return
+	}
+
+	query, ok := bindHistoryQuery(c)
+	if !ok {
+		return
+	}
+	pagination := domain.PaginationParams{
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}
+	filter := domain.HistoryFilter{
+		Actions: domain.ParseActions(query.Actions),
+		From:    query.From,
+		To:      query.To,
+	}
+
+	grants := middleware.GetSessionGrants(c)
+	response := domain.MultiSessionHistoryResponse{Sessions: make(map[string]domain.SessionHistory, len(grants))}
+	for _, grant := range grants {
+		if grant.Err != nil {
+			response.Sessions[grant.SessionID] = domain.SessionHistory{Items: []domain.AuditEntry{}, Error: domain.ToAPIError(grant.Err)}
 			continue
 		}
-		if !((char >= '0' && char <= '9') || (char >= 'a' && char <= 'f') || (char >= 'A' && char <= 'F')) {
-			return false
+
+		history, err := h.service.GetAuditLogs(c.Request.Context(), grant.SessionID, "", true, pagination, filter)
+		if err != nil {
+			h.logger.Debug("multi-session history read failed for session",
+				zap.String("request_id", requestID),
+				zap.String("session_id", grant.SessionID),
+				zap.Error(err),
+			)
+			response.Sessions[grant.SessionID] = domain.SessionHistory{Items: []domain.AuditEntry{}, Error: domain.ToAPIError(err)}
+			continue
 		}
+		response.Sessions[grant.SessionID] = domain.SessionHistory{TotalCount: history.TotalCount, Items: history.Items}
+		middleware.SetSessionRowsServed(c, grant.SessionID, len(history.Items))
+	}
+
+	h.logger.Debug("multi-session history resolved",
+		zap.String("request_id", requestID),
+		zap.Int("sessions", len(grants)),
+	)
+
+	h.writeHistory(c, response)
+}
+
+// ListSessions handles GET /sessions
+// @Summary List a user's sessions by recent activity
+// @Description Lists the user's sessions with their latest audit timestamp, most recently active first. userId=me selects the caller; other users are only visible with the admin token.
+// @Tags Audit
+// @Produce json
+// @Param userId query string true "User ID, or me for the authenticated user"
+// @Security BearerAuth
+// @Success 200 {object} domain.SessionListResponse
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /sessions [get]
+func (h *AuditHandler) ListSessions(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	if !singleValuedParams(c, "userId") {
+		return
+	}
+
+	isAdmin := middleware.GetAuthTokenType(c) == middleware.TokenTypeAdmin
+	authUserID := middleware.GetAuthUserID(c)
+
+	userID := c.Query("userId")
+	switch {
+	case userID == "":
+		c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", "userId is required", http.StatusBadRequest))
+		return
+	case userID == "me" && isAdmin:
+		c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", "userId=me requires a user token", http.StatusBadRequest))
+		return
+	case userID == "me":
+		userID = authUserID
+	case !isAdmin && userID != authUserID:
+		h.logger.Warn("cross-user session listing denied",
+			zap.String("request_id", requestID),
+			zap.String("user_id", authUserID),
+			zap.String("requested_user_id", userID),
+		)
+		c.JSON(http.StatusForbidden, domain.APIErrForbidden)
+		return
+	}
+
+	h.logger.Debug("processing session list request",
+		zap.String("request_id", requestID),
+		zap.String("user_id", userID),
+		zap.Bool("admin", isAdmin),
+	)
+
+	sessions, err := h.service.ListSessions(c.Request.Context(), userID)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteAPIError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.SessionListResponse{Items: sessions})
+}
+
+// ListShares handles GET /sessions/{sessionId}/shares
+// @Summary List who can access a session
+// @Description Lists the session's active share tokens, masked to their last four characters, with their expiries. Only the session owner may list them.
+// @Tags Audit
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Security BearerAuth
+// @Success 200 {object} domain.ShareListResponse
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /sessions/{sessionId}/shares [get]
+func (h *AuditHandler) ListShares(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	sessionID, ok := sessionIDParam(c)
+	if !ok {
+		return
+	}
+
+	// Reviewers may not see who else the session is shared with
+	if middleware.GetAuthTokenType(c) == middleware.TokenTypeShare {
+		c.JSON(http.StatusForbidden, domain.APIErrForbidden)
+		return
+	}
+
+	userID := middleware.GetAuthUserID(c)
+
+	h.logger.Debug("processing share list request",
+		zap.String("request_id", requestID),
+		zap.String("session_id", sessionID),
+		zap.String("user_id", userID),
+	)
+
+	shares, err := h.service.ListShares(c.Request.Context(), sessionID, userID)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteAPIError(c, apiErr)
+		return
 	}
 
+	c.JSON(http.StatusOK, domain.ShareListResponse{Items: shares})
+}
+
+// CreateEntry handles POST /sessions/{sessionId}/history
+// @Summary Record an audit entry for a session
+// @Description Records a new audit log entry. Supply an Idempotency-Key header to make retries safe. Admins (ADMIN_USER_IDS) may set actorUserId to record the entry for another user.
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param Idempotency-Key header string false "Key identifying this write for safe retries"
+// @Param entry body domain.CreateAuditEntryRequest true "Audit entry"
+// @Security BearerAuth
+// @Success 201 {object} domain.AuditEntry
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Failure 413 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /sessions/{sessionId}/history [post]
+func (h *AuditHandler) CreateEntry(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	sessionID, ok := sessionIDParam(c)
+	if !ok {
+		return
+	}
+
+	// Share tokens grant read-only access
+	if middleware.GetAuthTokenType(c) == middleware.TokenTypeShare {
+		c.JSON(http.StatusForbidden, domain.APIErrForbidden)
+		return
+	}
+
+	idempotencyKey := c.GetHeader("Idempotency-Key")
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", "Idempotency-Key header is too long", http.StatusBadRequest))
+		return
+	}
+
+	var req domain.CreateAuditEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBodyError(c, err)
+		return
+	}
+	req.IPAddress = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	userID := middleware.GetAuthUserID(c)
+
+	h.logger.Debug("processing audit entry creation",
+		zap.String("request_id", requestID),
+		zap.String("session_id", sessionID),
+		zap.String("user_id", userID),
+		zap.String("action", req.Action),
+		zap.Bool("idempotent", idempotencyKey != ""),
+	)
+
+	entry, replayed, err := h.service.CreateAuditEntry(c.Request.Context(), sessionID, userID, idempotencyKey, req)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteAPIError(c, apiErr)
+		return
+	}
+
+	if replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// CreateEntries handles POST /sessions/{sessionId}/history/batch
+// @Summary Record multiple audit entries for a session
+// @Description Records a batch of audit log entries in a single insert. The whole batch is rejected if any entry is invalid.
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param entries body []domain.CreateAuditEntryRequest true "Audit entries"
+// @Security BearerAuth
+// @Success 201 {object} domain.BatchCreateResponse
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 413 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /sessions/{sessionId}/history/batch [post]
+func (h *AuditHandler) CreateEntries(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	sessionID, ok := sessionIDParam(c)
+	if !ok {
+		return
+	}
+
+	// Share tokens grant read-only access
+	if middleware.GetAuthTokenType(c) == middleware.TokenTypeShare {
+		c.JSON(http.StatusForbidden, domain.APIErrForbidden)
+		return
+	}
+
+	// Decode without binding validation so invalid actions are reported
+	// per index by the service rather than failing the whole body
+	var reqs []domain.CreateAuditEntryRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&reqs); err != nil {
+		writeBodyError(c, err)
+		return
+	}
+	for i := range reqs {
+		reqs[i].IPAddress = c.ClientIP()
+		reqs[i].UserAgent = c.Request.UserAgent()
+	}
+
+	userID := middleware.GetAuthUserID(c)
+
+	h.logger.Debug("processing audit batch creation",
+		zap.String("request_id", requestID),
+		zap.String("session_id", sessionID),
+		zap.String("user_id", userID),
+		zap.Int("count", len(reqs)),
+	)
+
+	entries, err := h.service.CreateAuditEntries(c.Request.Context(), sessionID, userID, reqs)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteAPIError(c, apiErr)
+		return
+	}
+
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+
+	c.JSON(http.StatusCreated, domain.BatchCreateResponse{IDs: ids})
+}
+
+// ImportEntries handles POST /sessions/{sessionId}/history/import
+// @Summary Backfill audit history from ndjson
+// @Description Admin only. Streams one audit entry per line (userId, action, timestamp and optional details, ipAddress, userAgent), inserting valid lines in batches. Invalid lines are skipped and listed in the summary.
+// @Tags Admin
+// @Accept x-ndjson
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Security BearerAuth
+// @Success 200 {object} domain.ImportSummary
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 413 {object} domain.APIError
+// @Failure 415 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /sessions/{sessionId}/history/import [post]
+func (h *AuditHandler) ImportEntries(c *gin.Context) {
+	sessionID, ok := sessionIDParam(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.service.ImportAuditEntries(c.Request.Context(), sessionID, c.Request.Body)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteAPIError(c, apiErr)
+		return
+	}
+
+	h.logger.Info("audit history import finished",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.String("session_id", sessionID),
+		zap.Int("inserted", summary.Inserted),
+		zap.Int("failed", summary.Failed),
+	)
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// maxIdempotencyKeyLength bounds the size of client supplied idempotency keys
+const maxIdempotencyKeyLength = 255
+
+// writeBodyError responds to a request body that could not be decoded,
+// distinguishing bodies cut off by the BodyLimit middleware
+func writeBodyError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, domain.APIErrPayloadTooLarge)
+		return
+	}
+	c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", "Invalid request body", http.StatusBadRequest))
+}
+
+// sessionIDParam extracts and validates the session ID path parameter,
+// writing a 400 response when it is missing or malformed
+func sessionIDParam(c *gin.Context) (string, bool) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", "Session ID is required", http.StatusBadRequest))
+		return "", false
+	}
+
+	if domain.IsReservedPathSegment(sessionID) {
+		c.JSON(http.StatusBadRequest, domain.NewReservedSegmentError(sessionID))
+		return "", false
+	}
+
+	// Validate UUID format
+	if !domain.IsValidUUID(sessionID) {
+		c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", "Invalid session ID format", http.StatusBadRequest))
+		return "", false
+	}
+
+	return sessionID, true
+}
+
+// historySingleParams are the history query parameters that may appear at most once
+var historySingleParams = []string{"limit", "offset", "fields", "sinceId", "slide", "from", "to", "tz", "count", "includeDeleted", "filter", "wait", "details"}
+
+// singleValuedParams rejects requests that repeat any of the named query parameters,
+// writing a 400 response, since silently picking one value would be ambiguous
+func singleValuedParams(c *gin.Context, names ...string) bool {
+	query := c.Request.URL.Query()
+	for _, name := range names {
+		if len(query[name]) > 1 {
+			c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request",
+				fmt.Sprintf("Query parameter %q must not be repeated", name), http.StatusBadRequest))
+			return false
+		}
+	}
 	return true
 }
+
+// buildLinkHeader builds an RFC 5988 Link header with next/prev page URLs,
+// preserving any other query parameters from the original request
+func buildLinkHeader(requestURL *url.URL, pagination domain.PaginationParams, totalCount int) string {
+	var links []string
+
+	if pagination.Offset+pagination.Limit < totalCount {
+		links = append(links, formatLink(requestURL, pagination.Limit, pagination.Offset+pagination.Limit, "next"))
+	}
+
+	if pagination.Offset > 0 {
+		prevOffset := pagination.Offset - pagination.Limit
+		if prevOffset < 0 {
+			prevOffset = 0
+		}
+		links = append(links, formatLink(requestURL, pagination.Limit, prevOffset, "prev"))
+	}
+
+	return strings.Join(links, ", ")
+}
+
+// formatLink formats a single Link header entry for the given page
+func formatLink(requestURL *url.URL, limit, offset int, rel string) string {
+	query := requestURL.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+
+	link := url.URL{Path: requestURL.Path, RawQuery: query.Encode()}
+	return fmt.Sprintf("<%s>; rel=\"%s\"", link.String(), rel)
+}
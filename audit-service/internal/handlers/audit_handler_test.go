@@ -3,8 +3,12 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,29 +18,95 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
+// testMaxResponseBytes is the MAX_RESPONSE_BYTES default
+const testMaxResponseBytes = 10 << 20
+
 // MockAuditService implements the AuditService interface for testing
 type MockAuditService struct {
 	mock.Mock
 }
 
-func (m *MockAuditService) GetAuditLogs(ctx context.Context, sessionID, userID string, isShareToken bool, pagination domain.PaginationParams) (*domain.AuditResponse, error) {
-	args := m.Called(ctx, sessionID, userID, isShareToken, pagination)
+func (m *MockAuditService) GetAuditLogs(ctx context.Context, sessionID, userID string, isShareToken bool, pagination domain.PaginationParams, filter domain.HistoryFilter) (*domain.AuditResponse, error) {
+	args := m.Called(ctx, sessionID, userID, isShareToken, pagination, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.AuditResponse), args.Error(1)
 }
 
+func (m *MockAuditService) CreateAuditEntry(ctx context.Context, sessionID, userID, idempotencyKey string, req domain.CreateAuditEntryRequest) (*domain.AuditEntry, bool, error) {
+	args := m.Called(ctx, sessionID, userID, idempotencyKey, req)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*domain.AuditEntry), args.Bool(1), args.Error(2)
+}
+
+func (m *MockAuditService) GetSummary(ctx context.Context, sessionID, userID string, isShareToken bool, window string) (*domain.AuditSummary, error) {
+	args := m.Called(ctx, sessionID, userID, isShareToken, window)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AuditSummary), args.Error(1)
+}
+
+func (m *MockAuditService) GetAuditEntry(ctx context.Context, sessionID, userID string, isShareToken bool, entryID string) (*domain.AuditEntry, error) {
+	args := m.Called(ctx, sessionID, userID, isShareToken, entryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AuditEntry), args.Error(1)
+}
+
+func (m *MockAuditService) ListSessions(ctx context.Context, userID string) ([]domain.SessionSummary, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.SessionSummary), args.Error(1)
+}
+
+func (m *MockAuditService) ListShares(ctx context.Context, sessionID, userID string) ([]domain.ShareGrant, error) {
+	args := m.Called(ctx, sessionID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ShareGrant), args.Error(1)
+}
+
+func (m *MockAuditService) CreateAuditEntries(ctx context.Context, sessionID, userID string, reqs []domain.CreateAuditEntryRequest) ([]domain.AuditEntry, error) {
+	args := m.Called(ctx, sessionID, userID, reqs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.AuditEntry), args.Error(1)
+}
+
+func (m *MockAuditService) ImportAuditEntries(ctx context.Context, sessionID string, r io.Reader) (*domain.ImportSummary, error) {
+	args := m.Called(ctx, sessionID, r)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ImportSummary), args.Error(1)
+}
+
+func (m *MockAuditService) WaitForEntries(ctx context.Context, sessionID, userID string, isShareToken bool, filter domain.HistoryFilter, wait time.Duration) (bool, error) {
+	args := m.Called(ctx, sessionID, userID, isShareToken, filter, wait)
+	return args.Bool(0), args.Error(1)
+}
+
 func TestAuditHandler_GetHistory_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	// Setup mock service
 	mockService := new(MockAuditService)
 	logger := zap.NewNop()
-	handler := NewAuditHandler(mockService, logger)
+	handler := NewAuditHandler(mockService, testMaxResponseBytes, logger)
 
 	// Use valid UUID for session ID
 	sessionID := "550e8400-e29b-41d4-a716-446655440000"
@@ -68,7 +138,8 @@ func TestAuditHandler_GetHistory_Success(t *testing.T) {
 		sessionID,     // sessionID
 		"user-456",    // userID
 		false,         // isShareToken
-		domain.PaginationParams{Limit: 50, Offset: 0},
+		domain.PaginationParams{Limit: 0, Offset: 0},
+		domain.HistoryFilter{},
 	).Return(expectedResponse, nil)
 
 	// Setup request
@@ -95,12 +166,40 @@ func TestAuditHandler_GetHistory_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestAuditHandler_GetHistory_RetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	mockService := new(MockAuditService)
+	handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+	rateLimited := &domain.RetryAfterError{
+		RetryAfter: 30 * time.Second,
+		Err:        fmt.Errorf("%w: rate limited by supabase", domain.ErrServiceUnavailable),
+	}
+	mockService.On("GetAuditLogs", mock.Anything, sessionID, "user-456", false, domain.PaginationParams{}, domain.HistoryFilter{}).
+		Return(nil, fmt.Errorf("failed to fetch audit logs: %w", rateLimited))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history", nil)
+	c.Set(middleware.AuthUserIDKey, "user-456")
+	c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+	c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+	handler.GetHistory(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), `"error":"service_unavailable"`)
+}
+
 func TestAuditHandler_GetHistory_InvalidSessionID(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockService := new(MockAuditService)
 	logger := zap.NewNop()
-	handler := NewAuditHandler(mockService, logger)
+	handler := NewAuditHandler(mockService, testMaxResponseBytes, logger)
 
 	// Setup request with invalid session ID
 	w := httptest.NewRecorder()
@@ -124,12 +223,30 @@ func TestAuditHandler_GetHistory_InvalidSessionID(t *testing.T) {
 	mockService.AssertNotCalled(t, "GetAuditLogs")
 }
 
+func TestAuditHandler_GetHistory_ReservedSessionID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockAuditService)
+	handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/sessions/summary/history", nil)
+	c.Params = []gin.Param{{Key: "sessionId", Value: "summary"}}
+
+	handler.GetHistory(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"bad_request","message":"\"summary\" is a reserved path segment, not a session ID","details":{"field":"sessionId"}}`, w.Body.String())
+	mockService.AssertNotCalled(t, "GetAuditLogs")
+}
+
 func TestAuditHandler_GetHistory_ServiceError(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockService := new(MockAuditService)
 	logger := zap.NewNop()
-	handler := NewAuditHandler(mockService, logger)
+	handler := NewAuditHandler(mockService, testMaxResponseBytes, logger)
 
 	// Setup mock expectation with error
 	mockService.On("GetAuditLogs",
@@ -137,7 +254,8 @@ func TestAuditHandler_GetHistory_ServiceError(t *testing.T) {
 		"550e8400-e29b-41d4-a716-446655440000",
 		"user-456",
 		false,
-		domain.PaginationParams{Limit: 50, Offset: 0},
+		domain.PaginationParams{Limit: 0, Offset: 0},
+		domain.HistoryFilter{},
 	).Return(nil, domain.ErrNotFound)
 
 	// Setup request
@@ -168,7 +286,7 @@ func TestAuditHandler_GetHistory_WithPagination(t *testing.T) {
 
 	mockService := new(MockAuditService)
 	logger := zap.NewNop()
-	handler := NewAuditHandler(mockService, logger)
+	handler := NewAuditHandler(mockService, testMaxResponseBytes, logger)
 
 	expectedResponse := &domain.AuditResponse{
 		TotalCount: 100,
@@ -182,6 +300,7 @@ func TestAuditHandler_GetHistory_WithPagination(t *testing.T) {
 		"user-456",
 		false,
 		domain.PaginationParams{Limit: 25, Offset: 50},
+		domain.HistoryFilter{},
 	).Return(expectedResponse, nil)
 
 	// Setup request with pagination
@@ -202,48 +321,1694 @@ func TestAuditHandler_GetHistory_WithPagination(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
-func TestIsValidUUID(t *testing.T) {
+func TestAuditHandler_GetHistory_Fields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const sessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+	mockService := new(MockAuditService)
+	handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+	expectedResponse := &domain.AuditResponse{
+		TotalCount: 1,
+		Items: []domain.AuditEntry{
+			{ID: "entry-1", Action: string(domain.ActionEdit)},
+		},
+	}
+
+	mockService.On("GetAuditLogs",
+		mock.Anything,
+		sessionID,
+		"user-456",
+		false,
+		domain.PaginationParams{Limit: 0, Offset: 0},
+		domain.HistoryFilter{Fields: []string{"id", "action"}},
+	).Return(expectedResponse, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history?fields=id,action", nil)
+	c.Set(middleware.AuthUserIDKey, "user-456")
+	c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+	c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+	handler.GetHistory(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"totalCount":1,"items":[{"id":"entry-1","action":"edit"}]}`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestAuditHandler_GetHistory_ResponseTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const sessionID = "550e8400-e29b-41d4-a716-446655440000"
+	oversized, _ := json.Marshal(map[string]interface{}{"text": strings.Repeat("x", 2048)})
+	entries := []domain.AuditEntry{{ID: "entry-1", Action: "edit", Details: oversized}}
+
+	tests := []struct {
+		name           string
+		query          string
+		filter         domain.HistoryFilter
+		expectedStatus int
+	}{
+		{
+			name:           "error_full_entries_over_limit",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "success_projection_under_limit",
+			query:          "?fields=id,action",
+			filter:         domain.HistoryFilter{Fields: []string{"id", "action"}},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, 1024, zap.NewNop())
+
+			mockService.On("GetAuditLogs", mock.Anything, sessionID, "user-456", false, domain.PaginationParams{}, tt.filter).
+				Return(&domain.AuditResponse{TotalCount: 1, Items: entries}, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history"+tt.query, nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetHistory(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusBadRequest {
+				var response domain.APIError
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "response_too_large", response.Code)
+			} else {
+				assert.JSONEq(t, `{"totalCount":1,"items":[{"id":"entry-1","action":"edit"}]}`, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetHistory_WithoutDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const sessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+	t.Run("details_false_omits_details", func(t *testing.T) {
+		mockService := new(MockAuditService)
+		handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+		// details is left out of the selection, so Supabase never returns it
+		mockService.On("GetAuditLogs",
+			mock.Anything, sessionID, "user-456", false,
+			domain.PaginationParams{},
+			domain.HistoryFilter{Fields: []string{"id", "sessionId", "userId", "action", "timestamp", "ipAddress", "userAgent", "deletedAt", "performedBy"}},
+		).Return(&domain.AuditResponse{
+			TotalCount: 1,
+			Items:      []domain.AuditEntry{{ID: "entry-1", SessionID: sessionID, UserID: "user-456", Action: "edit"}},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history?details=false", nil)
+		c.Set(middleware.AuthUserIDKey, "user-456")
+		c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+		c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+		handler.GetHistory(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), `"details"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("details_false_narrows_fields", func(t *testing.T) {
+		mockService := new(MockAuditService)
+		handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+		mockService.On("GetAuditLogs",
+			mock.Anything, sessionID, "user-456", false,
+			domain.PaginationParams{},
+			domain.HistoryFilter{Fields: []string{"id"}},
+		).Return(&domain.AuditResponse{TotalCount: 1, Items: []domain.AuditEntry{{ID: "entry-1"}}}, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history?fields=id,details&details=false", nil)
+		c.Set(middleware.AuthUserIDKey, "user-456")
+		c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+		c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+		handler.GetHistory(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"totalCount":1,"items":[{"id":"entry-1"}]}`, w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name            string
+		query           string
+		expectedMessage string
+	}{
+		{name: "nothing_left", query: "fields=details&details=false", expectedMessage: "Invalid details parameter: excludes every selected field"},
+		{name: "not_a_bool", query: "details=maybe", expectedMessage: "Invalid details parameter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history?"+tt.query, nil)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetHistory(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response domain.APIError
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedMessage, response.Message)
+			mockService.AssertNotCalled(t, "GetAuditLogs")
+		})
+	}
+}
+
+func TestAuditHandler_GetHistory_IncludeSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const sessionID = "550e8400-e29b-41d4-a716-446655440000"
+	metadata := &domain.SessionMetadata{Title: "Q3 deck", OwnerID: "user-456"}
+
 	tests := []struct {
-		name  string
-		uuid  string
-		valid bool
+		name         string
+		query        string
+		filter       domain.HistoryFilter
+		expectedBody string
 	}{
 		{
-			name:  "valid UUID",
-			uuid:  "550e8400-e29b-41d4-a716-446655440000",
-			valid: true,
+			name:         "not_requested",
+			query:        "fields=id",
+			filter:       domain.HistoryFilter{Fields: []string{"id"}},
+			expectedBody: `{"totalCount":1,"items":[{"id":"entry-1"}]}`,
 		},
 		{
-			name:  "valid UUID with uppercase",
-			uuid:  "550E8400-E29B-41D4-A716-446655440000",
-			valid: true,
+			name:         "requested",
+			query:        "fields=id&include=session",
+			filter:       domain.HistoryFilter{Fields: []string{"id"}, IncludeSession: true},
+			expectedBody: `{"totalCount":1,"items":[{"id":"entry-1"}],"session":{"title":"Q3 deck","ownerId":"user-456"}}`,
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+			response := &domain.AuditResponse{TotalCount: 1, Items: []domain.AuditEntry{{ID: "entry-1"}}}
+			if tt.filter.IncludeSession {
+				response.Session = metadata
+			}
+			mockService.On("GetAuditLogs", mock.Anything, sessionID, "user-456", false, domain.PaginationParams{}, tt.filter).
+				Return(response, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history?"+tt.query, nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetHistory(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetHistory_CountOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const sessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+	mockService := new(MockAuditService)
+	handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+	filter := domain.HistoryFilter{Actions: []string{"edit"}, CountOnly: true}
+	mockService.On("GetAuditLogs", mock.Anything, sessionID, "user-456", false, domain.PaginationParams{}, filter).
+		Return(&domain.AuditResponse{
+			TotalCount: 42,
+			Items:      []domain.AuditEntry{},
+			Pagination: domain.PaginationParams{Limit: 50},
+			CountMode:  "exact",
+		}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history?count=true&action=edit", nil)
+	c.Set(middleware.AuthUserIDKey, "user-456")
+	c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+	c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+	handler.GetHistory(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"totalCount":42}`, w.Body.String())
+	assert.Equal(t, "exact", w.Header().Get("X-Count-Mode"))
+	assert.Empty(t, w.Header().Get("Link"))
+	mockService.AssertExpectations(t)
+}
+
+func TestAuditHandler_GetHistory_IncludeDeleted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const sessionID = "550e8400-e29b-41d4-a716-446655440000"
+	deletedAt := time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)
+
+	mockService := new(MockAuditService)
+	handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+	filter := domain.HistoryFilter{Fields: []string{"id", "deletedAt"}, IncludeDeleted: true}
+	mockService.On("GetAuditLogs", mock.Anything, sessionID, "user-456", false, domain.PaginationParams{}, filter).
+		Return(&domain.AuditResponse{
+			TotalCount: 2,
+			Items:      []domain.AuditEntry{{ID: "entry-1", DeletedAt: &deletedAt}, {ID: "entry-2"}},
+		}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history?includeDeleted=true&fields=id,deletedAt", nil)
+	c.Set(middleware.AuthUserIDKey, "user-456")
+	c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+	c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+	handler.GetHistory(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"totalCount":2,"items":[{"id":"entry-1","deletedAt":"2024-01-15T11:00:00Z"},{"id":"entry-2"}]}`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestAuditHandler_GetHistory_CompositeFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const sessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+	tests := []struct {
+		name           string
+		rawFilter      string
+		expectedStatus int
+	}{
+		{name: "success_or_with_nested_and", rawFilter: "or(action.eq.edit,and(action.eq.merge,userId.eq.user-1))", expectedStatus: http.StatusOK},
+		{name: "error_disallowed_column", rawFilter: "or(action.eq.edit,session_id.neq.x)", expectedStatus: http.StatusBadRequest},
+		{name: "error_injected_group", rawFilter: "action.eq.edit),or(session_id.neq.x", expectedStatus: http.StatusBadRequest},
+		{name: "error_injected_value", rawFilter: "action.eq.edit,session_id.neq.x", expectedStatus: http.StatusBadRequest},
+		{name: "error_unknown_operator", rawFilter: "action.like.*", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+			if tt.expectedStatus == http.StatusOK {
+				where, err := domain.ParseFilterExpr(tt.rawFilter)
+				require.NoError(t, err)
+				mockService.On("GetAuditLogs", mock.Anything, sessionID, "user-456", false, domain.PaginationParams{}, domain.HistoryFilter{Where: where}).
+					Return(&domain.AuditResponse{Items: []domain.AuditEntry{}}, nil)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history?filter="+url.QueryEscape(tt.rawFilter), nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetHistory(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusBadRequest {
+				var response domain.APIError
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, map[string]interface{}{"field": "filter"}, response.Details)
+				mockService.AssertNotCalled(t, "GetAuditLogs")
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetHistory_LongPoll(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const sessionID = "550e8400-e29b-41d4-a716-446655440000"
+	const sinceID = "550e8400-e29b-41d4-a716-446655440009"
+	filter := domain.HistoryFilter{SinceID: sinceID}
+	entries := []domain.AuditEntry{{ID: "entry-new", Action: "edit"}}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMocks     func(*MockAuditService)
+		expectedStatus int
+		expectedItems  int
+	}{
 		{
-			name:  "invalid length",
-			uuid:  "550e8400-e29b-41d4-a716",
-			valid: false,
+			name:  "new_entries_returned",
+			query: "?wait=30s&sinceId=" + sinceID,
+			setupMocks: func(m *MockAuditService) {
+				m.On("WaitForEntries", mock.Anything, sessionID, "user-456", false, filter, 30*time.Second).Return(true, nil)
+				m.On("GetAuditLogs", mock.Anything, sessionID, "user-456", false, domain.PaginationParams{}, filter).
+					Return(&domain.AuditResponse{TotalCount: 1, Items: entries}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedItems:  1,
 		},
 		{
-			name:  "missing hyphens",
-			uuid:  "550e8400e29b41d4a716446655440000",
-			valid: false,
+			name:  "timeout_returns_empty_page",
+			query: "?wait=30s&sinceId=" + sinceID,
+			setupMocks: func(m *MockAuditService) {
+				m.On("WaitForEntries", mock.Anything, sessionID, "user-456", false, filter, 30*time.Second).Return(false, nil)
+			},
+			expectedStatus: http.StatusOK,
 		},
 		{
-			name:  "invalid characters",
-			uuid:  "550e8400-e29b-41d4-a716-44665544000g",
-			valid: false,
+			name:           "error_wait_without_since_id",
+			query:          "?wait=30s",
+			setupMocks:     func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
 		},
 		{
-			name:  "empty string",
-			uuid:  "",
-			valid: false,
+			name:           "error_invalid_wait",
+			query:          "?wait=soon&sinceId=" + sinceID,
+			setupMocks:     func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := isValidUUID(tt.uuid)
-			assert.Equal(t, tt.valid, result)
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+			tt.setupMocks(mockService)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history"+tt.query, nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetHistory(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response domain.AuditResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Len(t, response.Items, tt.expectedItems)
+				assert.NotNil(t, response.Items)
+			} else {
+				var response domain.APIError
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, map[string]interface{}{"field": "wait"}, response.Details)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetHistory_InvalidFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const sessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+	mockService := new(MockAuditService)
+	handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history?fields=id,secret", nil)
+	c.Set(middleware.AuthUserIDKey, "user-456")
+	c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+	c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+	handler.GetHistory(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response domain.APIError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "bad_request", response.Code)
+	mockService.AssertNotCalled(t, "GetAuditLogs")
+}
+
+func TestAuditHandler_GetHistory_DetailsFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const sessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedFilter domain.HistoryFilter
+		expectedStatus int
+	}{
+		{
+			name:           "slide shorthand",
+			query:          "slide=3",
+			expectedFilter: domain.HistoryFilter{Details: map[string]string{"slide": "3"}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "generic details filter",
+			query:          "detailsFilter=slide:7",
+			expectedFilter: domain.HistoryFilter{Details: map[string]string{"slide": "7"}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unsafe key rejected",
+			query:          "detailsFilter=" + url.QueryEscape("user_id:abc"),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid slide rejected",
+			query:          "slide=" + url.QueryEscape("3)"),
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+			if tt.expectedStatus == http.StatusOK {
+				mockService.On("GetAuditLogs", mock.Anything, sessionID, "user-456", false,
+					domain.PaginationParams{Limit: 0, Offset: 0}, tt.expectedFilter).
+					Return(&domain.AuditResponse{Items: []domain.AuditEntry{}}, nil)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history?"+tt.query, nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetHistory(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				mockService.AssertNotCalled(t, "GetAuditLogs")
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetHistory_ServedStale(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const sessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+	tests := []struct {
+		name           string
+		stale          bool
+		expectedHeader string
+	}{
+		{name: "fresh", stale: false, expectedHeader: ""},
+		{name: "stale", stale: true, expectedHeader: "true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+			mockService.On("GetAuditLogs", mock.Anything, sessionID, "user-456", false,
+				domain.PaginationParams{Limit: 0, Offset: 0}, domain.HistoryFilter{}).
+				Return(&domain.AuditResponse{Items: []domain.AuditEntry{}, Stale: tt.stale, CountMode: "estimated"}, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history", nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetHistory(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expectedHeader, w.Header().Get("X-Served-Stale"))
+			assert.Equal(t, "estimated", w.Header().Get("X-Count-Mode"))
+		})
+	}
+}
+
+func TestAuditHandler_GetHistory_LogsResolvedPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const sessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+	core, logs := observer.New(zap.DebugLevel)
+	mockService := new(MockAuditService)
+	handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.New(core))
+
+	// The service clamps the requested limit of 200 down to the maximum
+	mockService.On("GetAuditLogs", mock.Anything, sessionID, "user-456", false,
+		domain.PaginationParams{Limit: 200, Offset: 0}, domain.HistoryFilter{}).
+		Return(&domain.AuditResponse{
+			Items:      []domain.AuditEntry{},
+			Pagination: domain.PaginationParams{Limit: 100, Offset: 0},
+		}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history?limit=200", nil)
+	c.Set(middleware.AuthUserIDKey, "user-456")
+	c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+	c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+	handler.GetHistory(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	entries := logs.FilterMessage("audit history resolved").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, int64(100), entries[0].ContextMap()["limit"])
+	assert.Equal(t, int64(0), entries[0].ContextMap()["offset"])
+}
+
+func TestAuditHandler_GetHistory_RepeatedParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const sessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedFilter domain.HistoryFilter
+		expectedStatus int
+	}{
+		{
+			name:           "repeated_limit",
+			query:          "limit=10&limit=20",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "repeated_offset",
+			query:          "offset=0&offset=50",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "repeated_slide",
+			query:          "slide=1&slide=2",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "multi_valued_details_filter",
+			query:          "detailsFilter=slide:1&detailsFilter=slide:2",
+			expectedFilter: domain.HistoryFilter{Details: map[string]string{"slide": "2"}},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+			if tt.expectedStatus == http.StatusOK {
+				mockService.On("GetAuditLogs", mock.Anything, sessionID, "user-456", false,
+					domain.PaginationParams{Limit: 0, Offset: 0}, tt.expectedFilter).
+					Return(&domain.AuditResponse{Items: []domain.AuditEntry{}}, nil)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history?"+tt.query, nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetHistory(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				var response domain.APIError
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "bad_request", response.Code)
+				mockService.AssertNotCalled(t, "GetAuditLogs")
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetHistory_QueryValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const sessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+	tests := []struct {
+		name            string
+		query           string
+		expectedField   string
+		expectedMessage string
+	}{
+		{name: "non_numeric_limit", query: "limit=abc", expectedField: "limit", expectedMessage: "Invalid limit parameter: must be a non-negative integer"},
+		{name: "negative_limit", query: "limit=-1", expectedField: "limit", expectedMessage: "Invalid limit parameter: must be a non-negative integer"},
+		{name: "fractional_limit", query: "limit=10.5", expectedField: "limit", expectedMessage: "Invalid limit parameter: must be a non-negative integer"},
+		{name: "hex_limit", query: "limit=0x10", expectedField: "limit", expectedMessage: "Invalid limit parameter: must be a non-negative integer"},
+		{name: "signed_limit", query: "limit=%2B10", expectedField: "limit", expectedMessage: "Invalid limit parameter: must be a non-negative integer"},
+		{name: "exponent_limit", query: "limit=1e2", expectedField: "limit", expectedMessage: "Invalid limit parameter: must be a non-negative integer"},
+		{name: "overflowing_limit", query: "limit=99999999999999999999", expectedField: "limit", expectedMessage: "Invalid limit parameter: must be a non-negative integer"},
+		{name: "non_numeric_offset", query: "limit=10&offset=abc", expectedField: "offset", expectedMessage: "Invalid offset parameter: must be a non-negative integer"},
+		{name: "negative_offset", query: "offset=-5", expectedField: "offset", expectedMessage: "Invalid offset parameter: must be a non-negative integer"},
+		{name: "fractional_offset", query: "offset=1.0", expectedField: "offset", expectedMessage: "Invalid offset parameter: must be a non-negative integer"},
+		{name: "hex_offset", query: "offset=0x10", expectedField: "offset", expectedMessage: "Invalid offset parameter: must be a non-negative integer"},
+		{name: "signed_offset", query: "offset=%2B10", expectedField: "offset", expectedMessage: "Invalid offset parameter: must be a non-negative integer"},
+		{name: "invalid_since_id", query: "sinceId=not-a-uuid", expectedField: "sinceId", expectedMessage: "Invalid since ID format"},
+		{name: "braced_since_id", query: "sinceId=%7B550e8400-e29b-41d4-a716-446655440000%7D", expectedField: "sinceId", expectedMessage: "Invalid since ID format"},
+		{name: "invalid_from", query: "from=yesterday", expectedField: "from", expectedMessage: "Invalid from parameter"},
+		{name: "from_without_offset", query: "from=2024-01-15T10:00:00", expectedField: "from", expectedMessage: "Invalid from parameter"},
+		{name: "invalid_to", query: "to=2024-13-01T00:00:00Z", expectedField: "to", expectedMessage: "Invalid to parameter"},
+		{name: "to_before_from", query: "from=2024-01-15T00:00:00Z&to=2024-01-14T00:00:00Z", expectedField: "to", expectedMessage: "Invalid to parameter: must be after from"},
+		{name: "to_equal_to_from_across_offsets", query: "from=2024-01-15T00:00:00Z&to=2024-01-15T05:30:00%2B05:30", expectedField: "to", expectedMessage: "Invalid to parameter: must be after from"},
+		{name: "unknown_tz", query: "from=2024-01-15T10:00:00&tz=Mars/Olympus_Mons", expectedField: "tz", expectedMessage: "Invalid tz parameter: unknown time zone"},
+		{name: "local_tz", query: "from=2024-01-15T10:00:00&tz=Local", expectedField: "tz", expectedMessage: "Invalid tz parameter: unknown time zone"},
+		{name: "unknown_include", query: "include=owner", expectedField: "include", expectedMessage: "Invalid include parameter"},
+		{name: "invalid_from_with_tz", query: "from=yesterday&tz=Europe/Berlin", expectedField: "from", expectedMessage: "Invalid from parameter"},
+		{name: "invalid_count", query: "count=yes", expectedField: "count", expectedMessage: "Invalid count parameter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history?"+tt.query, nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetHistory(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response domain.APIError
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "bad_request", response.Code)
+			assert.Equal(t, tt.expectedMessage, response.Message)
+			assert.Equal(t, map[string]interface{}{"field": tt.expectedField}, response.Details)
+			mockService.AssertNotCalled(t, "GetAuditLogs")
+		})
+	}
+}
+
+func TestAuditHandler_GetHistory_TimeRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const sessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+	mockService := new(MockAuditService)
+	handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+	mockService.On("GetAuditLogs", mock.Anything, sessionID, "user-456", false, domain.PaginationParams{},
+		mock.MatchedBy(func(filter domain.HistoryFilter) bool {
+			return filter.From.Equal(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)) &&
+				filter.To.Equal(time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC))
+		})).
+		Return(&domain.AuditResponse{Items: []domain.AuditEntry{}}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history?from=2024-01-15T00:00:00Z&to=2024-01-15T17:30:00%2B05:30", nil)
+	c.Set(middleware.AuthUserIDKey, "user-456")
+	c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+	c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+	handler.GetHistory(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAuditHandler_GetHistory_TimeZones(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const sessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedFrom time.Time
+	}{
+		{name: "utc_designator", query: "from=2024-01-15T10:00:00Z", expectedFrom: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)},
+		{name: "numeric_offset", query: "from=2024-01-15T10:00:00%2B05:30", expectedFrom: time.Date(2024, 1, 15, 4, 30, 0, 0, time.UTC)},
+		{name: "named_zone", query: "from=2024-01-15T10:00:00&tz=Europe/Berlin", expectedFrom: time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)},
+		{name: "named_zone_in_summer_time", query: "from=2024-07-15T10:00:00&tz=Europe/Berlin", expectedFrom: time.Date(2024, 7, 15, 8, 0, 0, 0, time.UTC)},
+		{name: "offset_wins_over_tz", query: "from=2024-01-15T10:00:00Z&tz=America/New_York", expectedFrom: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+			mockService.On("GetAuditLogs", mock.Anything, sessionID, "user-456", false, domain.PaginationParams{},
+				mock.MatchedBy(func(filter domain.HistoryFilter) bool {
+					return filter.From.Equal(tt.expectedFrom) && filter.From.Location() == time.UTC
+				})).
+				Return(&domain.AuditResponse{Items: []domain.AuditEntry{}}, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history?"+tt.query, nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetHistory(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetHistory_LinkHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+
+	tests := []struct {
+		name         string
+		query        string
+		pagination   domain.PaginationParams
+		totalCount   int
+		expectedNext string
+		expectedPrev string
+	}{
+		{
+			name:         "first_page",
+			query:        "limit=10&offset=0",
+			pagination:   domain.PaginationParams{Limit: 10, Offset: 0},
+			totalCount:   25,
+			expectedNext: "10",
+			expectedPrev: "",
+		},
+		{
+			name:         "middle_page",
+			query:        "limit=10&offset=10",
+			pagination:   domain.PaginationParams{Limit: 10, Offset: 10},
+			totalCount:   25,
+			expectedNext: "20",
+			expectedPrev: "0",
+		},
+		{
+			name:         "last_page",
+			query:        "limit=10&offset=20",
+			pagination:   domain.PaginationParams{Limit: 10, Offset: 20},
+			totalCount:   25,
+			expectedNext: "",
+			expectedPrev: "10",
+		},
+		{
+			name:         "prev_clamped_to_zero",
+			query:        "limit=10&offset=5",
+			pagination:   domain.PaginationParams{Limit: 10, Offset: 5},
+			totalCount:   25,
+			expectedNext: "15",
+			expectedPrev: "0",
+		},
+		{
+			name:         "single_page",
+			query:        "limit=10&offset=0",
+			pagination:   domain.PaginationParams{Limit: 10, Offset: 0},
+			totalCount:   5,
+			expectedNext: "",
+			expectedPrev: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+			mockService.On("GetAuditLogs", mock.Anything, sessionID, "user-456", false, tt.pagination, domain.HistoryFilter{}).
+				Return(&domain.AuditResponse{TotalCount: tt.totalCount, Items: []domain.AuditEntry{}, Pagination: tt.pagination}, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history?"+tt.query+"&share_token=abc", nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetHistory(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			links := parseLinkHeader(t, w.Header().Get("Link"))
+			assertLinkOffset(t, links, "next", tt.expectedNext)
+			assertLinkOffset(t, links, "prev", tt.expectedPrev)
+
+			// Other query params are preserved in generated links
+			for _, link := range links {
+				assert.Equal(t, "abc", link.Query().Get("share_token"))
+				assert.Equal(t, "/api/v1/sessions/"+sessionID+"/history", link.Path)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetHistory_SessionIDHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const (
+		pathSessionID   = "550e8400-e29b-41d4-a716-446655440000"
+		headerSessionID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	)
+
+	tests := []struct {
+		name              string
+		pathParam         string
+		header            string
+		expectedStatus    int
+		expectedSessionID string
+	}{
+		{name: "header_only", header: headerSessionID, expectedStatus: http.StatusOK, expectedSessionID: headerSessionID},
+		{name: "path_takes_precedence", pathParam: pathSessionID, header: headerSessionID, expectedStatus: http.StatusOK, expectedSessionID: pathSessionID},
+		{name: "invalid_header", header: "not-a-uuid", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+			if tt.expectedSessionID != "" {
+				mockService.On("GetAuditLogs", mock.Anything, tt.expectedSessionID, "user-456", false, domain.PaginationParams{}, domain.HistoryFilter{}).
+					Return(&domain.AuditResponse{Items: []domain.AuditEntry{}}, nil)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/history", nil)
+			c.Request.Header.Set(middleware.SessionIDHeader, tt.header)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			if tt.pathParam != "" {
+				c.Params = []gin.Param{{Key: "sessionId", Value: tt.pathParam}}
+			}
+
+			handler.GetHistory(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+// parseLinkHeader parses an RFC 5988 Link header into a rel -> URL map
+func parseLinkHeader(t *testing.T, header string) map[string]*url.URL {
+	links := make(map[string]*url.URL)
+	if header == "" {
+		return links
+	}
+
+	for _, part := range strings.Split(header, ",") {
+		segments := strings.Split(strings.TrimSpace(part), ";")
+		require.Len(t, segments, 2)
+
+		target := strings.Trim(strings.TrimSpace(segments[0]), "<>")
+		rel := strings.TrimSpace(segments[1])
+		rel = strings.TrimSuffix(strings.TrimPrefix(rel, `rel="`), `"`)
+
+		parsed, err := url.Parse(target)
+		require.NoError(t, err)
+		links[rel] = parsed
+	}
+
+	return links
+}
+
+func assertLinkOffset(t *testing.T, links map[string]*url.URL, rel, expectedOffset string) {
+	link, ok := links[rel]
+	if expectedOffset == "" {
+		assert.False(t, ok, "did not expect rel=%s link", rel)
+		return
+	}
+	if assert.True(t, ok, "expected rel=%s link", rel) {
+		assert.Equal(t, expectedOffset, link.Query().Get("offset"))
+		assert.Equal(t, "10", link.Query().Get("limit"))
+	}
+}
+
+func TestAuditHandler_CreateEntry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	createdEntry := &domain.AuditEntry{
+		ID:        "entry-1",
+		SessionID: sessionID,
+		UserID:    "user-456",
+		Action:    "edit",
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		tokenType      string
+		idempotencyKey string
+		setupMocks     func(*MockAuditService)
+		expectedStatus int
+		expectedCode   string
+		expectedReplay bool
+	}{
+		{
+			name:      "success_created",
+			body:      `{"action":"edit","details":{"slide":1}}`,
+			tokenType: middleware.TokenTypeJWT,
+			setupMocks: func(m *MockAuditService) {
+				m.On("CreateAuditEntry", mock.Anything, sessionID, "user-456", "", mock.MatchedBy(func(req domain.CreateAuditEntryRequest) bool {
+					return req.Action == "edit" && string(req.Details) == `{"slide":1}`
+				})).Return(createdEntry, false, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:      "success_actor_override",
+			body:      `{"action":"edit","actorUserId":"550e8400-e29b-41d4-a716-446655440002"}`,
+			tokenType: middleware.TokenTypeJWT,
+			setupMocks: func(m *MockAuditService) {
+				m.On("CreateAuditEntry", mock.Anything, sessionID, "user-456", "", mock.MatchedBy(func(req domain.CreateAuditEntryRequest) bool {
+					return req.ActorUserID == "550e8400-e29b-41d4-a716-446655440002"
+				})).Return(createdEntry, false, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "success_replayed",
+			body:           `{"action":"edit"}`,
+			tokenType:      middleware.TokenTypeJWT,
+			idempotencyKey: "key-1",
+			setupMocks: func(m *MockAuditService) {
+				m.On("CreateAuditEntry", mock.Anything, sessionID, "user-456", "key-1", mock.Anything).
+					Return(createdEntry, true, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedReplay: true,
+		},
+		{
+			name:           "error_idempotency_conflict",
+			body:           `{"action":"merge"}`,
+			tokenType:      middleware.TokenTypeJWT,
+			idempotencyKey: "key-1",
+			setupMocks: func(m *MockAuditService) {
+				m.On("CreateAuditEntry", mock.Anything, sessionID, "user-456", "key-1", mock.Anything).
+					Return(nil, false, domain.ErrIdempotencyConflict)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "conflict",
+		},
+		{
+			name:      "error_malformed_edit_details",
+			body:      `{"action":"edit","details":{"slide":"one"}}`,
+			tokenType: middleware.TokenTypeJWT,
+			setupMocks: func(m *MockAuditService) {
+				m.On("CreateAuditEntry", mock.Anything, sessionID, "user-456", "", mock.Anything).
+					Return(nil, false, &domain.DetailsValidationError{Index: -1, Field: "slide", Reason: "must be of type number"})
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "bad_request",
+		},
+		{
+			name:           "error_share_token_cannot_write",
+			body:           `{"action":"edit"}`,
+			tokenType:      middleware.TokenTypeShare,
+			setupMocks:     func(m *MockAuditService) {},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "forbidden",
+		},
+		{
+			name:           "error_missing_action",
+			body:           `{"details":{}}`,
+			tokenType:      middleware.TokenTypeJWT,
+			setupMocks:     func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "bad_request",
+		},
+		{
+			name:           "error_idempotency_key_too_long",
+			body:           `{"action":"edit"}`,
+			tokenType:      middleware.TokenTypeJWT,
+			idempotencyKey: strings.Repeat("k", 256),
+			setupMocks:     func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "bad_request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+			tt.setupMocks(mockService)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/sessions/"+sessionID+"/history", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			if tt.idempotencyKey != "" {
+				c.Request.Header.Set("Idempotency-Key", tt.idempotencyKey)
+			}
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, tt.tokenType)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.CreateEntry(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedCode != "" {
+				var response domain.APIError
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedCode, response.Code)
+			} else {
+				var response domain.AuditEntry
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, createdEntry.ID, response.ID)
+			}
+			if tt.expectedReplay {
+				assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+			} else {
+				assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_CreateEntries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "123e4567-e89b-12d3-a456-426614174000"
+
+	tests := []struct {
+		name            string
+		body            string
+		setupMocks      func(*MockAuditService)
+		expectedStatus  int
+		expectedIDs     []string
+		expectedIndices []interface{}
+	}{
+		{
+			name: "success_valid_batch",
+			body: `[{"action":"edit","details":{"slide":1}},{"action":"merge"}]`,
+			setupMocks: func(m *MockAuditService) {
+				m.On("CreateAuditEntries", mock.Anything, sessionID, "user-456", mock.MatchedBy(func(reqs []domain.CreateAuditEntryRequest) bool {
+					return len(reqs) == 2 && reqs[0].Action == "edit" && reqs[1].Action == "merge"
+				})).Return([]domain.AuditEntry{{ID: "entry-1"}, {ID: "entry-2"}}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedIDs:    []string{"entry-1", "entry-2"},
+		},
+		{
+			name: "error_mixed_validity_batch",
+			body: `[{"action":"edit"},{"action":"bogus"},{"details":{}}]`,
+			setupMocks: func(m *MockAuditService) {
+				m.On("CreateAuditEntries", mock.Anything, sessionID, "user-456", mock.Anything).
+					Return(nil, &domain.BatchValidationError{InvalidIndices: []int{1, 2}})
+			},
+			expectedStatus:  http.StatusBadRequest,
+			expectedIndices: []interface{}{float64(1), float64(2)},
+		},
+		{
+			name:           "error_malformed_body",
+			body:           `{"action":"edit"}`,
+			setupMocks:     func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+			tt.setupMocks(mockService)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/sessions/"+sessionID+"/history/batch", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.CreateEntries(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedIDs != nil {
+				var response domain.BatchCreateResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedIDs, response.IDs)
+			}
+			if tt.expectedIndices != nil {
+				var response map[string]interface{}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "bad_request", response["error"])
+				details, _ := response["details"].(map[string]interface{})
+				assert.Equal(t, tt.expectedIndices, details["invalid_indices"])
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_ImportEntries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "123e4567-e89b-12d3-a456-426614174000"
+	body := `{"userId":"user-1","action":"edit","timestamp":"2023-11-01T09:00:00Z"}` + "\n" + `{"userId":"user-1","action":"bogus","timestamp":"2023-11-01T09:00:00Z"}`
+
+	tests := []struct {
+		name           string
+		contentType    string
+		setupMocks     func(*MockAuditService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "success_summary",
+			contentType: "application/x-ndjson",
+			setupMocks: func(m *MockAuditService) {
+				m.On("ImportAuditEntries", mock.Anything, sessionID, mock.Anything).Return(&domain.ImportSummary{
+					Inserted: 1,
+					Failed:   1,
+					Errors:   []domain.ImportError{{Line: 2, Error: `invalid action "bogus"`}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"inserted":1,"failed":1,"errors":[{"line":2,"error":"invalid action \"bogus\""}]}`,
+		},
+		{
+			name:        "error_session_not_found",
+			contentType: "application/x-ndjson; charset=utf-8",
+			setupMocks: func(m *MockAuditService) {
+				m.On("ImportAuditEntries", mock.Anything, sessionID, mock.Anything).Return(nil, domain.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+			tt.setupMocks(mockService)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/sessions/"+sessionID+"/history/import", strings.NewReader(body))
+			c.Request.Header.Set("Content-Type", tt.contentType)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.ImportEntries(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_ImportEntries_RouteGuards(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "123e4567-e89b-12d3-a456-426614174000"
+	body := `{"userId":"user-1","action":"edit","timestamp":"2023-11-01T09:00:00Z"}`
+
+	tests := []struct {
+		name           string
+		contentType    string
+		maxBytes       int64
+		expectedStatus int
+	}{
+		{
+			name:           "error_json_content_type",
+			contentType:    "application/json",
+			maxBytes:       1 << 10,
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "error_body_over_import_cap",
+			contentType:    "application/x-ndjson",
+			maxBytes:       16,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+			router := gin.New()
+			router.POST("/sessions/:sessionId/history/import", middleware.RequireNDJSON(), middleware.BodyLimit(tt.maxBytes), handler.ImportEntries)
+
+			req := httptest.NewRequest("POST", "/sessions/"+sessionID+"/history/import", strings.NewReader(body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertNotCalled(t, "ImportAuditEntries", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAuditHandler_CreateEntry_BodyTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "123e4567-e89b-12d3-a456-426614174000"
+	body := `{"action":"edit","details":{"text":"` + strings.Repeat("x", 64) + `"}}`
+
+	for _, path := range []string{"/sessions/:sessionId/history", "/sessions/:sessionId/history/batch"} {
+		t.Run(path, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set(middleware.AuthUserIDKey, "user-456")
+				c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			})
+			router.POST("/sessions/:sessionId/history", middleware.BodyLimit(32), handler.CreateEntry)
+			router.POST("/sessions/:sessionId/history/batch", middleware.BodyLimit(32), handler.CreateEntries)
+
+			// Unknown length so the limit is enforced while decoding
+			req := httptest.NewRequest("POST", strings.Replace(path, ":sessionId", sessionID, 1), strings.NewReader(body))
+			req.ContentLength = -1
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+			var response domain.APIError
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "payload_too_large", response.Code)
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetSummary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "123e4567-e89b-12d3-a456-426614174000"
+	oldest := time.Date(2023, 11, 1, 9, 0, 0, 0, time.UTC)
+	latest := time.Date(2023, 12, 1, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMocks     func(*MockAuditService)
+		expectedStatus int
+	}{
+		{
+			name:  "success",
+			query: "?window=7d",
+			setupMocks: func(m *MockAuditService) {
+				m.On("GetSummary", mock.Anything, sessionID, "user-456", false, "7d").Return(&domain.AuditSummary{
+					SessionID:      sessionID,
+					Window:         "168h0m0s",
+					TotalCount:     3,
+					ActionCounts:   map[string]int{"edit": 2, "merge": 1},
+					LastActivity:   &latest,
+					OldestActivity: &oldest,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "error_window_too_large",
+			query: "?window=365d",
+			setupMocks: func(m *MockAuditService) {
+				m.On("GetSummary", mock.Anything, sessionID, "user-456", false, "365d").
+					Return(nil, domain.ErrInvalidWindow)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+			tt.setupMocks(mockService)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/summary"+tt.query, nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetSummary(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response domain.AuditSummary
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, 3, response.TotalCount)
+				assert.NotNil(t, response.LastActivity)
+				assert.NotNil(t, response.OldestActivity)
+				assert.True(t, response.OldestActivity.Before(*response.LastActivity))
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetEntry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const (
+		sessionID = "550e8400-e29b-41d4-a716-446655440000"
+		entryID   = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	)
+	entry := &domain.AuditEntry{
+		ID:        entryID,
+		SessionID: sessionID,
+		UserID:    "user-456",
+		Action:    "edit",
+		Timestamp: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+	}
+	stale := domain.AuditEntry{ID: entryID, Timestamp: entry.Timestamp.Add(-time.Minute)}
+
+	tests := []struct {
+		name           string
+		entryID        string
+		ifMatch        string
+		serviceErr     error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "success", entryID: entryID, expectedStatus: http.StatusOK},
+		{name: "matching_if_match", entryID: entryID, ifMatch: entry.ETag(), expectedStatus: http.StatusOK},
+		{name: "wildcard_if_match", entryID: entryID, ifMatch: "*", expectedStatus: http.StatusOK},
+		{name: "stale_if_match", entryID: entryID, ifMatch: stale.ETag(), expectedStatus: http.StatusPreconditionFailed, expectedCode: "precondition_failed"},
+		{name: "weak_if_match", entryID: entryID, ifMatch: "W/" + entry.ETag(), expectedStatus: http.StatusPreconditionFailed, expectedCode: "precondition_failed"},
+		{name: "invalid_entry_id", entryID: "not-a-uuid", expectedStatus: http.StatusBadRequest, expectedCode: "bad_request"},
+		{name: "not_found", entryID: entryID, serviceErr: domain.ErrEntryNotFound, expectedStatus: http.StatusNotFound, expectedCode: "not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+			if tt.entryID == entryID {
+				if tt.serviceErr != nil {
+					mockService.On("GetAuditEntry", mock.Anything, sessionID, "user-456", false, entryID).Return(nil, tt.serviceErr)
+				} else {
+					mockService.On("GetAuditEntry", mock.Anything, sessionID, "user-456", false, entryID).Return(entry, nil)
+				}
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history/"+tt.entryID, nil)
+			if tt.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tt.ifMatch)
+			}
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}, {Key: "entryId", Value: tt.entryID}}
+
+			handler.GetEntry(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, entry.ETag(), w.Header().Get("ETag"))
+				var response domain.AuditEntry
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, entryID, response.ID)
+			} else {
+				assert.Empty(t, w.Header().Get("ETag"))
+				var response domain.APIError
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedCode, response.Code)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetHistoryBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const (
+		sharedSession  = "550e8400-e29b-41d4-a716-446655440000"
+		deniedSession  = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+		failingSession = "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+	)
+
+	mockService := new(MockAuditService)
+	handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+
+	pagination := domain.PaginationParams{Limit: 5}
+	filter := domain.HistoryFilter{Actions: []string{"edit"}}
+	mockService.On("GetAuditLogs", mock.Anything, sharedSession, "", true, pagination, filter).
+		Return(&domain.AuditResponse{TotalCount: 1, Items: []domain.AuditEntry{{ID: "entry-1", SessionID: sharedSession, Action: "edit"}}}, nil)
+	mockService.On("GetAuditLogs", mock.Anything, failingSession, "", true, pagination, filter).
+		Return(nil, domain.ErrSessionNotFound)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/history/batch?limit=5&action=edit", nil)
+	c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeShare)
+	c.Set(middleware.AuthSessionGrantsKey, []middleware.SessionGrant{
+		{SessionID: sharedSession},
+		{SessionID: deniedSession, Err: domain.ErrShareTokenExpired},
+		{SessionID: failingSession},
+	})
+
+	handler.GetHistoryBatch(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response domain.MultiSessionHistoryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Sessions, 3)
+
+	shared := response.Sessions[sharedSession]
+	assert.Nil(t, shared.Error)
+	assert.Equal(t, 1, shared.TotalCount)
+	require.Len(t, shared.Items, 1)
+	assert.Equal(t, "entry-1", shared.Items[0].ID)
+
+	denied := response.Sessions[deniedSession]
+	require.NotNil(t, denied.Error)
+	assert.Equal(t, "share_token_expired", denied.Error.Code)
+	assert.Empty(t, denied.Items)
+
+	failing := response.Sessions[failingSession]
+	require.NotNil(t, failing.Error)
+	assert.Equal(t, "not_found", failing.Error.Code)
+
+	// Only rows actually served are charged to each session's share token
+	served, _ := c.Get(middleware.SessionRowsServedKey)
+	assert.Equal(t, map[string]int{sharedSession: 1}, served)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAuditHandler_ListShares(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const sessionID = "550e8400-e29b-41d4-a716-446655440000"
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		tokenType      string
+		setupMocks     func(*MockAuditService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:      "owner",
+			tokenType: middleware.TokenTypeJWT,
+			setupMocks: func(m *MockAuditService) {
+				m.On("ListShares", mock.Anything, sessionID, "user-456").Return([]domain.ShareGrant{
+					{Token: "****3456", ExpiresAt: &expiresAt},
+					{Token: "****abcd"},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"items":[{"token":"****3456","expiresAt":"2030-01-01T00:00:00Z"},{"token":"****abcd"}]}`,
+		},
+		{
+			name:      "not_owner",
+			tokenType: middleware.TokenTypeJWT,
+			setupMocks: func(m *MockAuditService) {
+				m.On("ListShares", mock.Anything, sessionID, "user-456").Return(nil, domain.ErrForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "share_token_forbidden",
+			tokenType:      middleware.TokenTypeShare,
+			setupMocks:     func(m *MockAuditService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+			tt.setupMocks(mockService)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/shares", nil)
+			if tt.tokenType == middleware.TokenTypeJWT {
+				c.Set(middleware.AuthUserIDKey, "user-456")
+			}
+			c.Set(middleware.AuthTokenTypeKey, tt.tokenType)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.ListShares(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_ListSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	latest := time.Date(2023, 12, 1, 10, 30, 0, 0, time.UTC)
+	sessions := []domain.SessionSummary{
+		{SessionID: "session-1", UserID: "user-456", LastActivity: &latest},
+		{SessionID: "session-2", UserID: "user-456"},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		tokenType      string
+		setupMocks     func(*MockAuditService)
+		expectedStatus int
+	}{
+		{
+			name:      "owner_me",
+			query:     "?userId=me",
+			tokenType: middleware.TokenTypeJWT,
+			setupMocks: func(m *MockAuditService) {
+				m.On("ListSessions", mock.Anything, "user-456").Return(sessions, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "owner_explicit_id",
+			query:     "?userId=user-456",
+			tokenType: middleware.TokenTypeJWT,
+			setupMocks: func(m *MockAuditService) {
+				m.On("ListSessions", mock.Anything, "user-456").Return(sessions, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "cross_user_forbidden",
+			query:          "?userId=user-789",
+			tokenType:      middleware.TokenTypeJWT,
+			setupMocks:     func(m *MockAuditService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:      "admin_cross_user",
+			query:     "?userId=user-789",
+			tokenType: middleware.TokenTypeAdmin,
+			setupMocks: func(m *MockAuditService) {
+				m.On("ListSessions", mock.Anything, "user-789").Return([]domain.SessionSummary{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "admin_me_rejected",
+			query:          "?userId=me",
+			tokenType:      middleware.TokenTypeAdmin,
+			setupMocks:     func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing_user_id",
+			query:          "",
+			tokenType:      middleware.TokenTypeJWT,
+			setupMocks:     func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "service_unavailable",
+			query:     "?userId=me",
+			tokenType: middleware.TokenTypeJWT,
+			setupMocks: func(m *MockAuditService) {
+				m.On("ListSessions", mock.Anything, "user-456").Return(nil, domain.ErrServiceUnavailable)
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, testMaxResponseBytes, zap.NewNop())
+			tt.setupMocks(mockService)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions"+tt.query, nil)
+			if tt.tokenType == middleware.TokenTypeJWT {
+				c.Set(middleware.AuthUserIDKey, "user-456")
+			}
+			c.Set(middleware.AuthTokenTypeKey, tt.tokenType)
+
+			handler.ListSessions(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response domain.SessionListResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.NotNil(t, response.Items)
+			}
+
+			mockService.AssertExpectations(t)
 		})
 	}
 }
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+
+	"audit-service/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ConcurrencyStats reports how many outbound requests are in flight
+type ConcurrencyStats interface {
+	InFlight() int
+	MaxConcurrent() int
+}
+
+// CoalescingStats reports how many history reads shared an in-flight query
+type CoalescingStats interface {
+	HistoryReads() int64
+	CoalescedHistoryReads() int64
+}
+
+// DebugHandler handles operational troubleshooting requests
+type DebugHandler struct {
+	cfg      *config.Config
+	supabase ConcurrencyStats
+	reads    CoalescingStats
+	logger   *zap.Logger
+}
+
+// MetricsResponse reports runtime counters for troubleshooting
+type MetricsResponse struct {
+	SupabaseInFlight      int   `json:"supabase_in_flight"`
+	SupabaseMaxConcurrent int   `json:"supabase_max_concurrent"`
+	HistoryReads          int64 `json:"history_reads"`
+	HistoryReadsCoalesced int64 `json:"history_reads_coalesced"`
+}
+
+// NewDebugHandler creates a new debug handler
+func NewDebugHandler(cfg *config.Config, supabase ConcurrencyStats, reads CoalescingStats, logger *zap.Logger) *DebugHandler {
+	return &DebugHandler{
+		cfg:      cfg,
+		supabase: supabase,
+		reads:    reads,
+		logger:   logger,
+	}
+}
+
+// GetConfig handles GET /debug/config
+// @Summary Get loaded configuration
+// @Description Returns the non-secret configuration values the service is running with
+// @Tags Debug
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} config.PublicConfig
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Router /debug/config [get]
+func (h *DebugHandler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.cfg.Public())
+}
+
+// GetMetrics handles GET /debug/metrics
+// @Summary Get runtime metrics
+// @Description Returns in-flight and maximum concurrent Supabase requests, and how many history reads were coalesced into a shared query
+// @Tags Debug
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} MetricsResponse
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Router /debug/metrics [get]
+func (h *DebugHandler) GetMetrics(c *gin.Context) {
+	c.JSON(http.StatusOK, MetricsResponse{
+		SupabaseInFlight:      h.supabase.InFlight(),
+		SupabaseMaxConcurrent: h.supabase.MaxConcurrent(),
+		HistoryReads:          h.reads.HistoryReads(),
+		HistoryReadsCoalesced: h.reads.CoalescedHistoryReads(),
+	})
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"audit-service/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestDebugHandler_GetConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		Port:                   "4006",
+		SupabaseURL:            "https://example.supabase.co",
+		SupabaseAnonKey:        "anon-key-secret",
+		SupabaseServiceRoleKey: "service-role-key-secret",
+		SupabaseJWTSecret:      "jwt-secret-value",
+		AdminToken:             "admin-token-secret",
+		HTTPTimeout:            30 * time.Second,
+		CacheJWTTTL:            5 * time.Minute,
+		MaxPageSize:            100,
+	}
+	handler := NewDebugHandler(cfg, nil, nil, zap.NewNop())
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/debug/config", nil)
+
+	handler.GetConfig(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	for _, secret := range []string{"anon-key-secret", "service-role-key-secret", "jwt-secret-value", "admin-token-secret"} {
+		assert.NotContains(t, body, secret)
+	}
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.NotContains(t, response, "supabase_anon_key")
+	assert.NotContains(t, response, "supabase_service_role_key")
+	assert.NotContains(t, response, "supabase_jwt_secret")
+	assert.Equal(t, float64(100), response["max_page_size"])
+	assert.Equal(t, "30s", response["http_timeout"])
+	assert.Equal(t, "5m0s", response["cache_jwt_ttl"])
+}
+
+// stubConcurrencyStats reports fixed concurrency numbers
+type stubConcurrencyStats struct {
+	inFlight, max int
+}
+
+func (s stubConcurrencyStats) InFlight() int      { return s.inFlight }
+func (s stubConcurrencyStats) MaxConcurrent() int { return s.max }
+
+// stubCoalescingStats reports fixed history read counts
+type stubCoalescingStats struct {
+	reads, coalesced int64
+}
+
+func (s stubCoalescingStats) HistoryReads() int64          { return s.reads }
+func (s stubCoalescingStats) CoalescedHistoryReads() int64 { return s.coalesced }
+
+func TestDebugHandler_GetMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewDebugHandler(&config.Config{}, stubConcurrencyStats{inFlight: 3, max: 20}, stubCoalescingStats{reads: 12, coalesced: 5}, zap.NewNop())
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/debug/metrics", nil)
+
+	handler.GetMetrics(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"supabase_in_flight":3,"supabase_max_concurrent":20,"history_reads":12,"history_reads_coalesced":5}`, w.Body.String())
+}
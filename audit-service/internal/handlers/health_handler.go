@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// DependencyCheck probes a dependency the service needs to serve requests
+type DependencyCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// HealthHandler serves the Kubernetes-style liveness and readiness probes
+type HealthHandler struct {
+	checks  []DependencyCheck
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+// DependencyStatus is the outcome of one dependency check
+type DependencyStatus struct {
+	Name      string `json:"name" example:"supabase"`
+	Status    string `json:"status" example:"up"`
+	LatencyMs int64  `json:"latencyMs" example:"12"`
+}
+
+// ReadinessResponse reports whether the service can serve traffic and why
+type ReadinessResponse struct {
+	Status       string             `json:"status" example:"ready"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+// NewHealthHandler creates a new health handler. Each check is bounded by timeout.
+func NewHealthHandler(timeout time.Duration, checks []DependencyCheck, logger *zap.Logger) *HealthHandler {
+	return &HealthHandler{
+		checks:  checks,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+// Live handles GET /health/live
+// @Summary Liveness probe
+// @Description Reports that the process is running. Never touches dependencies, so a Supabase outage doesn't get the pod restarted.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /health/live [get]
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "alive"})
+}
+
+// Ready handles GET /health/ready
+// @Summary Readiness probe
+// @Description Checks every dependency concurrently and reports each one's status and latency. Responds 503 if any dependency is down.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} ReadinessResponse
+// @Failure 503 {object} ReadinessResponse
+// @Router /health/ready [get]
+func (h *HealthHandler) Ready(c *gin.Context) {
+	response := ReadinessResponse{
+		Status:       "ready",
+		Dependencies: make([]DependencyStatus, len(h.checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response.Dependencies[i] = h.run(c.Request.Context(), check)
+		}()
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	for _, dependency := range response.Dependencies {
+		if dependency.Status != "up" {
+			response.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+	}
+	c.JSON(status, response)
+}
+
+// run performs one dependency check within the configured timeout
+func (h *HealthHandler) run(ctx context.Context, check DependencyCheck) DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Check(ctx)
+	result := DependencyStatus{
+		Name:      check.Name,
+		Status:    "up",
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		// The probe is unauthenticated, so the cause is only logged
+		h.logger.Warn("readiness check failed",
+			zap.String("dependency", check.Name),
+			zap.Error(err),
+		)
+		result.Status = "down"
+	}
+	return result
+}
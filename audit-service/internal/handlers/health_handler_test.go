@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestHealthHandler_Live(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	checked := false
+	handler := NewHealthHandler(time.Second, []DependencyCheck{
+		{Name: "supabase", Check: func(ctx context.Context) error {
+			checked = true
+			return errors.New("down")
+		}},
+	}, zap.NewNop())
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/health/live", nil)
+
+	handler.Live(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"alive"}`, w.Body.String())
+	assert.False(t, checked, "liveness must not touch dependencies")
+}
+
+func TestHealthHandler_Ready(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	up := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+	hangs := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name             string
+		checks           []DependencyCheck
+		expectedStatus   int
+		expectedReady    string
+		expectedStatuses map[string]string
+	}{
+		{
+			name:             "all_up",
+			checks:           []DependencyCheck{{Name: "supabase", Check: up}, {Name: "other", Check: up}},
+			expectedStatus:   http.StatusOK,
+			expectedReady:    "ready",
+			expectedStatuses: map[string]string{"supabase": "up", "other": "up"},
+		},
+		{
+			name:             "one_down",
+			checks:           []DependencyCheck{{Name: "supabase", Check: down}, {Name: "other", Check: up}},
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedReady:    "unavailable",
+			expectedStatuses: map[string]string{"supabase": "down", "other": "up"},
+		},
+		{
+			name:             "check_times_out",
+			checks:           []DependencyCheck{{Name: "supabase", Check: hangs}},
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedReady:    "unavailable",
+			expectedStatuses: map[string]string{"supabase": "down"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHealthHandler(20*time.Millisecond, tt.checks, zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/health/ready", nil)
+
+			handler.Ready(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response ReadinessResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedReady, response.Status)
+			statuses := map[string]string{}
+			for _, dependency := range response.Dependencies {
+				statuses[dependency.Name] = dependency.Status
+				assert.GreaterOrEqual(t, dependency.LatencyMs, int64(0))
+			}
+			assert.Equal(t, tt.expectedStatuses, statuses)
+			assert.NotContains(t, w.Body.String(), "connection refused", "failure causes are only logged")
+		})
+	}
+}
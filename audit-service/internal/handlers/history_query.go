@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"audit-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// naiveTimestampLayout is RFC3339 without an offset, accepted for from/to
+// only when tz names the zone to read it in
+const naiveTimestampLayout = "2006-01-02T15:04:05.999999999"
+
+// historyQuery binds the GET history query parameters. The upper bound on
+// limit is the configured MAX_PAGE_SIZE, which the service clamps to (or
+// rejects with STRICT_PAGINATION).
+type historyQuery struct {
+	Limit          int      `form:"limit" binding:"min=0"`
+	Offset         int      `form:"offset" binding:"min=0"`
+	Actions        []string `form:"action"`
+	SinceID        string   `form:"sinceId"`
+	RawFrom        string   `form:"from"`
+	RawTo          string   `form:"to"`
+	TZ             string   `form:"tz"`
+	Include        []string `form:"include"`
+	Count          bool     `form:"count"`
+	IncludeDeleted bool     `form:"includeDeleted"`
+	IncludeDetails *bool    `form:"details"`
+
+	// Wait long-polls: hold the read until entries newer than sinceId exist
+	Wait time.Duration `form:"wait" binding:"min=0"`
+
+	// From and To are the parsed time range, in UTC like audit timestamps
+	From time.Time `form:"-"`
+	To   time.Time `form:"-"`
+}
+
+// omitDetails reports whether details=false asked to leave the details blob out
+func (q historyQuery) omitDetails() bool {
+	return q.IncludeDetails != nil && !*q.IncludeDetails
+}
+
+// integerParams are the query parameters that must be plain non-negative
+// decimal integers
+var integerParams = []string{"limit", "offset"}
+
+// decimalPattern matches what integerParams accept. strconv alone would also
+// let through a leading sign, e.g. +10.
+var decimalPattern = regexp.MustCompile(`^[0-9]+$`)
+
+const integerReason = "must be a non-negative integer"
+
+// bindHistoryQuery binds and validates the history query, writing a 400
+// response naming the offending parameter when it is invalid
+func bindHistoryQuery(c *gin.Context) (historyQuery, bool) {
+	if field := malformedIntegerParam(c); field != "" {
+		writeQueryError(c, field, integerReason)
+		return historyQuery{}, false
+	}
+
+	var query historyQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		field, reason := queryBindingError(c, err)
+		// Negative or out of range integers get the same message as malformed ones
+		if slices.Contains(integerParams, field) {
+			reason = integerReason
+		}
+		writeQueryError(c, field, reason)
+		return historyQuery{}, false
+	}
+	// sinceId is checked like the session and entry IDs so all three accept the same UUIDs
+	if query.SinceID != "" && !domain.IsValidUUID(query.SinceID) {
+		apiErr := domain.NewAPIError("bad_request", "Invalid since ID format", http.StatusBadRequest)
+		apiErr.Details = map[string]interface{}{"field": "sinceId"}
+		c.JSON(http.StatusBadRequest, apiErr)
+		return historyQuery{}, false
+	}
+	if field, reason := query.parseTimeRange(); field != "" {
+		writeQueryError(c, field, reason)
+		return historyQuery{}, false
+	}
+	return query, true
+}
+
+// writeQueryError writes a 400 response naming the invalid query parameter
+func writeQueryError(c *gin.Context, field, reason string) {
+	apiErr := domain.NewAPIError("bad_request", fmt.Sprintf("Invalid %s parameter", field), http.StatusBadRequest)
+	if reason != "" {
+		apiErr.Message += ": " + reason
+	}
+	apiErr.Details = map[string]interface{}{"field": field}
+	c.JSON(http.StatusBadRequest, apiErr)
+}
+
+// parseTimeRange parses from and to into UTC. Timestamps must carry an
+// offset unless tz names an IANA zone to interpret them in. A non-empty
+// field names the parameter that was rejected.
+func (q *historyQuery) parseTimeRange() (field, reason string) {
+	var loc *time.Location
+	if q.TZ != "" {
+		var err error
+		// Local would depend on the server's zone, so only explicit zones are accepted
+		if loc, err = time.LoadLocation(q.TZ); err != nil || q.TZ == "Local" {
+			return "tz", "unknown time zone"
+		}
+	}
+
+	var ok bool
+	if q.From, ok = parseTimestamp(q.RawFrom, loc); !ok {
+		return "from", ""
+	}
+	if q.To, ok = parseTimestamp(q.RawTo, loc); !ok {
+		return "to", ""
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.To.After(q.From) {
+		return "to", "must be after from"
+	}
+	return "", ""
+}
+
+// parseTimestamp parses an RFC3339 timestamp, or a timestamp without an
+// offset in loc when loc is set, returning it in UTC. Empty values yield
+// the zero time.
+func parseTimestamp(value string, loc *time.Location) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), true
+	}
+	if loc != nil {
+		if t, err := time.ParseInLocation(naiveTimestampLayout, value, loc); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// malformedIntegerParam returns the first integer parameter whose value is
+// not plain decimal digits, such as 10.5, 0x10 or +10. Empty values are left
+// to binding, which reads them as zero.
+func malformedIntegerParam(c *gin.Context) string {
+	query := c.Request.URL.Query()
+	for _, name := range integerParams {
+		for _, value := range query[name] {
+			if value != "" && !decimalPattern.MatchString(value) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// queryBindingError returns the query parameter a binding error refers to
+// and, for failed validation rules, why the value was rejected
+func queryBindingError(c *gin.Context, err error) (field, reason string) {
+	queryType := reflect.TypeOf(historyQuery{})
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) && len(validationErrs) > 0 {
+		fieldErr := validationErrs[0]
+		field = formName(queryType, fieldErr.StructField())
+		switch fieldErr.Tag() {
+		case "min":
+			reason = "must be at least " + fieldErr.Param()
+		}
+		return field, reason
+	}
+
+	// Conversion errors (e.g. limit=abc) don't carry the field name, so find
+	// the parameter that fails to bind on its own
+	query := c.Request.URL.Query()
+	for i := 0; i < queryType.NumField(); i++ {
+		name := queryType.Field(i).Tag.Get("form")
+		values, ok := query[name]
+		if !ok {
+			continue
+		}
+		probe := reflect.New(queryType).Interface()
+		if binding.MapFormWithTag(probe, map[string][]string{name: values}, "form") != nil {
+			return name, ""
+		}
+	}
+	return "query", ""
+}
+
+// formName returns the query parameter name bound to a historyQuery field
+func formName(queryType reflect.Type, structField string) string {
+	if f, ok := queryType.FieldByName(structField); ok {
+		return f.Tag.Get("form")
+	}
+	return strings.ToLower(structField)
+}
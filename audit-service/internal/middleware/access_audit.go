@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AccessAudit middleware records who read which session after a successful request
+func AccessAudit(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		// Only successful reads are recorded
+		if c.Writer.Status() != http.StatusOK {
+			return
+		}
+
+		tokenType := GetAuthTokenType(c)
+		fields := []zap.Field{
+			zap.String("request_id", GetRequestID(c)),
+			zap.String("session_id", GetSessionID(c)),
+			zap.String("token_type", tokenType),
+			zap.Time("accessed_at", time.Now().UTC()),
+		}
+
+		// Never log the raw share token, only its fingerprint
+		if tokenType == TokenTypeShare {
+			fields = append(fields, zap.String("share_token_fingerprint", GetShareTokenFingerprint(c)))
+		} else {
+			fields = append(fields, zap.String("user_id", GetAuthUserID(c)))
+		}
+
+		logger.Info("access audit", fields...)
+	}
+}
+
+// tokenFingerprint returns a short, non-reversible identifier for a token
+func tokenFingerprint(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return fmt.Sprintf("%x", hash[:8])
+}
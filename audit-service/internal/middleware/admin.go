@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"audit-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AdminAuth middleware restricts access to callers presenting the admin token
+func AdminAuth(adminToken string, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := GetRequestID(c)
+
+		token := extractBearerToken(c.GetHeader("Authorization"))
+		if token == "" {
+			c.JSON(http.StatusUnauthorized, domain.APIErrUnauthorized)
+			c.Abort()
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			logger.Warn("invalid admin token",
+				zap.String("request_id", requestID),
+				zap.String("path", c.Request.URL.Path),
+			)
+			c.JSON(http.StatusForbidden, domain.APIErrForbidden)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
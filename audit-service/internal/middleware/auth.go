@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"time"
 
@@ -15,19 +16,26 @@ import (
 )
 
 const (
-	AuthUserIDKey    = "auth_user_id"
-	AuthTokenTypeKey = "auth_token_type"
-	TokenTypeJWT     = "jwt"
-	TokenTypeShare   = "share"
+	AuthUserIDKey           = "auth_user_id"
+	AuthTokenTypeKey        = "auth_token_type"
+	AuthShareFingerprintKey = "auth_share_fingerprint"
+	TokenTypeJWT            = "jwt"
+	TokenTypeShare          = "share"
+	TokenTypeAdmin          = "admin"
+
+	// SessionIDHeader carries the session ID for gateways that strip it from the path
+	SessionIDHeader = "X-Session-Id"
 )
 
-// Auth middleware validates JWT tokens or share tokens
-func Auth(validator jwt.TokenValidator, tokenCache *cache.TokenCache, repo repository.AuditRepository, logger *zap.Logger) gin.HandlerFunc {
+// Auth middleware validates JWT tokens or, if shareTokensEnabled, share tokens.
+// JWTs longer than maxTokenLength bytes are rejected without being parsed, and
+// JWTs of denied users are refused with 403.
+func Auth(validator jwt.TokenValidator, tokenCache *cache.TokenCache, repo repository.AuditRepository, shareTokensEnabled bool, maxTokenLength int, deniedUsers DeniedUsers, logger *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		requestID := GetRequestID(c)
 
-		// Extract session ID from path
-		sessionID := c.Param("sessionId")
+		// Extract session ID from the path, or the X-Session-Id header when the path has none
+		sessionID := GetSessionID(c)
 		if sessionID == "" {
 			logger.Warn("missing session ID in path",
 				zap.String("request_id", requestID),
@@ -36,71 +44,121 @@ func Auth(validator jwt.TokenValidator, tokenCache *cache.TokenCache, repo repos
 			c.Abort()
 			return
 		}
-
-		// Check for share token first
-		shareToken := c.Query("share_token")
-		if shareToken != "" {
-			// Validate share token
-			if validateShareToken(c, shareToken, sessionID, tokenCache, repo, logger) {
-				c.Set(AuthTokenTypeKey, TokenTypeShare)
-				c.Next()
-				return
-			}
-			// If share token is invalid, don't fall through to JWT
-			c.JSON(403, domain.APIErrForbidden)
+		// A route word in the session position means a malformed URL, not a session to authorize
+		if domain.IsReservedPathSegment(sessionID) {
+			c.JSON(400, domain.NewReservedSegmentError(sessionID))
 			c.Abort()
 			return
 		}
-
-		// Check for JWT token
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			logger.Warn("missing authorization header",
-				zap.String("request_id", requestID),
-			)
-			c.JSON(401, domain.APIErrUnauthorized)
+		// Path params are validated by the handlers; a header value is checked before it reaches the share token lookup
+		if c.Param("sessionId") == "" && !domain.IsValidUUID(sessionID) {
+			c.JSON(400, domain.NewAPIError("bad_request", "Invalid session ID format", 400))
 			c.Abort()
 			return
 		}
 
-		// Extract token from Bearer scheme
-		token := extractBearerToken(authHeader)
-		if token == "" {
-			logger.Warn("invalid authorization header format",
+		// Check for share token first; when disabled it is ignored and a JWT is required
+		shareToken := c.Query("share_token")
+		if shareToken != "" && !shareTokensEnabled {
+			logger.Debug("ignoring share token because share tokens are disabled",
 				zap.String("request_id", requestID),
 			)
-			c.JSON(401, domain.APIErrUnauthorized)
-			c.Abort()
+		}
+		if shareToken != "" && shareTokensEnabled {
+			// Validate share token
+			if err := validateShareToken(c, shareToken, sessionID, tokenCache, repo, logger); err != nil {
+				// If share token is invalid, don't fall through to JWT
+				apiErr := domain.ToAPIError(err)
+				WriteAPIError(c, apiErr)
+				c.Abort()
+				return
+			}
+			c.Set(AuthTokenTypeKey, TokenTypeShare)
+			c.Set(AuthShareFingerprintKey, tokenFingerprint(shareToken))
+			c.Next()
 			return
 		}
 
-		// Validate JWT token
-		if !validateJWTToken(c, token, validator, tokenCache, logger) {
+		// Check for JWT token
+		if !authenticateJWT(c, validator, tokenCache, maxTokenLength, logger) {
 			c.JSON(401, domain.APIErrUnauthorized)
 			c.Abort()
 			return
 		}
+		if rejectDeniedUser(c, deniedUsers, logger) {
+			return
+		}
 
 		c.Set(AuthTokenTypeKey, TokenTypeJWT)
 		c.Next()
 	}
 }
 
-// extractBearerToken extracts the token from the Bearer scheme
-func extractBearerToken(authHeader string) string {
-	// Trim any leading/trailing whitespace
-	authHeader = strings.TrimSpace(authHeader)
+// authenticateJWT validates the bearer JWT in the Authorization header and
+// stores the caller's user ID in the context
+func authenticateJWT(c *gin.Context, validator jwt.TokenValidator, tokenCache *cache.TokenCache, maxTokenLength int, logger *zap.Logger) bool {
+	requestID := GetRequestID(c)
 
-	// Check if it starts with "Bearer " (case-insensitive)
-	if len(authHeader) < 7 || strings.ToLower(authHeader[:6]) != "bearer" {
-		return ""
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		logger.Warn("missing authorization header",
+			zap.String("request_id", requestID),
+		)
+		return false
 	}
 
-	// Extract everything after "Bearer " and trim spaces
-	token := strings.TrimSpace(authHeader[6:])
-
-	// Token should not be empty
+	// Extract token from Bearer scheme
+	token := extractBearerToken(authHeader)
 	if token == "" {
+		logger.Warn("invalid authorization header format",
+			zap.String("request_id", requestID),
+		)
+		return false
+	}
+
+	// Reject oversized tokens before spending time on hashing or parsing
+	if len(token) > maxTokenLength {
+		logger.Warn("jwt exceeds maximum length",
+			zap.String("request_id", requestID),
+			zap.Int("length", len(token)),
+			zap.Int("max_length", maxTokenLength),
+		)
+		return false
+	}
+
+	return validateJWTToken(c, token, validator, tokenCache, logger)
+}
+
+// rejectDeniedUser responds 403 when the authenticated user is on the denylist.
+// It runs after every JWT check, cached or not, so a denial takes effect at once.
+func rejectDeniedUser(c *gin.Context, deniedUsers DeniedUsers, logger *zap.Logger) bool {
+	userID := GetAuthUserID(c)
+	if !deniedUsers.Contains(userID) {
+		return false
+	}
+
+	logger.Warn("denied user rejected",
+		zap.String("request_id", GetRequestID(c)),
+		zap.String("user_id", userID),
+	)
+	c.JSON(403, domain.APIErrForbidden)
+	c.Abort()
+	return true
+}
+
+// extractBearerToken extracts the token from the Bearer scheme. Headers
+// carrying anything besides the scheme and a single token, such as several
+// comma-joined credentials, yield an empty token.
+func extractBearerToken(authHeader string) string {
+	// Exactly the scheme and the token, separated by whitespace
+	parts := strings.Fields(authHeader)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+		return ""
+	}
+
+	// A comma means credentials for several schemes were joined together
+	token := parts[1]
+	if strings.Contains(token, ",") {
 		return ""
 	}
 
@@ -113,6 +171,15 @@ func validateJWTToken(c *gin.Context, token string, validator jwt.TokenValidator
 
 	// Check cache first
 	if cached, found := tokenCache.GetJWT(token); found {
+		if tokenCache.IsJWTRevoked(cached.TokenID) {
+			logger.Warn("revoked jwt rejected",
+				zap.String("request_id", requestID),
+				zap.String("user_id", cached.UserID),
+			)
+			tokenCache.InvalidateJWT(token)
+			return false
+		}
+
 		logger.Debug("jwt token found in cache",
 			zap.String("request_id", requestID),
 			zap.String("user_id", cached.UserID),
@@ -134,9 +201,18 @@ func validateJWTToken(c *gin.Context, token string, validator jwt.TokenValidator
 		return false
 	}
 
+	if tokenCache.IsJWTRevoked(claims.TokenID) {
+		logger.Warn("revoked jwt rejected",
+			zap.String("request_id", requestID),
+			zap.String("user_id", claims.UserID),
+		)
+		return false
+	}
+
 	// Cache successful validation
 	tokenCache.SetJWT(token, &cache.CachedTokenInfo{
 		UserID:    claims.UserID,
+		TokenID:   claims.TokenID,
 		ExpiresAt: claims.ExpiresAt.Time,
 	})
 
@@ -149,8 +225,9 @@ func validateJWTToken(c *gin.Context, token string, validator jwt.TokenValidator
 	return true
 }
 
-// validateShareToken validates a share token and caches the result
-func validateShareToken(c *gin.Context, token, sessionID string, tokenCache *cache.TokenCache, repo repository.AuditRepository, logger *zap.Logger) bool {
+// validateShareToken validates a share token and caches the result. It returns
+// domain.ErrShareTokenExpired for expired tokens and domain.ErrForbidden otherwise.
+func validateShareToken(c *gin.Context, token, sessionID string, tokenCache *cache.TokenCache, repo repository.AuditRepository, logger *zap.Logger) error {
 	requestID := GetRequestID(c)
 
 	// Check cache first
@@ -159,21 +236,28 @@ func validateShareToken(c *gin.Context, token, sessionID string, tokenCache *cac
 			zap.String("request_id", requestID),
 			zap.String("session_id", sessionID),
 		)
-		return true
+		return nil
 	}
 
 	// Validate with repository
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	valid, err := repo.ValidateShareToken(ctx, token, sessionID)
+	valid, expiresAt, err := repo.ValidateShareToken(ctx, token, sessionID)
+	if errors.Is(err, domain.ErrShareTokenExpired) {
+		logger.Warn("expired share token",
+			zap.String("request_id", requestID),
+			zap.String("session_id", sessionID),
+		)
+		return domain.ErrShareTokenExpired
+	}
 	if err != nil {
 		logger.Error("share token validation error",
 			zap.String("request_id", requestID),
 			zap.String("session_id", sessionID),
 			zap.Error(err),
 		)
-		return false
+		return domain.ErrForbidden
 	}
 
 	if !valid {
@@ -181,13 +265,13 @@ func validateShareToken(c *gin.Context, token, sessionID string, tokenCache *cac
 			zap.String("request_id", requestID),
 			zap.String("session_id", sessionID),
 		)
-		return false
+		return domain.ErrForbidden
 	}
 
-	// Cache successful validation
+	// Cache successful validation; the cache caps the TTL at the token expiry
 	tokenCache.SetShareToken(token, sessionID, &cache.CachedTokenInfo{
 		SessionID: sessionID,
-		ExpiresAt: time.Now().Add(24 * time.Hour), // Default expiry
+		ExpiresAt: expiresAt,
 	})
 
 	logger.Debug("share token validated and cached",
@@ -195,7 +279,16 @@ func validateShareToken(c *gin.Context, token, sessionID string, tokenCache *cac
 		zap.String("session_id", sessionID),
 	)
 
-	return true
+	return nil
+}
+
+// GetSessionID returns the sessionId path parameter, falling back to the
+// X-Session-Id header when the route has no session segment
+func GetSessionID(c *gin.Context) string {
+	if sessionID := c.Param("sessionId"); sessionID != "" {
+		return sessionID
+	}
+	return strings.TrimSpace(c.GetHeader(SessionIDHeader))
 }
 
 // GetAuthUserID retrieves the authenticated user ID from context
@@ -208,6 +301,16 @@ func GetAuthUserID(c *gin.Context) string {
 	return ""
 }
 
+// GetShareTokenFingerprint retrieves the hashed share token from context
+func GetShareTokenFingerprint(c *gin.Context) string {
+	if fingerprint, exists := c.Get(AuthShareFingerprintKey); exists {
+		if f, ok := fingerprint.(string); ok {
+			return f
+		}
+	}
+	return ""
+}
+
 // GetAuthTokenType retrieves the token type from context
 func GetAuthTokenType(c *gin.Context) string {
 	if tokenType, exists := c.Get(AuthTokenTypeKey); exists {
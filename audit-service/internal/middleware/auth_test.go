@@ -4,9 +4,11 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"audit-service/internal/domain"
 	"audit-service/mocks"
 	"audit-service/pkg/cache"
 	"audit-service/pkg/jwt"
@@ -20,7 +22,8 @@ import (
 
 // Test constants
 const (
-	testUserID = "test-user-456"
+	testUserID         = "test-user-456"
+	testMaxTokenLength = 8192
 )
 
 // Helper function to create test JWT claims
@@ -71,7 +74,7 @@ func TestAuth(t *testing.T) {
 			},
 			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
 				mockRepo.On("ValidateShareToken", mock.Anything, "valid-share-token", "test-session").
-					Return(true, nil)
+					Return(true, time.Time{}, nil)
 			},
 			expectedStatus: 200,
 			expectedUserID: "",
@@ -176,7 +179,7 @@ func TestAuth(t *testing.T) {
 			},
 			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
 				mockRepo.On("ValidateShareToken", mock.Anything, "invalid-share-token", "test-session").
-					Return(false, nil)
+					Return(false, time.Time{}, nil)
 			},
 			expectedStatus: 403,
 			expectedUserID: "",
@@ -192,7 +195,7 @@ func TestAuth(t *testing.T) {
 			},
 			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
 				mockRepo.On("ValidateShareToken", mock.Anything, "error-share-token", "test-session").
-					Return(false, errors.New("database error"))
+					Return(false, time.Time{}, errors.New("database error"))
 			},
 			expectedStatus: 403,
 			expectedUserID: "",
@@ -218,7 +221,7 @@ func TestAuth(t *testing.T) {
 			// Create router and middleware
 			router := gin.New()
 			router.Use(RequestID())
-			router.Use(Auth(mockValidator, tokenCache, mockRepo, logger))
+			router.Use(Auth(mockValidator, tokenCache, mockRepo, true, testMaxTokenLength, nil, logger))
 
 			// Test endpoint
 			router.GET("/sessions/:sessionId/history", func(c *gin.Context) {
@@ -250,6 +253,207 @@ func TestAuth(t *testing.T) {
 	}
 }
 
+func TestAuth_OversizedJWT(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The validator is never consulted for an oversized token
+	mockValidator := mocks.NewMockTokenValidator(t)
+	mockRepo := mocks.NewMockAuditRepository(t)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+
+	router := gin.New()
+	router.Use(Auth(mockValidator, tokenCache, mockRepo, true, 64, nil, zap.NewNop()))
+	router.GET("/sessions/:sessionId/history", func(c *gin.Context) {
+		c.JSON(200, gin.H{"success": true})
+	})
+
+	req, _ := http.NewRequest("GET", "/sessions/test-session/history", nil)
+	req.Header.Set("Authorization", "Bearer "+strings.Repeat("a", 65))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 401, w.Code)
+	assert.Equal(t, 0, tokenCache.Stats()["items"])
+}
+
+func TestAuth_ExpiredShareToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockValidator := mocks.NewMockTokenValidator(t)
+	mockRepo := mocks.NewMockAuditRepository(t)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	mockRepo.On("ValidateShareToken", mock.Anything, "expired-share-token", "test-session").
+		Return(false, time.Time{}, domain.ErrShareTokenExpired)
+
+	router := gin.New()
+	router.Use(Auth(mockValidator, tokenCache, mockRepo, true, testMaxTokenLength, nil, zap.NewNop()))
+	router.GET("/sessions/:sessionId/history", func(c *gin.Context) {
+		c.JSON(200, gin.H{"success": true})
+	})
+
+	req, _ := http.NewRequest("GET", "/sessions/test-session/history?share_token=expired-share-token", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 403, w.Code)
+	assert.Contains(t, w.Body.String(), `"error":"share_token_expired"`)
+}
+
+func TestAuth_DeniedUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		deniedUsers    DeniedUsers
+		cached         bool
+		expectedStatus int
+	}{
+		{name: "allowed user", deniedUsers: NewDeniedUsers([]string{"other-user"}), expectedStatus: 200},
+		{name: "denied user", deniedUsers: NewDeniedUsers([]string{testUserID}), expectedStatus: 403},
+		{name: "denied user with cached token", deniedUsers: NewDeniedUsers([]string{testUserID}), cached: true, expectedStatus: 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockValidator := mocks.NewMockTokenValidator(t)
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			if tt.cached {
+				tokenCache.SetJWT("valid-jwt", &cache.CachedTokenInfo{UserID: testUserID, ExpiresAt: time.Now().Add(time.Hour)})
+			} else {
+				mockValidator.On("ValidateToken", mock.Anything, "valid-jwt").Return(createTestJWTClaims(), nil)
+			}
+
+			router := gin.New()
+			router.Use(Auth(mockValidator, tokenCache, mockRepo, true, testMaxTokenLength, tt.deniedUsers, zap.NewNop()))
+			router.GET("/sessions/:sessionId/history", func(c *gin.Context) {
+				c.JSON(200, gin.H{"success": true})
+			})
+
+			req, _ := http.NewRequest("GET", "/sessions/test-session/history", nil)
+			req.Header.Set("Authorization", "Bearer valid-jwt")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == 403 {
+				assert.Contains(t, w.Body.String(), `"error":"forbidden"`)
+			}
+		})
+	}
+}
+
+func TestAuth_SessionIDHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const (
+		pathSessionID   = "550e8400-e29b-41d4-a716-446655440000"
+		headerSessionID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	)
+
+	tests := []struct {
+		name              string
+		path              string
+		header            string
+		expectedStatus    int
+		expectedSessionID string
+	}{
+		{name: "header only", path: "/history", header: headerSessionID, expectedStatus: 200, expectedSessionID: headerSessionID},
+		{name: "path takes precedence", path: "/sessions/" + pathSessionID + "/history", header: headerSessionID, expectedStatus: 200, expectedSessionID: pathSessionID},
+		{name: "invalid header", path: "/history", header: "not-a-uuid", expectedStatus: 400},
+		{name: "neither", path: "/history", expectedStatus: 401},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockValidator := mocks.NewMockTokenValidator(t)
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			if tt.expectedSessionID != "" {
+				mockRepo.On("ValidateShareToken", mock.Anything, "share-token", tt.expectedSessionID).
+					Return(true, time.Now().Add(time.Hour), nil)
+			}
+
+			var gotSessionID string
+			handler := func(c *gin.Context) {
+				gotSessionID = GetSessionID(c)
+				c.JSON(200, gin.H{"success": true})
+			}
+			router := gin.New()
+			router.Use(Auth(mockValidator, tokenCache, mockRepo, true, testMaxTokenLength, nil, zap.NewNop()))
+			router.GET("/history", handler)
+			router.GET("/sessions/:sessionId/history", handler)
+
+			req, _ := http.NewRequest("GET", tt.path+"?share_token=share-token", nil)
+			if tt.header != "" {
+				req.Header.Set(SessionIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedSessionID, gotSessionID)
+		})
+	}
+}
+
+func TestAuth_ShareTokensDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		setupMocks     func(*mocks.MockTokenValidator)
+		expectedStatus int
+		expectedType   string
+	}{
+		{
+			name:           "share_token_rejected",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "jwt_still_accepted",
+			authHeader: "Bearer valid-jwt-token",
+			setupMocks: func(mockValidator *mocks.MockTokenValidator) {
+				mockValidator.On("ValidateToken", mock.Anything, "valid-jwt-token").
+					Return(createTestJWTClaims(), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedType:   TokenTypeJWT,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockValidator := mocks.NewMockTokenValidator(t)
+			// The repository must never be asked to validate the share token
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockValidator)
+			}
+
+			router := gin.New()
+			router.Use(Auth(mockValidator, tokenCache, mockRepo, false, testMaxTokenLength, nil, zap.NewNop()))
+			router.GET("/sessions/:sessionId/history", func(c *gin.Context) {
+				c.String(http.StatusOK, GetAuthTokenType(c))
+			})
+
+			req := httptest.NewRequest("GET", "/sessions/test-session/history?share_token=valid-share-token", nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedType != "" {
+				assert.Equal(t, tt.expectedType, w.Body.String())
+			}
+		})
+	}
+}
+
 func TestExtractBearerToken(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -286,6 +490,26 @@ func TestExtractBearerToken(t *testing.T) {
 			authHeader:    "Bearer  token123",
 			expectedToken: "token123",
 		},
+		{
+			name:          "comma_joined_schemes",
+			authHeader:    "Bearer x, Basic y",
+			expectedToken: "",
+		},
+		{
+			name:          "comma_without_spaces",
+			authHeader:    "Bearer x,Basic",
+			expectedToken: "",
+		},
+		{
+			name:          "multiple_tokens",
+			authHeader:    "Bearer token123 token456",
+			expectedToken: "",
+		},
+		{
+			name:          "scheme_without_separator",
+			authHeader:    "Bearertoken123",
+			expectedToken: "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -339,6 +563,33 @@ func TestValidateJWTToken(t *testing.T) {
 			expectedResult: false,
 			expectedUserID: "",
 		},
+		{
+			name:  "error_revoked_cached_token",
+			token: "revoked-cached-token",
+			setupMocks: func(mockValidator *mocks.MockTokenValidator, tokenCache *cache.TokenCache) {
+				tokenCache.SetJWT("revoked-cached-token", &cache.CachedTokenInfo{
+					UserID:    testUserID,
+					TokenID:   "revoked-jti",
+					ExpiresAt: time.Now().Add(1 * time.Hour),
+				})
+				tokenCache.RevokeJWT("revoked-jti", time.Time{})
+			},
+			expectedResult: false,
+			expectedUserID: "",
+		},
+		{
+			name:  "error_revoked_fresh_token",
+			token: "revoked-token",
+			setupMocks: func(mockValidator *mocks.MockTokenValidator, tokenCache *cache.TokenCache) {
+				claims := createTestJWTClaims()
+				claims.TokenID = "revoked-jti"
+				mockValidator.On("ValidateToken", mock.Anything, "revoked-token").
+					Return(claims, nil)
+				tokenCache.RevokeJWT("revoked-jti", time.Time{})
+			},
+			expectedResult: false,
+			expectedUserID: "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -382,11 +633,11 @@ func TestValidateShareToken(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	tests := []struct {
-		name           string
-		token          string
-		sessionID      string
-		setupMocks     func(*mocks.MockAuditRepository, *cache.TokenCache)
-		expectedResult bool
+		name        string
+		token       string
+		sessionID   string
+		setupMocks  func(*mocks.MockAuditRepository, *cache.TokenCache)
+		expectedErr error
 	}{
 		{
 			name:      "success_valid_token",
@@ -394,9 +645,9 @@ func TestValidateShareToken(t *testing.T) {
 			sessionID: "test-session",
 			setupMocks: func(mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
 				mockRepo.On("ValidateShareToken", mock.Anything, "valid-share-token", "test-session").
-					Return(true, nil)
+					Return(true, time.Time{}, nil)
 			},
-			expectedResult: true,
+			expectedErr: nil,
 		},
 		{
 			name:      "success_cached_token",
@@ -408,7 +659,7 @@ func TestValidateShareToken(t *testing.T) {
 					ExpiresAt: time.Now().Add(1 * time.Hour),
 				})
 			},
-			expectedResult: true,
+			expectedErr: nil,
 		},
 		{
 			name:      "error_invalid_token",
@@ -416,9 +667,19 @@ func TestValidateShareToken(t *testing.T) {
 			sessionID: "test-session",
 			setupMocks: func(mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
 				mockRepo.On("ValidateShareToken", mock.Anything, "invalid-share-token", "test-session").
-					Return(false, nil)
+					Return(false, time.Time{}, nil)
 			},
-			expectedResult: false,
+			expectedErr: domain.ErrForbidden,
+		},
+		{
+			name:      "error_expired_token",
+			token:     "expired-share-token",
+			sessionID: "test-session",
+			setupMocks: func(mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+				mockRepo.On("ValidateShareToken", mock.Anything, "expired-share-token", "test-session").
+					Return(false, time.Time{}, domain.ErrShareTokenExpired)
+			},
+			expectedErr: domain.ErrShareTokenExpired,
 		},
 		{
 			name:      "error_validation_failure",
@@ -426,9 +687,9 @@ func TestValidateShareToken(t *testing.T) {
 			sessionID: "test-session",
 			setupMocks: func(mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
 				mockRepo.On("ValidateShareToken", mock.Anything, "error-share-token", "test-session").
-					Return(false, errors.New("database error"))
+					Return(false, time.Time{}, errors.New("database error"))
 			},
-			expectedResult: false,
+			expectedErr: domain.ErrForbidden,
 		},
 	}
 
@@ -453,10 +714,10 @@ func TestValidateShareToken(t *testing.T) {
 			c.Set("request_id", "test-request-id")
 
 			// Execute
-			result := validateShareToken(c, tt.token, tt.sessionID, tokenCache, mockRepo, logger)
+			err := validateShareToken(c, tt.token, tt.sessionID, tokenCache, mockRepo, logger)
 
 			// Assert
-			assert.Equal(t, tt.expectedResult, result)
+			assert.ErrorIs(t, err, tt.expectedErr)
 
 			// Verify all expectations were met
 			mockRepo.AssertExpectations(t)
@@ -464,6 +725,31 @@ func TestValidateShareToken(t *testing.T) {
 	}
 }
 
+func TestValidateShareToken_CachesUntilTokenExpiry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockRepo := mocks.NewMockAuditRepository(t)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 5*time.Minute, 10*time.Minute)
+	expiresAt := time.Now().Add(2 * time.Minute)
+
+	mockRepo.On("ValidateShareToken", mock.Anything, "expiring-share-token", "test-session").
+		Return(true, expiresAt, nil).Once()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+
+	assert.NoError(t, validateShareToken(c, "expiring-share-token", "test-session", tokenCache, mockRepo, zap.NewNop()))
+
+	// The cached entry carries the real token expiry rather than the cache TTL
+	info, found := tokenCache.GetShareToken("expiring-share-token", "test-session")
+	assert.True(t, found)
+	assert.True(t, expiresAt.Equal(info.ExpiresAt))
+
+	// Second validation is served from cache
+	assert.NoError(t, validateShareToken(c, "expiring-share-token", "test-session", tokenCache, mockRepo, zap.NewNop()))
+}
+
 func TestGetAuthUserID(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
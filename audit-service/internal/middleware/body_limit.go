@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"audit-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimit middleware caps the request body at maxBytes. Requests that
+// declare a larger Content-Length are rejected up front; bodies without a
+// declared length fail with *http.MaxBytesError once the limit is read past.
+func BodyLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			c.JSON(http.StatusRequestEntityTooLarge, domain.APIErrPayloadTooLarge)
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"audit-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		chunked        bool
+		expectedStatus int
+	}{
+		{
+			name:           "success_within_limit",
+			body:           strings.Repeat("a", 16),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "error_declared_length_over_limit",
+			body:           strings.Repeat("a", 17),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "error_undeclared_length_over_limit",
+			body:           strings.Repeat("a", 17),
+			chunked:        true,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(BodyLimit(16))
+			router.POST("/test", func(c *gin.Context) {
+				if _, err := io.ReadAll(c.Request.Body); err != nil {
+					c.JSON(http.StatusRequestEntityTooLarge, domain.APIErrPayloadTooLarge)
+					return
+				}
+				c.JSON(http.StatusOK, gin.H{"success": true})
+			})
+
+			req, _ := http.NewRequest("POST", "/test", strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusRequestEntityTooLarge {
+				var response domain.APIError
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "payload_too_large", response.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+// DeniedUsers is the set of user IDs refused access even with a valid JWT
+type DeniedUsers map[string]struct{}
+
+// NewDeniedUsers builds the denylist from the configured user IDs
+func NewDeniedUsers(userIDs []string) DeniedUsers {
+	denied := make(DeniedUsers, len(userIDs))
+	for _, userID := range userIDs {
+		if userID != "" {
+			denied[userID] = struct{}{}
+		}
+	}
+	return denied
+}
+
+// Contains reports whether the user is denied; a nil denylist denies no one
+func (d DeniedUsers) Contains(userID string) bool {
+	_, ok := d[userID]
+	return ok
+}
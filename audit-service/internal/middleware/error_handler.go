@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 
 	"audit-service/internal/domain"
 
@@ -29,13 +31,13 @@ func ErrorHandler(logger *zap.Logger) gin.HandlerFunc {
 
 			// Check if it's already an API error
 			if apiErr, ok := err.Err.(*domain.APIError); ok {
-				c.JSON(apiErr.Status, apiErr)
+				WriteAPIError(c, apiErr)
 				return
 			}
 
 			// Convert to API error
 			apiErr := domain.ToAPIError(err.Err)
-			c.JSON(apiErr.Status, apiErr)
+			WriteAPIError(c, apiErr)
 		} else {
 			// Log server errors even when no errors in c.Errors
 			status := c.Writer.Status()
@@ -51,6 +53,14 @@ func ErrorHandler(logger *zap.Logger) gin.HandlerFunc {
 	}
 }
 
+// WriteAPIError writes an API error response, advertising Retry-After when the error carries one
+func WriteAPIError(c *gin.Context, apiErr *domain.APIError) {
+	if apiErr.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(apiErr.RetryAfter.Seconds()))))
+	}
+	c.JSON(apiErr.Status, apiErr)
+}
+
 // HandleNotFound returns a handler for 404 errors
 func HandleNotFound() gin.HandlerFunc {
 	return func(c *gin.Context) {
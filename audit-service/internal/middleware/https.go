@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"audit-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireHTTPS middleware rejects requests that did not arrive over HTTPS.
+// X-Forwarded-Proto is only honoured when the connection comes from one of
+// trustedProxies (IPs or CIDRs); otherwise the connection itself must be TLS.
+// Routes in exempt (as reported by FullPath) are let through so health checks
+// from inside the cluster keep working.
+func RequireHTTPS(trustedProxies []string, exempt ...string) gin.HandlerFunc {
+	proxies := parseProxyNets(trustedProxies)
+	exemptRoutes := make(map[string]struct{}, len(exempt))
+	for _, route := range exempt {
+		exemptRoutes[route] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		if _, ok := exemptRoutes[c.FullPath()]; ok {
+			c.Next()
+			return
+		}
+
+		if !isHTTPS(c, proxies) {
+			c.JSON(http.StatusBadRequest, domain.APIErrHTTPSRequired)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// isHTTPS reports whether the request reached us, or the trusted proxy in front of us, over HTTPS
+func isHTTPS(c *gin.Context, proxies []*net.IPNet) bool {
+	if remote := net.ParseIP(c.RemoteIP()); remote != nil && containsIP(proxies, remote) {
+		if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+			// A chain of proxies appends its own scheme; the first one is what the client used
+			first, _, _ := strings.Cut(proto, ",")
+			return strings.EqualFold(strings.TrimSpace(first), "https")
+		}
+	}
+	return c.Request.TLS != nil
+}
+
+// parseProxyNets turns IPs and CIDRs into networks, skipping entries that are
+// neither (config validation rejects those before we get here)
+func parseProxyNets(proxies []string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if ip := net.ParseIP(proxy); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(proxy); err == nil {
+			nets = append(nets, ipNet)
+		}
+	}
+	return nets
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
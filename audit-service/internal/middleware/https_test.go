@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireHTTPS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		path           string
+		remoteAddr     string
+		forwardedProto string
+		tls            bool
+		expectedStatus int
+	}{
+		{
+			name:           "success_forwarded_https_from_trusted_proxy",
+			path:           "/api",
+			remoteAddr:     "10.0.0.5:4000",
+			forwardedProto: "https",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "success_forwarded_chain_uses_client_scheme",
+			path:           "/api",
+			remoteAddr:     "10.0.0.5:4000",
+			forwardedProto: "HTTPS, http",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "success_direct_tls",
+			path:           "/api",
+			remoteAddr:     "203.0.113.7:4000",
+			tls:            true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "success_health_exempt",
+			path:           "/health",
+			remoteAddr:     "10.0.0.5:4000",
+			forwardedProto: "http",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "error_forwarded_http_from_trusted_proxy",
+			path:           "/api",
+			remoteAddr:     "10.0.0.5:4000",
+			forwardedProto: "http",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "error_forwarded_https_from_untrusted_client",
+			path:           "/api",
+			remoteAddr:     "203.0.113.7:4000",
+			forwardedProto: "https",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "error_plain_http",
+			path:           "/api",
+			remoteAddr:     "203.0.113.7:4000",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RequireHTTPS([]string{"10.0.0.0/8", "192.168.1.1"}, "/health"))
+			router.GET("/api", func(c *gin.Context) { c.Status(http.StatusOK) })
+			router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedProto != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.forwardedProto)
+			}
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusBadRequest {
+				assert.Contains(t, w.Body.String(), "https_required")
+			}
+		})
+	}
+}
@@ -1,14 +1,17 @@
 package middleware
 
 import (
+	"net"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-// Logger returns a gin middleware for structured logging
-func Logger(logger *zap.Logger) gin.HandlerFunc {
+// Logger returns a gin middleware for structured logging. Requests slower
+// than slowThreshold are flagged with slow=true and logged at least at Warn;
+// a zero threshold disables slow request detection.
+func Logger(logger *zap.Logger, slowThreshold time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Start timer
 		start := time.Now()
@@ -20,7 +23,7 @@ func Logger(logger *zap.Logger) gin.HandlerFunc {
 
 		// Log only after request is processed
 		latency := time.Since(start)
-		clientIP := c.ClientIP()
+		clientIP := clientIP(c)
 		method := c.Request.Method
 		statusCode := c.Writer.Status()
 		errorMessage := c.Errors.ByType(gin.ErrorTypePrivate).String()
@@ -47,12 +50,19 @@ func Logger(logger *zap.Logger) gin.HandlerFunc {
 			fields = append(fields, zap.String("error", errorMessage))
 		}
 
+		slow := slowThreshold > 0 && latency > slowThreshold
+		if slow {
+			fields = append(fields, zap.Bool("slow", true))
+		}
+
 		// Log based on status code
 		switch {
 		case statusCode >= 500:
 			logger.Error("server error", fields...)
 		case statusCode >= 400:
 			logger.Warn("client error", fields...)
+		case slow:
+			logger.Warn("slow request", fields...)
 		case statusCode >= 300:
 			logger.Info("redirection", fields...)
 		default:
@@ -60,3 +70,17 @@ func Logger(logger *zap.Logger) gin.HandlerFunc {
 		}
 	}
 }
+
+// clientIP returns the client IP, falling back to the host portion of
+// RemoteAddr when gin cannot determine it (e.g. behind some proxies)
+func clientIP(c *gin.Context) string {
+	if ip := c.ClientIP(); ip != "" {
+		return ip
+	}
+
+	host, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+	if err != nil {
+		return c.Request.RemoteAddr
+	}
+	return host
+}
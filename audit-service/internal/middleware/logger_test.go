@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -129,7 +130,7 @@ func TestLogger(t *testing.T) {
 			// Setup router with middleware
 			router := gin.New()
 			router.Use(RequestID()) // RequestID middleware needed for logger
-			router.Use(Logger(logger))
+			router.Use(Logger(logger, 0))
 
 			// Test endpoint
 			router.Any("/*path", tt.setupHandler)
@@ -186,7 +187,7 @@ func TestLogger_WithVariousStatusCodes(t *testing.T) {
 			// Setup router
 			router := gin.New()
 			router.Use(RequestID())
-			router.Use(Logger(logger))
+			router.Use(Logger(logger, 0))
 
 			router.GET("/test", func(c *gin.Context) {
 				c.JSON(statusCode, gin.H{"status": statusCode})
@@ -207,6 +208,58 @@ func TestLogger_WithVariousStatusCodes(t *testing.T) {
 	}
 }
 
+func TestLogger_ClientIPFallback(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Setup logger with buffer
+	var logBuffer bytes.Buffer
+	encoder := zapcore.NewJSONEncoder(zap.NewDevelopmentEncoderConfig())
+	core := zapcore.NewCore(encoder, zapcore.AddSync(&logBuffer), zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	router := gin.New()
+	router.Use(RequestID())
+	router.Use(Logger(logger, 0))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(200, gin.H{"success": true})
+	})
+
+	// A RemoteAddr without a port makes gin's ClientIP return ""
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.RemoteAddr = "10.1.2.3"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var logEntry map[string]interface{}
+	err := json.Unmarshal(bytes.Split(logBuffer.Bytes(), []byte("\n"))[0], &logEntry)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.1.2.3", logEntry["ip"])
+}
+
+func TestClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		expectedIP string
+	}{
+		{name: "host_and_port", remoteAddr: "192.168.1.10:54321", expectedIP: "192.168.1.10"},
+		{name: "host_only", remoteAddr: "192.168.1.10", expectedIP: "192.168.1.10"},
+		{name: "ipv6_without_port", remoteAddr: "::1", expectedIP: "::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request, _ = http.NewRequest("GET", "/", nil)
+			c.Request.RemoteAddr = tt.remoteAddr
+
+			assert.Equal(t, tt.expectedIP, clientIP(c))
+		})
+	}
+}
+
 func TestLogger_Performance(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -216,7 +269,7 @@ func TestLogger_Performance(t *testing.T) {
 	// Setup router
 	router := gin.New()
 	router.Use(RequestID())
-	router.Use(Logger(logger))
+	router.Use(Logger(logger, 0))
 
 	router.GET("/test", func(c *gin.Context) {
 		c.JSON(200, gin.H{"success": true})
@@ -230,3 +283,46 @@ func TestLogger_Performance(t *testing.T) {
 		assert.Equal(t, 200, w.Code)
 	}
 }
+
+func TestLogger_SlowRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		delay         time.Duration
+		statusCode    int
+		expectedLevel string
+		expectSlow    bool
+	}{
+		{name: "fast_request", delay: 0, statusCode: 200, expectedLevel: "INFO", expectSlow: false},
+		{name: "slow_success", delay: 30 * time.Millisecond, statusCode: 200, expectedLevel: "WARN", expectSlow: true},
+		{name: "slow_server_error", delay: 30 * time.Millisecond, statusCode: 500, expectedLevel: "ERROR", expectSlow: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logBuffer bytes.Buffer
+			encoder := zapcore.NewJSONEncoder(zap.NewDevelopmentEncoderConfig())
+			core := zapcore.NewCore(encoder, zapcore.AddSync(&logBuffer), zapcore.DebugLevel)
+
+			router := gin.New()
+			router.Use(Logger(zap.New(core), 20*time.Millisecond))
+			router.GET("/test", func(c *gin.Context) {
+				time.Sleep(tt.delay)
+				c.Status(tt.statusCode)
+			})
+
+			req, _ := http.NewRequest("GET", "/test", nil)
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			var logEntry map[string]interface{}
+			assert.NoError(t, json.Unmarshal(bytes.Split(logBuffer.Bytes(), []byte("\n"))[0], &logEntry))
+			assert.Equal(t, tt.expectedLevel, logEntry["L"])
+			if tt.expectSlow {
+				assert.Equal(t, true, logEntry["slow"])
+			} else {
+				assert.NotContains(t, logEntry, "slow")
+			}
+		})
+	}
+}
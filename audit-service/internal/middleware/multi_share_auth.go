@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/repository"
+	"audit-service/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	AuthSessionGrantsKey = "auth_session_grants"
+
+	// ShareTokenHeader carries one share token per session as an alternative to share_token params
+	ShareTokenHeader = "X-Share-Token"
+)
+
+// SessionGrant is the outcome of validating the share token given for one session
+type SessionGrant struct {
+	SessionID string
+	// Fingerprint identifies the session's share token without revealing it
+	Fingerprint string
+	// Err is nil when the share token is valid for the session
+	Err error
+}
+
+// MultiShareAuth middleware authenticates multi-session reads. The sessions
+// query parameter lists up to maxSessions session IDs and the share tokens
+// (repeated share_token params, or repeated X-Share-Token headers) pair with
+// them by position. Each token is only ever checked against its own session.
+// Requests where no token is valid are refused; otherwise the per-session
+// outcomes are stored for the handler.
+func MultiShareAuth(tokenCache *cache.TokenCache, repo repository.AuditRepository, maxSessions int, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionIDs, problem := parseSessionIDs(c.Query("sessions"), maxSessions)
+		if problem != "" {
+			c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", problem, http.StatusBadRequest))
+			c.Abort()
+			return
+		}
+
+		tokens := c.QueryArray("share_token")
+		if len(tokens) == 0 {
+			tokens = c.Request.Header.Values(ShareTokenHeader)
+		}
+		if len(tokens) == 0 {
+			c.JSON(http.StatusUnauthorized, domain.APIErrUnauthorized)
+			c.Abort()
+			return
+		}
+		if len(tokens) != len(sessionIDs) {
+			c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request",
+				fmt.Sprintf("Expected one share token per session, got %d tokens for %d sessions", len(tokens), len(sessionIDs)),
+				http.StatusBadRequest))
+			c.Abort()
+			return
+		}
+
+		for i := range tokens {
+			tokens[i] = strings.TrimSpace(tokens[i])
+		}
+		warmShareTokenCache(c, tokens, sessionIDs, tokenCache, repo, logger)
+
+		grants := make([]SessionGrant, len(sessionIDs))
+		var firstErr error
+		granted := 0
+		for i, sessionID := range sessionIDs {
+			err := validateShareToken(c, tokens[i], sessionID, tokenCache, repo, logger)
+			grants[i] = SessionGrant{SessionID: sessionID, Fingerprint: tokenFingerprint(tokens[i]), Err: err}
+			if err == nil {
+				granted++
+			} else if firstErr == nil {
+				firstErr = err
+			}
+		}
+
+		if granted == 0 {
+			WriteAPIError(c, domain.ToAPIError(firstErr))
+			c.Abort()
+			return
+		}
+
+		c.Set(AuthTokenTypeKey, TokenTypeShare)
+		c.Set(AuthSessionGrantsKey, grants)
+		c.Next()
+	}
+}
+
+// warmShareTokenCache validates a token given for several uncached sessions
+// in one round-trip and caches the sessions it is valid for, so dashboards
+// reusing a token don't validate it session by session. Failures are left
+// for the per-session validation to report.
+func warmShareTokenCache(c *gin.Context, tokens, sessionIDs []string, tokenCache *cache.TokenCache, repo repository.AuditRepository, logger *zap.Logger) {
+	uncached := make(map[string][]string)
+	for i, sessionID := range sessionIDs {
+		if _, found := tokenCache.GetShareToken(tokens[i], sessionID); !found {
+			uncached[tokens[i]] = append(uncached[tokens[i]], sessionID)
+		}
+	}
+
+	for token, ids := range uncached {
+		if len(ids) < 2 {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		valid, err := repo.ValidateShareTokens(ctx, token, ids)
+		cancel()
+		if err != nil {
+			logger.Warn("bulk share token validation failed",
+				zap.String("request_id", GetRequestID(c)),
+				zap.Int("sessions", len(ids)),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		for sessionID, expiresAt := range valid {
+			tokenCache.SetShareToken(token, sessionID, &cache.CachedTokenInfo{
+				SessionID: sessionID,
+				ExpiresAt: expiresAt,
+			})
+		}
+	}
+}
+
+// parseSessionIDs splits the comma-separated sessions parameter, requiring
+// between one and maxSessions distinct UUIDs. A non-empty problem describes
+// why the parameter was rejected.
+func parseSessionIDs(raw string, maxSessions int) (sessionIDs []string, problem string) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, "The sessions parameter is required"
+	}
+
+	seen := make(map[string]bool)
+	for _, sessionID := range strings.Split(raw, ",") {
+		sessionID = strings.TrimSpace(sessionID)
+		if !domain.IsValidUUID(sessionID) {
+			return nil, fmt.Sprintf("Invalid session ID %q", sessionID)
+		}
+		if seen[sessionID] {
+			return nil, fmt.Sprintf("Session %s is listed more than once", sessionID)
+		}
+		seen[sessionID] = true
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+
+	if len(sessionIDs) > maxSessions {
+		return nil, fmt.Sprintf("At most %d sessions may be requested at once", maxSessions)
+	}
+	return sessionIDs, ""
+}
+
+// GetSessionGrants returns the per-session share token outcomes set by MultiShareAuth
+func GetSessionGrants(c *gin.Context) []SessionGrant {
+	if grants, exists := c.Get(AuthSessionGrantsKey); exists {
+		if g, ok := grants.([]SessionGrant); ok {
+			return g
+		}
+	}
+	return nil
+}
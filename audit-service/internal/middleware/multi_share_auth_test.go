@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/mocks"
+	"audit-service/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestMultiShareAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const (
+		sessionA = "550e8400-e29b-41d4-a716-446655440000"
+		sessionB = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	)
+
+	tests := []struct {
+		name           string
+		query          string
+		headerTokens   []string
+		setupMocks     func(*mocks.MockAuditRepository)
+		expectedStatus int
+		expectedGrants map[string]bool
+	}{
+		{
+			name:  "all tokens valid",
+			query: "sessions=" + sessionA + "," + sessionB + "&share_token=token-a&share_token=token-b",
+			setupMocks: func(repo *mocks.MockAuditRepository) {
+				repo.On("ValidateShareToken", mock.Anything, "token-a", sessionA).Return(true, time.Now().Add(time.Hour), nil)
+				repo.On("ValidateShareToken", mock.Anything, "token-b", sessionB).Return(true, time.Now().Add(time.Hour), nil)
+			},
+			expectedStatus: 200,
+			expectedGrants: map[string]bool{sessionA: true, sessionB: true},
+		},
+		{
+			name:  "mixed valid and invalid tokens",
+			query: "sessions=" + sessionA + "," + sessionB + "&share_token=token-a&share_token=bad-token",
+			setupMocks: func(repo *mocks.MockAuditRepository) {
+				repo.On("ValidateShareToken", mock.Anything, "token-a", sessionA).Return(true, time.Now().Add(time.Hour), nil)
+				repo.On("ValidateShareToken", mock.Anything, "bad-token", sessionB).Return(false, time.Time{}, nil)
+			},
+			expectedStatus: 200,
+			expectedGrants: map[string]bool{sessionA: true, sessionB: false},
+		},
+		{
+			name:         "tokens from headers",
+			query:        "sessions=" + sessionA + "," + sessionB,
+			headerTokens: []string{"token-a", "token-b"},
+			setupMocks: func(repo *mocks.MockAuditRepository) {
+				repo.On("ValidateShareToken", mock.Anything, "token-a", sessionA).Return(true, time.Now().Add(time.Hour), nil)
+				repo.On("ValidateShareToken", mock.Anything, "token-b", sessionB).Return(true, time.Now().Add(time.Hour), nil)
+			},
+			expectedStatus: 200,
+			expectedGrants: map[string]bool{sessionA: true, sessionB: true},
+		},
+		{
+			name:  "no valid tokens",
+			query: "sessions=" + sessionA + "," + sessionB + "&share_token=token-b&share_token=token-a",
+			setupMocks: func(repo *mocks.MockAuditRepository) {
+				repo.On("ValidateShareToken", mock.Anything, "token-b", sessionA).Return(false, time.Time{}, nil)
+				repo.On("ValidateShareToken", mock.Anything, "token-a", sessionB).Return(false, time.Time{}, nil)
+			},
+			expectedStatus: 403,
+		},
+		{
+			name:  "shared token validated in one query",
+			query: "sessions=" + sessionA + "," + sessionB + "&share_token=token-x&share_token=token-x",
+			setupMocks: func(repo *mocks.MockAuditRepository) {
+				repo.On("ValidateShareTokens", mock.Anything, "token-x", []string{sessionA, sessionB}).
+					Return(map[string]time.Time{sessionA: time.Now().Add(time.Hour), sessionB: {}}, nil).Once()
+			},
+			expectedStatus: 200,
+			expectedGrants: map[string]bool{sessionA: true, sessionB: true},
+		},
+		{
+			name:  "shared token valid for some sessions",
+			query: "sessions=" + sessionA + "," + sessionB + "&share_token=token-x&share_token=token-x",
+			setupMocks: func(repo *mocks.MockAuditRepository) {
+				repo.On("ValidateShareTokens", mock.Anything, "token-x", []string{sessionA, sessionB}).
+					Return(map[string]time.Time{sessionA: time.Now().Add(time.Hour)}, nil).Once()
+				repo.On("ValidateShareToken", mock.Anything, "token-x", sessionB).Return(false, time.Time{}, domain.ErrShareTokenExpired)
+			},
+			expectedStatus: 200,
+			expectedGrants: map[string]bool{sessionA: true, sessionB: false},
+		},
+		{
+			name:  "bulk validation failure falls back per session",
+			query: "sessions=" + sessionA + "," + sessionB + "&share_token=token-x&share_token=token-x",
+			setupMocks: func(repo *mocks.MockAuditRepository) {
+				repo.On("ValidateShareTokens", mock.Anything, "token-x", []string{sessionA, sessionB}).
+					Return(nil, errors.New("supabase unavailable")).Once()
+				repo.On("ValidateShareToken", mock.Anything, "token-x", sessionA).Return(true, time.Now().Add(time.Hour), nil)
+				repo.On("ValidateShareToken", mock.Anything, "token-x", sessionB).Return(true, time.Now().Add(time.Hour), nil)
+			},
+			expectedStatus: 200,
+			expectedGrants: map[string]bool{sessionA: true, sessionB: true},
+		},
+		{
+			name:           "token count mismatch",
+			query:          "sessions=" + sessionA + "," + sessionB + "&share_token=token-a",
+			expectedStatus: 400,
+		},
+		{
+			name:           "no tokens",
+			query:          "sessions=" + sessionA,
+			expectedStatus: 401,
+		},
+		{
+			name:           "missing sessions",
+			query:          "share_token=token-a",
+			expectedStatus: 400,
+		},
+		{
+			name:           "invalid session ID",
+			query:          "sessions=not-a-uuid&share_token=token-a",
+			expectedStatus: 400,
+		},
+		{
+			name:           "duplicate session",
+			query:          "sessions=" + sessionA + "," + sessionA + "&share_token=token-a&share_token=token-a",
+			expectedStatus: 400,
+		},
+		{
+			name:           "too many sessions",
+			query:          "sessions=" + strings.Repeat(sessionA+",", 2) + sessionB + "&share_token=a&share_token=b&share_token=c",
+			expectedStatus: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockRepo)
+			}
+
+			var grants []SessionGrant
+			router := gin.New()
+			router.Use(MultiShareAuth(tokenCache, mockRepo, 2, zap.NewNop()))
+			router.GET("/history/batch", func(c *gin.Context) {
+				grants = GetSessionGrants(c)
+				c.JSON(200, gin.H{"success": true})
+			})
+
+			req, _ := http.NewRequest("GET", "/history/batch?"+tt.query, nil)
+			for _, token := range tt.headerTokens {
+				req.Header.Add(ShareTokenHeader, token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedGrants == nil {
+				assert.Nil(t, grants)
+				return
+			}
+			assert.Len(t, grants, len(tt.expectedGrants))
+			for _, grant := range grants {
+				assert.Equal(t, tt.expectedGrants[grant.SessionID], grant.Err == nil, grant.SessionID)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import "sort"
+
+// PublicRoutes records the route patterns that are served without authentication
+type PublicRoutes struct {
+	patterns map[string]struct{}
+}
+
+// NewPublicRoutes creates an empty public route registry
+func NewPublicRoutes() *PublicRoutes {
+	return &PublicRoutes{patterns: make(map[string]struct{})}
+}
+
+// Add registers route patterns as gin reports them from FullPath, e.g. /docs/*any
+func (p *PublicRoutes) Add(patterns ...string) {
+	for _, pattern := range patterns {
+		p.patterns[pattern] = struct{}{}
+	}
+}
+
+// Patterns returns the registered route patterns in sorted order
+func (p *PublicRoutes) Patterns() []string {
+	patterns := make([]string, 0, len(p.patterns))
+	for pattern := range p.patterns {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	return patterns
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicRoutes_Patterns(t *testing.T) {
+	public := NewPublicRoutes()
+	public.Add("/health", "/docs/*any")
+	// Registering a route twice records it once
+	public.Add("/health")
+
+	assert.Equal(t, []string{"/docs/*any", "/health"}, public.Patterns())
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"syscall"
+
+	"audit-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Recovery middleware turns a panic into a 500 and logs it with its stack and
+// request ID. The stack is only echoed in the response body when
+// includeStack is set, which should never be the case in production.
+func Recovery(logger *zap.Logger, includeStack bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			// The client went away; there is nobody to answer
+			if err, ok := recovered.(error); ok && isBrokenConnection(err) {
+				logger.Warn("connection closed during request",
+					zap.String("request_id", GetRequestID(c)),
+					zap.String("path", c.Request.URL.Path),
+					zap.Error(err),
+				)
+				c.Abort()
+				return
+			}
+
+			stack := string(debug.Stack())
+			logger.Error("panic recovered",
+				zap.String("request_id", GetRequestID(c)),
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+				zap.Any("panic", recovered),
+				zap.String("stack", stack),
+			)
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+
+			// A copy, so clients see exactly the 500 every other failure produces
+			apiErr := *domain.APIErrInternalServer
+			if includeStack {
+				apiErr.Details = map[string]string{
+					"panic": fmt.Sprint(recovered),
+					"stack": stack,
+				}
+			}
+			c.AbortWithStatusJSON(apiErr.Status, apiErr)
+		}()
+
+		c.Next()
+	}
+}
+
+// isBrokenConnection reports whether a panic came from writing to a client
+// that has disconnected
+func isBrokenConnection(err error) bool {
+	return errors.Is(err, http.ErrAbortHandler) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"audit-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRecovery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		includeStack bool
+	}{
+		{name: "production_hides_stack", includeStack: false},
+		{name: "debug_includes_stack", includeStack: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.ErrorLevel)
+			router := gin.New()
+			router.Use(Recovery(zap.New(core), tt.includeStack), RequestID())
+			router.GET("/test", func(c *gin.Context) {
+				panic("test panic")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set(RequestIDKey, "req-123")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+			var body struct {
+				Error   string            `json:"error"`
+				Details map[string]string `json:"details"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, "internal_server_error", body.Error)
+			if tt.includeStack {
+				assert.Equal(t, "test panic", body.Details["panic"])
+				assert.Contains(t, body.Details["stack"], "recovery_test.go")
+			} else {
+				assert.Nil(t, body.Details)
+				assert.NotContains(t, w.Body.String(), "test panic")
+			}
+
+			// The stack is always logged, with the request ID to find it by
+			require.Equal(t, 1, logs.Len())
+			fields := logs.All()[0].ContextMap()
+			assert.Equal(t, "req-123", fields["request_id"])
+			assert.Contains(t, fields["stack"], "recovery_test.go")
+		})
+	}
+}
+
+func TestRecovery_StandardErrorBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Recovery(zap.NewNop(), false), RequestID())
+	router.GET("/panic", func(c *gin.Context) {
+		panic(errors.New("nil map write"))
+	})
+	router.GET("/error", func(c *gin.Context) {
+		WriteAPIError(c, domain.ToAPIError(domain.ErrInternalServer))
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(RequestIDKey, "req-456")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// A panic is indistinguishable from any other internal error
+	panicked, failed := serve("/panic"), serve("/error")
+	expected, err := json.Marshal(domain.APIErrInternalServer)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, panicked.Code)
+	assert.JSONEq(t, string(expected), panicked.Body.String())
+	assert.Equal(t, failed.Body.String(), panicked.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", panicked.Header().Get("Content-Type"))
+	assert.Equal(t, "req-456", panicked.Header().Get(RequestIDKey))
+
+	// The shared error value is not modified along the way
+	assert.Nil(t, domain.APIErrInternalServer.Details)
+}
+
+func TestRecovery_BrokenConnection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	core, logs := observer.New(zap.ErrorLevel)
+	router := gin.New()
+	router.Use(Recovery(zap.New(core), true))
+	router.GET("/test", func(c *gin.Context) {
+		panic(http.ErrAbortHandler)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, 0, logs.Len())
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+
+	"audit-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireJSON middleware rejects mutating requests whose Content-Type is not
+// application/json. GET and HEAD requests pass through unchecked.
+func RequireJSON() gin.HandlerFunc {
+	return requireMediaType("application/json", domain.APIErrUnsupportedMediaType)
+}
+
+// RequireNDJSON middleware is RequireJSON for newline-delimited JSON uploads
+func RequireNDJSON() gin.HandlerFunc {
+	return requireMediaType("application/x-ndjson", domain.NewAPIError("unsupported_media_type", "Content-Type must be application/x-ndjson", http.StatusUnsupportedMediaType))
+}
+
+func requireMediaType(want string, apiErr *domain.APIError) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+		if err != nil || mediaType != want {
+			c.JSON(http.StatusUnsupportedMediaType, apiErr)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
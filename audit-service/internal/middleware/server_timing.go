@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"time"
+
+	"audit-service/pkg/timing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServerTiming middleware reports time spent in Supabase and in total as a
+// Server-Timing header, for browser devtools. It exposes internal timings, so
+// it is only installed when enabled in config.
+func ServerTiming() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recorder := timing.NewRecorder()
+		c.Request = c.Request.WithContext(timing.NewContext(c.Request.Context(), recorder))
+		c.Writer = &serverTimingWriter{ResponseWriter: c.Writer, recorder: recorder, start: time.Now()}
+		c.Next()
+	}
+}
+
+// serverTimingWriter adds the Server-Timing header just before the response
+// headers are sent, since handlers write the body before the middleware resumes.
+// It is also set when the status is chosen, because gin flushes the headers of
+// bodyless responses through its own writer rather than c.Writer.
+type serverTimingWriter struct {
+	gin.ResponseWriter
+	recorder *timing.Recorder
+	start    time.Time
+}
+
+func (w *serverTimingWriter) setHeader() {
+	if !w.Written() {
+		w.Header().Set("Server-Timing", w.recorder.Header(time.Since(w.start)))
+	}
+}
+
+func (w *serverTimingWriter) WriteHeader(code int) {
+	w.setHeader()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *serverTimingWriter) WriteHeaderNow() {
+	w.setHeader()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *serverTimingWriter) Write(data []byte) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *serverTimingWriter) WriteString(s string) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"audit-service/pkg/timing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerTiming(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(ServerTiming())
+	router.GET("/history", func(c *gin.Context) {
+		// Stands in for a Supabase call made with the request context
+		timing.Record(c.Request.Context(), "supabase", time.Now().Add(-12*time.Millisecond))
+		c.JSON(http.StatusOK, gin.H{"items": []string{}})
+	})
+	router.GET("/empty", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/history", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Regexp(t, `^supabase;dur=1\d\.\d, total;dur=\d+\.\d$`, w.Header().Get("Server-Timing"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/empty", nil))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Regexp(t, `^total;dur=\d+\.\d$`, w.Header().Get("Server-Timing"))
+}
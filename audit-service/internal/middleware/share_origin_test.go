@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestShareOrigin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		allowed        []string
+		tokenType      string
+		origin         string
+		referer        string
+		expectedStatus int
+	}{
+		{name: "allowed_origin", allowed: []string{"https://app.example.com"}, tokenType: TokenTypeShare, origin: "https://app.example.com", expectedStatus: http.StatusOK},
+		{name: "allowed_origin_case_insensitive", allowed: []string{"https://app.example.com"}, tokenType: TokenTypeShare, origin: "https://APP.example.com", expectedStatus: http.StatusOK},
+		{name: "allowed_referer", allowed: []string{"https://app.example.com"}, tokenType: TokenTypeShare, referer: "https://app.example.com/decks/42?tab=history", expectedStatus: http.StatusOK},
+		{name: "null_origin_falls_back_to_referer", allowed: []string{"https://app.example.com"}, tokenType: TokenTypeShare, origin: "null", referer: "https://app.example.com/embed", expectedStatus: http.StatusOK},
+		{name: "disallowed_origin", allowed: []string{"https://app.example.com"}, tokenType: TokenTypeShare, origin: "https://evil.example.net", expectedStatus: http.StatusForbidden},
+		{name: "origin_wins_over_referer", allowed: []string{"https://app.example.com"}, tokenType: TokenTypeShare, origin: "https://evil.example.net", referer: "https://app.example.com/", expectedStatus: http.StatusForbidden},
+		{name: "other_port", allowed: []string{"https://app.example.com"}, tokenType: TokenTypeShare, origin: "https://app.example.com:8443", expectedStatus: http.StatusForbidden},
+		{name: "other_scheme", allowed: []string{"https://app.example.com"}, tokenType: TokenTypeShare, origin: "http://app.example.com", expectedStatus: http.StatusForbidden},
+		{name: "no_origin_or_referer", allowed: []string{"https://app.example.com"}, tokenType: TokenTypeShare, expectedStatus: http.StatusForbidden},
+		{name: "jwt_not_checked", allowed: []string{"https://app.example.com"}, tokenType: TokenTypeJWT, origin: "https://evil.example.net", expectedStatus: http.StatusOK},
+		{name: "empty_allowlist", tokenType: TokenTypeShare, origin: "https://evil.example.net", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/history", func(c *gin.Context) {
+				c.Set(AuthTokenTypeKey, tt.tokenType)
+				c.Next()
+			}, ShareOrigin(tt.allowed, zap.NewNop()), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/history", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.referer != "" {
+				req.Header.Set("Referer", tt.referer)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusForbidden {
+				assert.Contains(t, w.Body.String(), `"error":"forbidden"`)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RowsServedKey is the context key handlers set to the number of rows they returned
+const RowsServedKey = "rows_served"
+
+// SessionRowsServedKey is the context key multi-session handlers set to the
+// rows they returned per session
+const SessionRowsServedKey = "session_rows_served"
+
+// SetRowsServed records how many rows the response carries, for ShareRowLimit
+func SetRowsServed(c *gin.Context, rows int) {
+	c.Set(RowsServedKey, rows)
+}
+
+// SetSessionRowsServed records how many rows a multi-session response carries
+// for one session, so ShareRowLimit can charge them to that session's token
+func SetSessionRowsServed(c *gin.Context, sessionID string, rows int) {
+	served, _ := c.Get(SessionRowsServedKey)
+	perSession, ok := served.(map[string]int)
+	if !ok {
+		perSession = make(map[string]int)
+		c.Set(SessionRowsServedKey, perSession)
+	}
+	perSession[sessionID] += rows
+}
+
+// ShareRowLimit middleware caps the rows a single share token can read within
+// the quota's window, answering 429 once the cap is reached. The request that
+// crosses the cap is still served in full. On multi-session reads each
+// session's rows are charged to the token given for it, and the request is
+// refused if any of those tokens is used up. JWT callers are not limited.
+func ShareRowLimit(quota *cache.RowQuota, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if GetAuthTokenType(c) != TokenTypeShare {
+			c.Next()
+			return
+		}
+
+		grants := GetSessionGrants(c)
+		fingerprints := []string{GetShareTokenFingerprint(c)}
+		if grants != nil {
+			fingerprints = fingerprints[:0]
+			for _, grant := range grants {
+				if grant.Err == nil {
+					fingerprints = append(fingerprints, grant.Fingerprint)
+				}
+			}
+		}
+
+		for _, fingerprint := range fingerprints {
+			if retryAfter, exceeded := quota.Exceeded(fingerprint); exceeded {
+				logger.Warn("share token row limit reached",
+					zap.String("request_id", GetRequestID(c)),
+					zap.String("session_id", GetSessionID(c)),
+					zap.String("share_token_fingerprint", fingerprint),
+				)
+				apiErr := domain.NewAPIError(domain.APIErrTooManyRequests.Code, domain.APIErrTooManyRequests.Message, domain.APIErrTooManyRequests.Status)
+				apiErr.RetryAfter = retryAfter
+				WriteAPIError(c, apiErr)
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+
+		if c.Writer.Status() != http.StatusOK {
+			return
+		}
+		if grants == nil {
+			quota.Add(fingerprints[0], c.GetInt(RowsServedKey))
+			return
+		}
+		served, _ := c.Get(SessionRowsServedKey)
+		perSession, _ := served.(map[string]int)
+		for _, grant := range grants {
+			if grant.Err == nil {
+				quota.Add(grant.Fingerprint, perSession[grant.SessionID])
+			}
+		}
+	}
+}
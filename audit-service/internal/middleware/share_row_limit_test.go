@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"audit-service/mocks"
+	"audit-service/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestShareRowLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	quota := cache.NewRowQuota(5, time.Minute, 10*time.Minute)
+	router := gin.New()
+	router.GET("/history", func(c *gin.Context) {
+		c.Set(AuthTokenTypeKey, c.GetHeader("X-Token-Type"))
+		c.Set(AuthShareFingerprintKey, c.GetHeader("X-Fingerprint"))
+		c.Next()
+	}, ShareRowLimit(quota, zap.NewNop()), func(c *gin.Context) {
+		SetRowsServed(c, 3)
+		c.Status(http.StatusOK)
+	})
+
+	read := func(tokenType, fingerprint string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/history", nil)
+		req.Header.Set("X-Token-Type", tokenType)
+		req.Header.Set("X-Fingerprint", fingerprint)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// 3 rows, then 6: the read crossing the cap is still served
+	assert.Equal(t, http.StatusOK, read(TokenTypeShare, "token-a").Code)
+	assert.Equal(t, http.StatusOK, read(TokenTypeShare, "token-a").Code)
+
+	w := read(TokenTypeShare, "token-a")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "too_many_requests")
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// Other tokens and JWT callers are unaffected
+	assert.Equal(t, http.StatusOK, read(TokenTypeShare, "token-b").Code)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, read(TokenTypeJWT, "").Code)
+	}
+}
+
+func TestShareRowLimit_Batch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const (
+		sessionA = "550e8400-e29b-41d4-a716-446655440000"
+		sessionB = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	)
+
+	// Both tokens are cached, so the repository is never asked
+	tokenCache := cache.NewTokenCache(5*time.Minute, time.Minute, 10*time.Minute)
+	tokenCache.SetShareToken("token-a", sessionA, &cache.CachedTokenInfo{SessionID: sessionA})
+	tokenCache.SetShareToken("token-b", sessionB, &cache.CachedTokenInfo{SessionID: sessionB})
+	repo := mocks.NewMockAuditRepository(t)
+
+	quota := cache.NewRowQuota(5, time.Minute, 10*time.Minute)
+	router := gin.New()
+	router.GET("/history/batch", MultiShareAuth(tokenCache, repo, 10, zap.NewNop()), ShareRowLimit(quota, zap.NewNop()), func(c *gin.Context) {
+		SetSessionRowsServed(c, sessionA, 3)
+		SetSessionRowsServed(c, sessionB, 1)
+		c.Status(http.StatusOK)
+	})
+	router.GET("/history", func(c *gin.Context) {
+		c.Set(AuthTokenTypeKey, TokenTypeShare)
+		c.Set(AuthShareFingerprintKey, tokenFingerprint("token-b"))
+		c.Next()
+	}, ShareRowLimit(quota, zap.NewNop()), func(c *gin.Context) {
+		SetRowsServed(c, 1)
+		c.Status(http.StatusOK)
+	})
+
+	read := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	batch := "/history/batch?sessions=" + sessionA + "," + sessionB + "&share_token=token-a&share_token=token-b"
+
+	// token-a is charged 3 rows per batch and crosses the cap on the second
+	assert.Equal(t, http.StatusOK, read(batch).Code)
+	assert.Equal(t, http.StatusOK, read(batch).Code)
+
+	w := read(batch)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "too_many_requests")
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// token-b was only charged its own session's rows, so it can still read
+	assert.Equal(t, http.StatusOK, read("/history").Code)
+}
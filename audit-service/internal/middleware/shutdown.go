@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"audit-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShutdownState records whether graceful shutdown has begun
+type ShutdownState struct {
+	shuttingDown atomic.Bool
+}
+
+// NewShutdownState creates a shutdown state for a server that is accepting requests
+func NewShutdownState() *ShutdownState {
+	return &ShutdownState{}
+}
+
+// Begin marks the start of shutdown; subsequent requests are refused
+func (s *ShutdownState) Begin() {
+	s.shuttingDown.Store(true)
+}
+
+// ShuttingDown reports whether shutdown has begun
+func (s *ShutdownState) ShuttingDown() bool {
+	return s.shuttingDown.Load()
+}
+
+// RejectDuringShutdown middleware refuses new requests with 503 once shutdown
+// has begun, so load balancers stop routing here. Requests already past this
+// middleware are left to finish.
+func RejectDuringShutdown(state *ShutdownState) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if state.ShuttingDown() {
+			c.Header("Connection", "close")
+			c.JSON(http.StatusServiceUnavailable, domain.APIErrServiceUnavailable)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRejectDuringShutdown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	state := NewShutdownState()
+	router := gin.New()
+	router.Use(RejectDuringShutdown(state))
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "healthy"})
+	})
+
+	serve := func() *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/health", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, state.ShuttingDown())
+
+	state.Begin()
+
+	w = serve()
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "close", w.Header().Get("Connection"))
+	assert.Contains(t, w.Body.String(), `"error":"service_unavailable"`)
+	assert.True(t, state.ShuttingDown())
+}
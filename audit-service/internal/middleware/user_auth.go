@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/cache"
+	"audit-service/pkg/jwt"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// UserAuth middleware authenticates routes that are not scoped to a session.
+// It accepts a JWT of a user that is not denied, or the admin token when one
+// is configured; share tokens are never accepted.
+func UserAuth(validator jwt.TokenValidator, tokenCache *cache.TokenCache, adminToken string, maxTokenLength int, deniedUsers DeniedUsers, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if adminToken != "" {
+			token := extractBearerToken(c.GetHeader("Authorization"))
+			if token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1 {
+				c.Set(AuthTokenTypeKey, TokenTypeAdmin)
+				c.Next()
+				return
+			}
+		}
+
+		if !authenticateJWT(c, validator, tokenCache, maxTokenLength, logger) {
+			c.JSON(http.StatusUnauthorized, domain.APIErrUnauthorized)
+			c.Abort()
+			return
+		}
+		if rejectDeniedUser(c, deniedUsers, logger) {
+			return
+		}
+
+		c.Set(AuthTokenTypeKey, TokenTypeJWT)
+		c.Next()
+	}
+}
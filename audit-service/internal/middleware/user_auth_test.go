@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"audit-service/mocks"
+	"audit-service/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestUserAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		adminToken     string
+		authHeader     string
+		setupMocks     func(*mocks.MockTokenValidator)
+		expectedStatus int
+		expectedType   string
+	}{
+		{
+			name:       "success_jwt",
+			adminToken: "admin-secret",
+			authHeader: "Bearer valid-jwt-token",
+			setupMocks: func(m *mocks.MockTokenValidator) {
+				m.On("ValidateToken", mock.Anything, "valid-jwt-token").Return(createTestJWTClaims(), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedType:   TokenTypeJWT,
+		},
+		{
+			name:           "success_admin_token",
+			adminToken:     "admin-secret",
+			authHeader:     "Bearer admin-secret",
+			setupMocks:     func(m *mocks.MockTokenValidator) {},
+			expectedStatus: http.StatusOK,
+			expectedType:   TokenTypeAdmin,
+		},
+		{
+			name:       "error_admin_token_when_unconfigured",
+			adminToken: "",
+			authHeader: "Bearer admin-secret",
+			setupMocks: func(m *mocks.MockTokenValidator) {
+				m.On("ValidateToken", mock.Anything, "admin-secret").Return(nil, errors.New("invalid token"))
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "error_missing_header",
+			adminToken:     "admin-secret",
+			setupMocks:     func(m *mocks.MockTokenValidator) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockValidator := mocks.NewMockTokenValidator(t)
+			tt.setupMocks(mockValidator)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+
+			var tokenType string
+			router := gin.New()
+			router.GET("/sessions", UserAuth(mockValidator, tokenCache, tt.adminToken, testMaxTokenLength, nil, zap.NewNop()), func(c *gin.Context) {
+				tokenType = GetAuthTokenType(c)
+				c.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest("GET", "/sessions", nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedType, tokenType)
+		})
+	}
+}
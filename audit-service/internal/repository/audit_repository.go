@@ -1,27 +1,51 @@
 package repository
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"strconv"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"audit-service/internal/domain"
 
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
 // AuditRepository defines the interface for audit data access
 type AuditRepository interface {
-	FindBySessionID(ctx context.Context, sessionID string, limit, offset int) ([]domain.AuditEntry, int, error)
+	FindBySessionID(ctx context.Context, sessionID string, limit, offset int, filter domain.HistoryFilter) ([]domain.AuditEntry, int, error)
+	CountBySessionID(ctx context.Context, sessionID string, filter domain.HistoryFilter) (int, error)
 	GetSession(ctx context.Context, sessionID string) (*Session, error)
-	ValidateShareToken(ctx context.Context, token, sessionID string) (bool, error)
+	GetEntry(ctx context.Context, sessionID, entryID string) (*domain.AuditEntry, error)
+	ValidateShareToken(ctx context.Context, token, sessionID string) (bool, time.Time, error)
+	ValidateShareTokens(ctx context.Context, token string, sessionIDs []string) (map[string]time.Time, error)
+	ListActiveShares(ctx context.Context, sessionID string) ([]ShareToken, error)
+	CreateEntry(ctx context.Context, entry *domain.AuditEntry) (*domain.AuditEntry, error)
+	CreateEntries(ctx context.Context, entries []domain.AuditEntry) ([]domain.AuditEntry, error)
+	CountActionsSince(ctx context.Context, sessionID string, since time.Time) (map[string]int, error)
+	FindActivityBounds(ctx context.Context, sessionID string, excludeActions []string) (oldest, latest *time.Time, err error)
+	ListSessionsByUser(ctx context.Context, userID string) ([]domain.SessionSummary, error)
+	// HistoryReads counts FindBySessionID calls and CoalescedHistoryReads
+	// those answered by another caller's in-flight query
+	HistoryReads() int64
+	CoalescedHistoryReads() int64
 }
 
 // auditRepository implements the AuditRepository interface
 type auditRepository struct {
 	client SupabaseClientInterface
-	logger *zap.Logger
+	// reads shares identical in-flight history queries between callers
+	reads singleflight.Group
+	// historyReads and historyQueries count calls and the Supabase queries they led to
+	historyReads   atomic.Int64
+	historyQueries atomic.Int64
+	logger         *zap.Logger
 }
 
 // NewAuditRepository creates a new audit repository instance
@@ -45,36 +69,160 @@ type ShareToken struct {
 	ExpiresAt string `json:"expires_at,omitempty"`
 }
 
-// FindBySessionID retrieves audit logs for a specific session
-func (r *auditRepository) FindBySessionID(ctx context.Context, sessionID string, limit, offset int) ([]domain.AuditEntry, int, error) {
-	// Build query parameters
-	queryParams := map[string]string{
-		"session_id": fmt.Sprintf("eq.%s", sessionID),
-		"order":      "timestamp.desc",
-		"limit":      strconv.Itoa(limit),
-		"offset":     strconv.Itoa(offset),
-		"select":     "*",
+// auditLogRow represents an audit_logs row as written to the database
+type auditLogRow struct {
+	SessionID string          `json:"session_id"`
+	UserID    string          `json:"user_id"`
+	Action    string          `json:"action"`
+	Details   json.RawMessage `json:"details,omitempty"`
+	IPAddress string          `json:"ip_address,omitempty"`
+	UserAgent string          `json:"user_agent,omitempty"`
+	// Timestamp is only sent for imported entries; live writes use the database default
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	// PerformedBy is only sent when an admin records an entry on behalf of UserID
+	PerformedBy string `json:"performed_by,omitempty"`
+}
+
+// auditLogColumns maps AuditEntry json fields to audit_logs columns
+var auditLogColumns = map[string]string{
+	"id":        "id",
+	"sessionId": "session_id",
+	"userId":    "user_id",
+	"action":    "action",
+	"timestamp": "timestamp",
+	"details":   "details",
+	"ipAddress": "ip_address",
+	"userAgent": "user_agent",
+	"deletedAt": "deleted_at",
+	// performed_by is only set on entries an admin recorded for another user
+	"performedBy": "performed_by",
+}
+
+// selectColumns builds the PostgREST select clause for the requested fields,
+// aliasing columns back to their json names so they decode into AuditEntry.
+// No fields selects every column, still aliased: with "*" snake_case columns
+// such as deleted_at would never decode.
+func selectColumns(fields []string) string {
+	if len(fields) == 0 {
+		fields = domain.AuditEntryFields()
 	}
 
-	// Make request to Supabase
-	data, count, err := r.client.Get(ctx, "/audit_logs", queryParams)
+	columns := make([]string, 0, len(fields))
+	for _, field := range fields {
+		column := auditLogColumns[field]
+		if column == field {
+			columns = append(columns, column)
+		} else {
+			columns = append(columns, field+":"+column)
+		}
+	}
+	return strings.Join(columns, ",")
+}
+
+// auditLogsInsert is the endpoint for inserts. PostgREST returns the inserted
+// rows through the same aliased select as reads, so they decode in full.
+var auditLogsInsert = "/audit_logs?select=" + selectColumns(nil)
+
+// historyRow is an audit_logs row as returned by the history query,
+// with the parent session embedded when include=session was requested
+type historyRow struct {
+	domain.AuditEntry
+	Sessions *struct {
+		Title  string `json:"title"`
+		UserID string `json:"user_id"`
+	} `json:"sessions"`
+}
+
+// auditLogPage is the shared result of a deduplicated history query
+type auditLogPage struct {
+	entries []domain.AuditEntry
+	count   int
+}
+
+// FindBySessionID retrieves audit logs for a specific session. Concurrent
+// identical queries share a single Supabase call; nothing is kept once it returns.
+func (r *auditRepository) FindBySessionID(ctx context.Context, sessionID string, limit, offset int, filter domain.HistoryFilter) ([]domain.AuditEntry, int, error) {
+	key := fmt.Sprintf("%s|%d|%d|%s", sessionID, limit, offset, filter.Key())
+	r.historyReads.Add(1)
+	result := r.reads.DoChan(key, func() (interface{}, error) {
+		r.historyQueries.Add(1)
+		entries, count, err := r.findBySessionID(ctx, sessionID, limit, offset, filter)
+		return auditLogPage{entries: entries, count: count}, err
+	})
+
+	select {
+	case res := <-result:
+		// The shared query runs on the first caller's context. If that caller
+		// went away, the others still want an answer, so they query themselves.
+		if isContextError(res.Err) && ctx.Err() == nil {
+			r.historyQueries.Add(1)
+			return r.findBySessionID(ctx, sessionID, limit, offset, filter)
+		}
+		if res.Err != nil {
+			return nil, 0, res.Err
+		}
+		page := res.Val.(auditLogPage)
+		return page.entries, page.count, nil
+	case <-ctx.Done():
+		return nil, 0, ctx.Err()
+	}
+}
+
+// HistoryReads returns how many history reads have been requested
+func (r *auditRepository) HistoryReads() int64 {
+	return r.historyReads.Load()
+}
+
+// CoalescedHistoryReads returns how many history reads shared another
+// caller's query instead of hitting Supabase themselves
+func (r *auditRepository) CoalescedHistoryReads() int64 {
+	// Load queries first: every query was preceded by its read, so this never goes negative
+	queries := r.historyQueries.Load()
+	return r.historyReads.Load() - queries
+}
+
+// findBySessionID performs the history query for FindBySessionID
+func (r *auditRepository) findBySessionID(ctx context.Context, sessionID string, limit, offset int, filter domain.HistoryFilter) ([]domain.AuditEntry, int, error) {
+	queryParams, err := r.historyParams(ctx, sessionID, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	queryParams["select"] = selectColumns(filter.Fields)
+	if filter.IncludeSession {
+		// Embed the parent session through the audit_logs.session_id foreign key
+		queryParams["select"] += ",sessions(title,user_id)"
+	}
+
+	// Make request to Supabase, paging via the Range header; on failure the count holds the HTTP status
+	data, count, err := r.client.GetRange(ctx, "/audit_logs", queryParams, offset, limit)
 	if err != nil {
 		r.logger.Error("failed to fetch audit logs",
 			zap.String("session_id", sessionID),
 			zap.Error(err),
 		)
-		return nil, 0, fmt.Errorf("failed to fetch audit logs: %w", err)
+		return nil, 0, fmt.Errorf("failed to fetch audit logs: %w", upstreamError(count, err))
 	}
 
 	// Parse response
-	var entries []domain.AuditEntry
-	if err := json.Unmarshal(data, &entries); err != nil {
+	if isJSONObject(data) {
+		return nil, 0, r.unexpectedObjectError("/audit_logs", sessionID, data)
+	}
+
+	var rows []historyRow
+	if err := json.Unmarshal(data, &rows); err != nil {
 		r.logger.Error("failed to parse audit logs",
 			zap.String("session_id", sessionID),
 			zap.Error(err),
 		)
 		return nil, 0, fmt.Errorf("failed to parse audit logs: %w", err)
 	}
+	entries := make([]domain.AuditEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = row.AuditEntry
+		if row.Sessions != nil {
+			entries[i].Session = &domain.SessionMetadata{Title: row.Sessions.Title, OwnerID: row.Sessions.UserID}
+		}
+	}
 
 	r.logger.Debug("fetched audit logs",
 		zap.String("session_id", sessionID),
@@ -85,6 +233,367 @@ func (r *auditRepository) FindBySessionID(ctx context.Context, sessionID string,
 	return entries, count, nil
 }
 
+// CountBySessionID counts the audit logs matching filter without fetching
+// any rows; Fields and IncludeSession are ignored
+func (r *auditRepository) CountBySessionID(ctx context.Context, sessionID string, filter domain.HistoryFilter) (int, error) {
+	queryParams, err := r.historyParams(ctx, sessionID, filter)
+	if err != nil {
+		return 0, err
+	}
+	// An empty page still carries the total in Content-Range, and needs no ordering
+	delete(queryParams, "order")
+	queryParams["select"] = "id"
+	queryParams["limit"] = "0"
+
+	// On failure the count holds the HTTP status
+	data, count, err := r.client.Get(ctx, "/audit_logs", queryParams)
+	if err != nil {
+		r.logger.Error("failed to count audit logs",
+			zap.String("session_id", sessionID),
+			zap.Error(err),
+		)
+		return 0, fmt.Errorf("failed to count audit logs: %w", upstreamError(count, err))
+	}
+	if isJSONObject(data) {
+		return 0, r.unexpectedObjectError("/audit_logs", sessionID, data)
+	}
+
+	r.logger.Debug("counted audit logs",
+		zap.String("session_id", sessionID),
+		zap.Int("total", count),
+	)
+
+	return count, nil
+}
+
+// History is ordered by timestamp with the ID breaking ties, so entries
+// sharing a timestamp always come back in the same order
+const (
+	historyOrderDesc = "timestamp.desc,id.desc"
+	historyOrderAsc  = "timestamp.asc,id.asc"
+)
+
+// historyParams builds the PostgREST filters shared by the history and
+// count queries; callers add the select clause
+func (r *auditRepository) historyParams(ctx context.Context, sessionID string, filter domain.HistoryFilter) (map[string]string, error) {
+	queryParams := map[string]string{
+		"session_id": fmt.Sprintf("eq.%s", sessionID),
+		"order":      historyOrderDesc,
+	}
+	if !filter.IncludeDeleted {
+		// Tombstoned entries stay in the table but are hidden from readers
+		queryParams["deleted_at"] = "is.null"
+	}
+	var timestampConds, andConds []string
+	if filter.SinceID != "" {
+		since, err := r.findEntryTimestamp(ctx, sessionID, filter.SinceID, filter.IncludeDeleted)
+		if err != nil {
+			return nil, err
+		}
+		// Incremental sync walks forward from the known entry, including
+		// entries that share its timestamp but sort after it
+		ts := since.UTC().Format(time.RFC3339Nano)
+		andConds = append(andConds, fmt.Sprintf("or(timestamp.gt.%s,and(timestamp.eq.%s,id.gt.%s))", ts, ts, filter.SinceID))
+		queryParams["order"] = historyOrderAsc
+	}
+	if !filter.From.IsZero() {
+		timestampConds = append(timestampConds, "gte."+filter.From.UTC().Format(time.RFC3339Nano))
+	}
+	if !filter.To.IsZero() {
+		timestampConds = append(timestampConds, "lt."+filter.To.UTC().Format(time.RFC3339Nano))
+	}
+	// A query param can only appear once in the map, so several timestamp
+	// bounds, the sinceId cursor and the composite filter share a single and=()
+	switch len(timestampConds) {
+	case 0:
+	case 1:
+		queryParams["timestamp"] = timestampConds[0]
+	default:
+		for _, cond := range timestampConds {
+			andConds = append(andConds, "timestamp."+cond)
+		}
+	}
+	if filter.Where != nil {
+		where, err := filter.Where.PostgREST()
+		if err != nil {
+			return nil, err
+		}
+		andConds = append(andConds, where)
+	}
+	if len(andConds) > 0 {
+		queryParams["and"] = "(" + strings.Join(andConds, ",") + ")"
+	}
+	if len(filter.Actions) > 0 {
+		queryParams["action"] = fmt.Sprintf("in.(%s)", strings.Join(filter.Actions, ","))
+	} else if len(filter.ExcludeActions) > 0 {
+		queryParams["action"] = fmt.Sprintf("not.in.(%s)", strings.Join(filter.ExcludeActions, ","))
+	}
+	for key, value := range filter.Details {
+		// Keys are interpolated into the query, so never trust the caller to have checked them
+		if err := domain.ValidateDetailsFilter(key, value); err != nil {
+			return nil, err
+		}
+		queryParams["details->>"+key] = "eq." + value
+	}
+	return queryParams, nil
+}
+
+// CountActionsSince counts a session's audit logs per action from since onwards
+func (r *auditRepository) CountActionsSince(ctx context.Context, sessionID string, since time.Time) (map[string]int, error) {
+	// Build query parameters
+	queryParams := map[string]string{
+		"session_id": fmt.Sprintf("eq.%s", sessionID),
+		"timestamp":  fmt.Sprintf("gte.%s", since.UTC().Format(time.RFC3339)),
+		"deleted_at": "is.null",
+		"select":     "action",
+	}
+
+	// Make request to Supabase
+	data, status, err := r.client.Get(ctx, "/audit_logs", queryParams)
+	if err != nil {
+		r.logger.Error("failed to count audit actions",
+			zap.String("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to count audit actions: %w", upstreamError(status, err))
+	}
+
+	// Parse response
+	if isJSONObject(data) {
+		return nil, r.unexpectedObjectError("/audit_logs", sessionID, data)
+	}
+
+	var rows []struct {
+		Action string `json:"action"`
+	}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		r.logger.Error("failed to parse audit actions",
+			zap.String("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to parse audit actions: %w", err)
+	}
+
+	counts := make(map[string]int)
+	for _, row := range rows {
+		counts[row.Action]++
+	}
+
+	return counts, nil
+}
+
+// FindActivityBounds returns the timestamps of a session's oldest and most
+// recent retained audit logs, or nil when the session has none. Logs whose
+// action is in excludeActions are skipped.
+func (r *auditRepository) FindActivityBounds(ctx context.Context, sessionID string, excludeActions []string) (*time.Time, *time.Time, error) {
+	oldest, err := r.findEdgeTimestamp(ctx, sessionID, "timestamp.asc", excludeActions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	latest, err := r.findEdgeTimestamp(ctx, sessionID, "timestamp.desc", excludeActions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return oldest, latest, nil
+}
+
+// ListSessionsByUser returns the user's sessions with their latest audit
+// timestamp, most recently active first. Sessions without activity come last.
+func (r *auditRepository) ListSessionsByUser(ctx context.Context, userID string) ([]domain.SessionSummary, error) {
+	// Embed only the newest audit log of each session
+	queryParams := map[string]string{
+		"user_id":          fmt.Sprintf("eq.%s", userID),
+		"select":           "id,user_id,audit_logs(timestamp)",
+		"audit_logs.order": "timestamp.desc",
+		"audit_logs.limit": "1",
+	}
+	// Tombstoned entries don't count as activity
+	queryParams["audit_logs.deleted_at"] = "is.null"
+
+	data, status, err := r.client.Get(ctx, "/sessions", queryParams)
+	if err != nil {
+		r.logger.Error("failed to list sessions",
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to list sessions: %w", upstreamError(status, err))
+	}
+
+	if isJSONObject(data) {
+		return nil, r.unexpectedObjectError("/sessions", "", data)
+	}
+
+	var rows []struct {
+		ID        string `json:"id"`
+		UserID    string `json:"user_id"`
+		AuditLogs []struct {
+			Timestamp time.Time `json:"timestamp"`
+		} `json:"audit_logs"`
+	}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		r.logger.Error("failed to parse sessions",
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to parse sessions: %w", err)
+	}
+
+	sessions := make([]domain.SessionSummary, len(rows))
+	for i, row := range rows {
+		sessions[i] = domain.SessionSummary{SessionID: row.ID, UserID: row.UserID}
+		if len(row.AuditLogs) > 0 {
+			sessions[i].LastActivity = &row.AuditLogs[0].Timestamp
+		}
+	}
+
+	// PostgREST cannot order parents by an embedded column, so sort here
+	sort.SliceStable(sessions, func(i, j int) bool {
+		a, b := sessions[i].LastActivity, sessions[j].LastActivity
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return a.After(*b)
+	})
+
+	return sessions, nil
+}
+
+// findEdgeTimestamp fetches the timestamp of the first audit log in the given
+// order, skipping excluded actions
+func (r *auditRepository) findEdgeTimestamp(ctx context.Context, sessionID, order string, excludeActions []string) (*time.Time, error) {
+	// Build query parameters
+	queryParams := map[string]string{
+		"session_id": fmt.Sprintf("eq.%s", sessionID),
+		"order":      order,
+		"limit":      "1",
+		"deleted_at": "is.null",
+		"select":     "timestamp",
+	}
+	if len(excludeActions) > 0 {
+		queryParams["action"] = fmt.Sprintf("not.in.(%s)", strings.Join(excludeActions, ","))
+	}
+
+	// Make request to Supabase
+	data, status, err := r.client.Get(ctx, "/audit_logs", queryParams)
+	if err != nil {
+		r.logger.Error("failed to fetch activity timestamp",
+			zap.String("session_id", sessionID),
+			zap.String("order", order),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch activity timestamp: %w", upstreamError(status, err))
+	}
+
+	// Parse response
+	if isJSONObject(data) {
+		return nil, r.unexpectedObjectError("/audit_logs", sessionID, data)
+	}
+
+	var rows []struct {
+		Timestamp time.Time `json:"timestamp"`
+	}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		r.logger.Error("failed to parse activity timestamp",
+			zap.String("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to parse activity timestamp: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	return &rows[0].Timestamp, nil
+}
+
+// findEntryTimestamp fetches the timestamp of an audit log entry within a
+// session. Tombstoned entries are only found when includeDeleted is set.
+func (r *auditRepository) findEntryTimestamp(ctx context.Context, sessionID, entryID string, includeDeleted bool) (time.Time, error) {
+	// Build query parameters
+	queryParams := map[string]string{
+		"id":         fmt.Sprintf("eq.%s", entryID),
+		"session_id": fmt.Sprintf("eq.%s", sessionID),
+		"limit":      "1",
+		"select":     "timestamp",
+	}
+	if !includeDeleted {
+		queryParams["deleted_at"] = "is.null"
+	}
+
+	// Make request to Supabase; on failure the count holds the HTTP status
+	data, status, err := r.client.Get(ctx, "/audit_logs", queryParams)
+	if err != nil {
+		r.logger.Error("failed to fetch entry timestamp",
+			zap.String("session_id", sessionID),
+			zap.String("entry_id", entryID),
+			zap.Error(err),
+		)
+		return time.Time{}, fmt.Errorf("failed to fetch entry timestamp: %w", upstreamError(status, err))
+	}
+
+	// Parse response
+	if isJSONObject(data) {
+		return time.Time{}, r.unexpectedObjectError("/audit_logs", sessionID, data)
+	}
+
+	var rows []struct {
+		Timestamp time.Time `json:"timestamp"`
+	}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse entry timestamp: %w", err)
+	}
+
+	// An entry from another session is indistinguishable from a missing one
+	if len(rows) == 0 {
+		return time.Time{}, domain.ErrEntryNotFound
+	}
+
+	return rows[0].Timestamp, nil
+}
+
+// GetEntry retrieves a single audit entry of a session; tombstoned entries are not found
+func (r *auditRepository) GetEntry(ctx context.Context, sessionID, entryID string) (*domain.AuditEntry, error) {
+	// Build query parameters
+	queryParams := map[string]string{
+		"id":         fmt.Sprintf("eq.%s", entryID),
+		"session_id": fmt.Sprintf("eq.%s", sessionID),
+		"deleted_at": "is.null",
+		"limit":      "1",
+		"select":     selectColumns(nil),
+	}
+
+	// Make request to Supabase; on failure the count holds the HTTP status
+	data, status, err := r.client.Get(ctx, "/audit_logs", queryParams)
+	if err != nil {
+		r.logger.Error("failed to fetch audit entry",
+			zap.String("session_id", sessionID),
+			zap.String("entry_id", entryID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch audit entry: %w", upstreamError(status, err))
+	}
+
+	// Parse response
+	if isJSONObject(data) {
+		return nil, r.unexpectedObjectError("/audit_logs", sessionID, data)
+	}
+
+	var entries []domain.AuditEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse audit entry: %w", err)
+	}
+
+	// An entry from another session is indistinguishable from a missing one
+	if len(entries) == 0 {
+		return nil, domain.ErrEntryNotFound
+	}
+
+	return &entries[0], nil
+}
+
 // GetSession retrieves session information
 func (r *auditRepository) GetSession(ctx context.Context, sessionID string) (*Session, error) {
 	// Build query parameters
@@ -94,17 +603,21 @@ func (r *auditRepository) GetSession(ctx context.Context, sessionID string) (*Se
 		"limit":  "1",
 	}
 
-	// Make request to Supabase
-	data, _, err := r.client.Get(ctx, "/sessions", queryParams)
+	// Make request to Supabase; on failure the count holds the HTTP status
+	data, status, err := r.client.Get(ctx, "/sessions", queryParams)
 	if err != nil {
 		r.logger.Error("failed to fetch session",
 			zap.String("session_id", sessionID),
 			zap.Error(err),
 		)
-		return nil, fmt.Errorf("failed to fetch session: %w", err)
+		return nil, fmt.Errorf("failed to fetch session: %w", upstreamError(status, err))
 	}
 
 	// Parse response
+	if isJSONObject(data) {
+		return nil, r.unexpectedObjectError("/sessions", sessionID, data)
+	}
+
 	var sessions []Session
 	if err := json.Unmarshal(data, &sessions); err != nil {
 		r.logger.Error("failed to parse session",
@@ -121,8 +634,10 @@ func (r *auditRepository) GetSession(ctx context.Context, sessionID string) (*Se
 	return &sessions[0], nil
 }
 
-// ValidateShareToken checks if a share token is valid for a session
-func (r *auditRepository) ValidateShareToken(ctx context.Context, token, sessionID string) (bool, error) {
+// ValidateShareToken checks if a share token is valid for a session and
+// returns its expiry. A zero expiry means the token does not expire; an
+// expired token yields domain.ErrShareTokenExpired.
+func (r *auditRepository) ValidateShareToken(ctx context.Context, token, sessionID string) (bool, time.Time, error) {
 	// Build query parameters
 	queryParams := map[string]string{
 		"token":      fmt.Sprintf("eq.%s", token),
@@ -132,30 +647,289 @@ func (r *auditRepository) ValidateShareToken(ctx context.Context, token, session
 	}
 
 	// Make request to Supabase
-	data, _, err := r.client.Get(ctx, "/session_shares", queryParams)
+	data, status, err := r.client.Get(ctx, "/session_shares", queryParams)
 	if err != nil {
 		r.logger.Error("failed to validate share token",
 			zap.String("session_id", sessionID),
 			zap.Error(err),
 		)
-		return false, fmt.Errorf("failed to validate share token: %w", err)
+		return false, time.Time{}, fmt.Errorf("failed to validate share token: %w", upstreamError(status, err))
 	}
 
 	// Parse response
+	if isJSONObject(data) {
+		return false, time.Time{}, r.unexpectedObjectError("/session_shares", sessionID, data)
+	}
+
 	var shares []ShareToken
 	if err := json.Unmarshal(data, &shares); err != nil {
 		r.logger.Error("failed to parse share token",
 			zap.String("session_id", sessionID),
 			zap.Error(err),
 		)
-		return false, fmt.Errorf("failed to parse share token: %w", err)
+		return false, time.Time{}, fmt.Errorf("failed to parse share token: %w", err)
 	}
 
 	if len(shares) == 0 {
-		return false, nil
+		return false, time.Time{}, nil
+	}
+
+	if shares[0].ExpiresAt == "" {
+		return true, time.Time{}, nil
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, shares[0].ExpiresAt)
+	if err != nil {
+		r.logger.Error("failed to parse share token expiry",
+			zap.String("session_id", sessionID),
+			zap.String("expires_at", shares[0].ExpiresAt),
+			zap.Error(err),
+		)
+		return false, time.Time{}, fmt.Errorf("failed to parse share token expiry: %w", err)
+	}
+
+	if !time.Now().Before(expiresAt) {
+		return false, time.Time{}, domain.ErrShareTokenExpired
+	}
+
+	return true, expiresAt, nil
+}
+
+// ValidateShareTokens checks one share token against several sessions in a
+// single query. It returns the sessions the token is currently valid for,
+// mapped to its expiry (zero when it never expires); sessions it is unknown,
+// expired or unreadable for are left out, for ValidateShareToken to explain.
+func (r *auditRepository) ValidateShareTokens(ctx context.Context, token string, sessionIDs []string) (map[string]time.Time, error) {
+	// Build query parameters
+	queryParams := map[string]string{
+		"token":      fmt.Sprintf("eq.%s", token),
+		"session_id": fmt.Sprintf("in.(%s)", strings.Join(sessionIDs, ",")),
+		"select":     "token,session_id,expires_at",
+	}
+
+	// Make request to Supabase
+	data, status, err := r.client.Get(ctx, "/session_shares", queryParams)
+	if err != nil {
+		r.logger.Error("failed to validate share tokens",
+			zap.Int("sessions", len(sessionIDs)),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to validate share tokens: %w", upstreamError(status, err))
+	}
+
+	// Parse response
+	if isJSONObject(data) {
+		return nil, r.unexpectedObjectError("/session_shares", strings.Join(sessionIDs, ","), data)
+	}
+
+	var shares []ShareToken
+	if err := json.Unmarshal(data, &shares); err != nil {
+		r.logger.Error("failed to parse share tokens",
+			zap.Int("sessions", len(sessionIDs)),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to parse share tokens: %w", err)
+	}
+
+	now := time.Now()
+	valid := make(map[string]time.Time, len(shares))
+	for _, share := range shares {
+		if share.ExpiresAt == "" {
+			valid[share.SessionID] = time.Time{}
+			continue
+		}
+		expiresAt, err := time.Parse(time.RFC3339, share.ExpiresAt)
+		if err != nil || !now.Before(expiresAt) {
+			continue
+		}
+		valid[share.SessionID] = expiresAt
+	}
+	return valid, nil
+}
+
+// ListActiveShares returns a session's share tokens that have not expired,
+// soonest expiry first and tokens without an expiry last
+func (r *auditRepository) ListActiveShares(ctx context.Context, sessionID string) ([]ShareToken, error) {
+	// Build query parameters
+	queryParams := map[string]string{
+		"session_id": fmt.Sprintf("eq.%s", sessionID),
+		"or":         fmt.Sprintf("(expires_at.is.null,expires_at.gt.%s)", time.Now().UTC().Format(time.RFC3339)),
+		"select":     "token,session_id,expires_at",
+		"order":      "expires_at.asc.nullslast",
+	}
+
+	// Make request to Supabase; on failure the count holds the HTTP status
+	data, status, err := r.client.Get(ctx, "/session_shares", queryParams)
+	if err != nil {
+		r.logger.Error("failed to list share tokens",
+			zap.String("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to list share tokens: %w", upstreamError(status, err))
+	}
+
+	// Parse response
+	if isJSONObject(data) {
+		return nil, r.unexpectedObjectError("/session_shares", sessionID, data)
+	}
+
+	var shares []ShareToken
+	if err := json.Unmarshal(data, &shares); err != nil {
+		r.logger.Error("failed to parse share tokens",
+			zap.String("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to parse share tokens: %w", err)
+	}
+
+	return shares, nil
+}
+
+// CreateEntry inserts a new audit log entry and returns the stored row
+func (r *auditRepository) CreateEntry(ctx context.Context, entry *domain.AuditEntry) (*domain.AuditEntry, error) {
+	row := auditLogRow{
+		SessionID:   entry.SessionID,
+		UserID:      entry.UserID,
+		Action:      entry.Action,
+		Details:     entry.Details,
+		IPAddress:   entry.IPAddress,
+		UserAgent:   entry.UserAgent,
+		PerformedBy: entry.PerformedBy,
+	}
+
+	// Make request to Supabase
+	data, err := r.client.Post(ctx, auditLogsInsert, row)
+	if err != nil {
+		r.logger.Error("failed to create audit log",
+			zap.String("session_id", entry.SessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to create audit log: %w", upstreamError(responseStatus(err), err))
+	}
+
+	// Parse response
+	var created []domain.AuditEntry
+	if err := json.Unmarshal(data, &created); err != nil {
+		r.logger.Error("failed to parse created audit log",
+			zap.String("session_id", entry.SessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to parse created audit log: %w", err)
+	}
+
+	if len(created) == 0 {
+		return nil, fmt.Errorf("failed to create audit log: empty response")
+	}
+
+	r.logger.Debug("created audit log",
+		zap.String("session_id", entry.SessionID),
+		zap.String("id", created[0].ID),
+	)
+
+	return &created[0], nil
+}
+
+// CreateEntries inserts multiple audit log entries in a single request
+func (r *auditRepository) CreateEntries(ctx context.Context, entries []domain.AuditEntry) ([]domain.AuditEntry, error) {
+	rows := make([]auditLogRow, len(entries))
+	for i, entry := range entries {
+		rows[i] = auditLogRow{
+			SessionID:   entry.SessionID,
+			UserID:      entry.UserID,
+			Action:      entry.Action,
+			Details:     entry.Details,
+			IPAddress:   entry.IPAddress,
+			UserAgent:   entry.UserAgent,
+			PerformedBy: entry.PerformedBy,
+		}
+		if !entry.Timestamp.IsZero() {
+			timestamp := entry.Timestamp.UTC()
+			rows[i].Timestamp = &timestamp
+		}
+	}
+
+	// Make request to Supabase
+	data, err := r.client.Post(ctx, auditLogsInsert, rows)
+	if err != nil {
+		r.logger.Error("failed to create audit logs",
+			zap.Int("count", len(rows)),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to create audit logs: %w", upstreamError(responseStatus(err), err))
+	}
+
+	// Parse response
+	var created []domain.AuditEntry
+	if err := json.Unmarshal(data, &created); err != nil {
+		r.logger.Error("failed to parse created audit logs",
+			zap.Int("count", len(rows)),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to parse created audit logs: %w", err)
+	}
+
+	if len(created) != len(rows) {
+		return nil, fmt.Errorf("failed to create audit logs: expected %d rows, got %d", len(rows), len(created))
 	}
 
-	// TODO: Check expiration if expires_at is set
-	// For now, assume valid if found
-	return true, nil
+	r.logger.Debug("created audit logs",
+		zap.Int("count", len(created)),
+	)
+
+	return created, nil
+}
+
+// maxLoggedBodyBytes bounds how much of an unexpected response body is logged
+const maxLoggedBodyBytes = 256
+
+// isJSONObject reports whether data is a well-formed JSON object rather than an array.
+// PostgREST list queries always return arrays, so an object indicates a misrouted
+// request or an upstream error wrapped in a 200 response.
+func isJSONObject(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && trimmed[0] == '{' && json.Valid(trimmed)
+}
+
+// upstreamError marks transport failures and 5xx responses as ErrServiceUnavailable
+// so callers can tell an outage apart from a bad request. Every Supabase read goes
+// through it, so the layers above don't map upstream errors again.
+func upstreamError(status int, err error) error {
+	if isContextError(err) || errors.Is(err, domain.ErrServiceUnavailable) {
+		return err
+	}
+	if status == 0 || status >= 500 {
+		return fmt.Errorf("%w: %w", domain.ErrServiceUnavailable, err)
+	}
+	return err
+}
+
+// responseStatus returns the HTTP status carried by a failed Supabase call, or
+// 0 when no response arrived. Post doesn't return the status separately.
+func responseStatus(err error) int {
+	var coder StatusCoder
+	if errors.As(err, &coder) {
+		return coder.StatusCode()
+	}
+	return 0
+}
+
+// isContextError reports whether err comes from a cancelled or expired context
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// unexpectedObjectError logs the offending body and returns a service unavailable error
+func (r *auditRepository) unexpectedObjectError(endpoint, sessionID string, data []byte) error {
+	body := data
+	if len(body) > maxLoggedBodyBytes {
+		body = body[:maxLoggedBodyBytes]
+	}
+
+	r.logger.Error("supabase returned an object for a list query",
+		zap.String("endpoint", endpoint),
+		zap.String("session_id", sessionID),
+		zap.ByteString("body", body),
+	)
+
+	return fmt.Errorf("%w: expected JSON array from %s, got object", domain.ErrServiceUnavailable, endpoint)
 }
@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -12,6 +15,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -22,6 +26,12 @@ const (
 	testOwnerID     = "test-owner-789"
 	testOtherUserID = "other-user-999"
 	testShareToken  = "test-share-token-abc"
+
+	// allColumns is the default select: every audit_logs column aliased to
+	// its AuditEntry json name, which is also how PostgREST keys the rows
+	allColumns = "id,sessionId:session_id,userId:user_id,action,timestamp,details,ipAddress:ip_address,userAgent:user_agent,deletedAt:deleted_at,performedBy:performed_by"
+	// insertEndpoint returns inserted rows through the same aliased select
+	insertEndpoint = "/audit_logs?select=" + allColumns
 )
 
 // Helper functions to create test data
@@ -86,6 +96,11 @@ func (m *MockSupabaseClient) Get(ctx context.Context, endpoint string, params ma
 	return args.Get(0).([]byte), args.Int(1), args.Error(2)
 }
 
+func (m *MockSupabaseClient) GetRange(ctx context.Context, endpoint string, params map[string]string, offset, limit int) ([]byte, int, error) {
+	args := m.Called(ctx, endpoint, params, offset, limit)
+	return args.Get(0).([]byte), args.Int(1), args.Error(2)
+}
+
 func (m *MockSupabaseClient) Post(ctx context.Context, endpoint string, payload interface{}) ([]byte, error) {
 	args := m.Called(ctx, endpoint, payload)
 	return args.Get(0).([]byte), args.Error(1)
@@ -97,11 +112,117 @@ func TestAuditRepository_FindBySessionID(t *testing.T) {
 		sessionID      string
 		limit          int
 		offset         int
+		filter         domain.HistoryFilter
 		setupMocks     func(*MockSupabaseClient)
 		expectedResult []domain.AuditEntry
 		expectedCount  int
 		expectedError  error
 	}{
+		{
+			name:      "success_with_selected_fields",
+			sessionID: testSessionID,
+			limit:     10,
+			offset:    0,
+			filter:    domain.HistoryFilter{Fields: []string{"id", "action", "userId"}},
+			setupMocks: func(mockClient *MockSupabaseClient) {
+				data := []byte(`[{"id":"audit-1","action":"edit","userId":"` + testUserID + `"}]`)
+
+				expectedParams := map[string]string{
+					"session_id": "eq." + testSessionID,
+					"deleted_at": "is.null",
+					"order":      "timestamp.desc,id.desc",
+					"select":     "id,action,userId:user_id",
+				}
+
+				mockClient.On("GetRange", mock.Anything, "/audit_logs", expectedParams, 0, 10).
+					Return(data, 1, nil)
+			},
+			expectedResult: []domain.AuditEntry{{ID: "audit-1", Action: "edit", UserID: testUserID}},
+			expectedCount:  1,
+			expectedError:  nil,
+		},
+		{
+			name:      "success_with_details_filter",
+			sessionID: testSessionID,
+			limit:     10,
+			offset:    0,
+			filter:    domain.HistoryFilter{Details: map[string]string{"slide": "3"}},
+			setupMocks: func(mockClient *MockSupabaseClient) {
+				entries := createTestAuditEntries()[:1]
+				data, _ := json.Marshal(entries)
+
+				expectedParams := map[string]string{
+					"session_id":      "eq." + testSessionID,
+					"deleted_at":      "is.null",
+					"order":           "timestamp.desc,id.desc",
+					"select":          allColumns,
+					"details->>slide": "eq.3",
+				}
+
+				mockClient.On("GetRange", mock.Anything, "/audit_logs", expectedParams, 0, 10).
+					Return(data, 1, nil)
+			},
+			expectedResult: createTestAuditEntries()[:1],
+			expectedCount:  1,
+			expectedError:  nil,
+		},
+		{
+			name:      "success_with_action_filter",
+			sessionID: testSessionID,
+			limit:     10,
+			offset:    0,
+			filter:    domain.HistoryFilter{Actions: []string{"edit", "merge"}},
+			setupMocks: func(mockClient *MockSupabaseClient) {
+				expectedParams := map[string]string{
+					"session_id": "eq." + testSessionID,
+					"deleted_at": "is.null",
+					"order":      "timestamp.desc,id.desc",
+					"select":     allColumns,
+					"action":     "in.(edit,merge)",
+				}
+
+				mockClient.On("GetRange", mock.Anything, "/audit_logs", expectedParams, 0, 10).
+					Return([]byte(`[]`), 0, nil)
+			},
+			expectedResult: []domain.AuditEntry{},
+			expectedCount:  0,
+			expectedError:  nil,
+		},
+		{
+			name:      "success_with_excluded_actions",
+			sessionID: testSessionID,
+			limit:     10,
+			offset:    0,
+			filter:    domain.HistoryFilter{ExcludeActions: []string{"export", "share"}},
+			setupMocks: func(mockClient *MockSupabaseClient) {
+				expectedParams := map[string]string{
+					"session_id": "eq." + testSessionID,
+					"deleted_at": "is.null",
+					"order":      "timestamp.desc,id.desc",
+					"select":     allColumns,
+					"action":     "not.in.(export,share)",
+				}
+
+				mockClient.On("GetRange", mock.Anything, "/audit_logs", expectedParams, 0, 10).
+					Return([]byte(`[]`), 0, nil)
+			},
+			expectedResult: []domain.AuditEntry{},
+			expectedCount:  0,
+			expectedError:  nil,
+		},
+		{
+			name:      "rejects_unsafe_details_key",
+			sessionID: testSessionID,
+			limit:     10,
+			offset:    0,
+			filter:    domain.HistoryFilter{Details: map[string]string{"slide=eq.1&select": "*"}},
+			setupMocks: func(mockClient *MockSupabaseClient) {
+				// The request must never reach Supabase
+			},
+			expectedResult: nil,
+			expectedCount:  0,
+			expectedError:  domain.ErrInvalidDetailsFilter,
+		},
 		{
 			name:      "success_fetch_audit_logs",
 			sessionID: testSessionID,
@@ -113,13 +234,12 @@ func TestAuditRepository_FindBySessionID(t *testing.T) {
 
 				expectedParams := map[string]string{
 					"session_id": "eq." + testSessionID,
-					"order":      "timestamp.desc",
-					"limit":      "10",
-					"offset":     "0",
-					"select":     "*",
+					"deleted_at": "is.null",
+					"order":      "timestamp.desc,id.desc",
+					"select":     allColumns,
 				}
 
-				mockClient.On("Get", mock.Anything, "/audit_logs", expectedParams).
+				mockClient.On("GetRange", mock.Anything, "/audit_logs", expectedParams, 0, 10).
 					Return(data, 4, nil)
 			},
 			expectedResult: createTestAuditEntries(),
@@ -137,13 +257,12 @@ func TestAuditRepository_FindBySessionID(t *testing.T) {
 
 				expectedParams := map[string]string{
 					"session_id": "eq." + testSessionID,
-					"order":      "timestamp.desc",
-					"limit":      "50",
-					"offset":     "20",
-					"select":     "*",
+					"deleted_at": "is.null",
+					"order":      "timestamp.desc,id.desc",
+					"select":     allColumns,
 				}
 
-				mockClient.On("Get", mock.Anything, "/audit_logs", expectedParams).
+				mockClient.On("GetRange", mock.Anything, "/audit_logs", expectedParams, 20, 50).
 					Return(data, 100, nil)
 			},
 			expectedResult: generateTestAuditEntries(30, testSessionID, testUserID)[20:],
@@ -160,13 +279,12 @@ func TestAuditRepository_FindBySessionID(t *testing.T) {
 
 				expectedParams := map[string]string{
 					"session_id": "eq." + testSessionID,
-					"order":      "timestamp.desc",
-					"limit":      "10",
-					"offset":     "0",
-					"select":     "*",
+					"deleted_at": "is.null",
+					"order":      "timestamp.desc,id.desc",
+					"select":     allColumns,
 				}
 
-				mockClient.On("Get", mock.Anything, "/audit_logs", expectedParams).
+				mockClient.On("GetRange", mock.Anything, "/audit_logs", expectedParams, 0, 10).
 					Return(data, 0, nil)
 			},
 			expectedResult: []domain.AuditEntry{},
@@ -181,18 +299,38 @@ func TestAuditRepository_FindBySessionID(t *testing.T) {
 			setupMocks: func(mockClient *MockSupabaseClient) {
 				expectedParams := map[string]string{
 					"session_id": "eq." + testSessionID,
-					"order":      "timestamp.desc",
-					"limit":      "10",
-					"offset":     "0",
-					"select":     "*",
+					"deleted_at": "is.null",
+					"order":      "timestamp.desc,id.desc",
+					"select":     allColumns,
 				}
 
-				mockClient.On("Get", mock.Anything, "/audit_logs", expectedParams).
+				mockClient.On("GetRange", mock.Anything, "/audit_logs", expectedParams, 0, 10).
 					Return([]byte{}, 0, errors.New("network error"))
 			},
 			expectedResult: nil,
 			expectedCount:  0,
-			expectedError:  errors.New("failed to fetch audit logs: network error"),
+			expectedError:  domain.ErrServiceUnavailable,
+		},
+		{
+			name:      "error_client_rejected_query",
+			sessionID: testSessionID,
+			limit:     10,
+			offset:    0,
+			setupMocks: func(mockClient *MockSupabaseClient) {
+				expectedParams := map[string]string{
+					"session_id": "eq." + testSessionID,
+					"deleted_at": "is.null",
+					"order":      "timestamp.desc,id.desc",
+					"select":     allColumns,
+				}
+
+				// A 4xx is our fault, not an outage
+				mockClient.On("GetRange", mock.Anything, "/audit_logs", expectedParams, 0, 10).
+					Return([]byte{}, 400, errors.New("bad filter"))
+			},
+			expectedResult: nil,
+			expectedCount:  0,
+			expectedError:  errors.New("failed to fetch audit logs: bad filter"),
 		},
 		{
 			name:      "error_json_parse_failure",
@@ -204,19 +342,40 @@ func TestAuditRepository_FindBySessionID(t *testing.T) {
 
 				expectedParams := map[string]string{
 					"session_id": "eq." + testSessionID,
-					"order":      "timestamp.desc",
-					"limit":      "10",
-					"offset":     "0",
-					"select":     "*",
+					"deleted_at": "is.null",
+					"order":      "timestamp.desc,id.desc",
+					"select":     allColumns,
 				}
 
-				mockClient.On("Get", mock.Anything, "/audit_logs", expectedParams).
+				mockClient.On("GetRange", mock.Anything, "/audit_logs", expectedParams, 0, 10).
 					Return(invalidJSON, 0, nil)
 			},
 			expectedResult: nil,
 			expectedCount:  0,
 			expectedError:  errors.New("failed to parse audit logs"),
 		},
+		{
+			name:      "error_single_object_response",
+			sessionID: testSessionID,
+			limit:     10,
+			offset:    0,
+			setupMocks: func(mockClient *MockSupabaseClient) {
+				singleObject := []byte(`{"message": "relation does not exist", "code": "42P01"}`)
+
+				expectedParams := map[string]string{
+					"session_id": "eq." + testSessionID,
+					"deleted_at": "is.null",
+					"order":      "timestamp.desc,id.desc",
+					"select":     allColumns,
+				}
+
+				mockClient.On("GetRange", mock.Anything, "/audit_logs", expectedParams, 0, 10).
+					Return(singleObject, 0, nil)
+			},
+			expectedResult: nil,
+			expectedCount:  0,
+			expectedError:  domain.ErrServiceUnavailable,
+		},
 	}
 
 	for _, tt := range tests {
@@ -230,14 +389,18 @@ func TestAuditRepository_FindBySessionID(t *testing.T) {
 			tt.setupMocks(mockClient)
 
 			// Execute
-			result, count, err := repo.FindBySessionID(context.Background(), tt.sessionID, tt.limit, tt.offset)
+			result, count, err := repo.FindBySessionID(context.Background(), tt.sessionID, tt.limit, tt.offset, tt.filter)
 
 			// Assert
 			if tt.expectedError != nil {
 				assert.Error(t, err)
 				assert.Nil(t, result)
 				assert.Equal(t, 0, count)
-				assert.Contains(t, err.Error(), tt.expectedError.Error())
+				if tt.expectedError == domain.ErrServiceUnavailable {
+					assert.ErrorIs(t, err, domain.ErrServiceUnavailable)
+				} else {
+					assert.Contains(t, err.Error(), tt.expectedError.Error())
+				}
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.expectedResult, result)
@@ -250,6 +413,69 @@ func TestAuditRepository_FindBySessionID(t *testing.T) {
 	}
 }
 
+func TestAuditRepository_GetEntry(t *testing.T) {
+	const entryID = "550e8400-e29b-41d4-a716-446655440099"
+	expectedParams := map[string]string{
+		"id":         "eq." + entryID,
+		"session_id": "eq." + testSessionID,
+		"deleted_at": "is.null",
+		"limit":      "1",
+		"select":     allColumns,
+	}
+
+	t.Run("found", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		entry := createTestAuditEntries()[0]
+		data, _ := json.Marshal([]domain.AuditEntry{entry})
+		mockClient.On("Get", mock.Anything, "/audit_logs", expectedParams).Return(data, 1, nil)
+
+		result, err := repo.GetEntry(context.Background(), testSessionID, entryID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &entry, result)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Get", mock.Anything, "/audit_logs", expectedParams).Return([]byte(`[]`), 0, nil)
+
+		result, err := repo.GetEntry(context.Background(), testSessionID, entryID)
+
+		assert.ErrorIs(t, err, domain.ErrEntryNotFound)
+		assert.Nil(t, result)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("tombstoned", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		// The row only comes back when deleted rows aren't filtered out
+		deletedAt := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+		entry := createTestAuditEntries()[0]
+		entry.DeletedAt = &deletedAt
+		data, _ := json.Marshal([]domain.AuditEntry{entry})
+		mockClient.On("Get", mock.Anything, "/audit_logs", excludesDeleted).Return([]byte(`[]`), 0, nil)
+		mockClient.On("Get", mock.Anything, "/audit_logs", mock.Anything).Return(data, 1, nil)
+
+		result, err := repo.GetEntry(context.Background(), testSessionID, entryID)
+
+		assert.ErrorIs(t, err, domain.ErrEntryNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+// excludesDeleted matches queries that filter out tombstoned rows, so a mock
+// can stand in for PostgREST leaving them out of the result
+var excludesDeleted = mock.MatchedBy(func(params map[string]string) bool {
+	return params["deleted_at"] == "is.null"
+})
+
 func TestAuditRepository_GetSession(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -310,7 +536,7 @@ func TestAuditRepository_GetSession(t *testing.T) {
 					Return([]byte{}, 0, errors.New("database error"))
 			},
 			expectedResult: nil,
-			expectedError:  errors.New("failed to fetch session: database error"),
+			expectedError:  errors.New("failed to fetch session: service temporarily unavailable: database error"),
 		},
 		{
 			name:      "error_json_parse_failure",
@@ -369,12 +595,13 @@ func TestAuditRepository_GetSession(t *testing.T) {
 
 func TestAuditRepository_ValidateShareToken(t *testing.T) {
 	tests := []struct {
-		name          string
-		token         string
-		sessionID     string
-		setupMocks    func(*MockSupabaseClient)
-		expectedValid bool
-		expectedError error
+		name           string
+		token          string
+		sessionID      string
+		setupMocks     func(*MockSupabaseClient)
+		expectedValid  bool
+		expectedExpiry time.Time
+		expectedError  error
 	}{
 		{
 			name:      "success_valid_token",
@@ -401,6 +628,40 @@ func TestAuditRepository_ValidateShareToken(t *testing.T) {
 			expectedValid: true,
 			expectedError: nil,
 		},
+		{
+			name:      "success_valid_token_with_expiry",
+			token:     testShareToken,
+			sessionID: testSessionID,
+			setupMocks: func(mockClient *MockSupabaseClient) {
+				data := []byte(`[{"token":"` + testShareToken + `","session_id":"` + testSessionID + `","expires_at":"2999-01-01T00:00:00+00:00"}]`)
+				mockClient.On("Get", mock.Anything, "/session_shares", mock.Anything).
+					Return(data, 1, nil)
+			},
+			expectedValid:  true,
+			expectedExpiry: time.Date(2999, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "invalid_token_expired",
+			token:     testShareToken,
+			sessionID: testSessionID,
+			setupMocks: func(mockClient *MockSupabaseClient) {
+				data := []byte(`[{"token":"` + testShareToken + `","session_id":"` + testSessionID + `","expires_at":"2000-01-01T00:00:00Z"}]`)
+				mockClient.On("Get", mock.Anything, "/session_shares", mock.Anything).
+					Return(data, 1, nil)
+			},
+			expectedError: domain.ErrShareTokenExpired,
+		},
+		{
+			name:      "error_invalid_expiry_format",
+			token:     testShareToken,
+			sessionID: testSessionID,
+			setupMocks: func(mockClient *MockSupabaseClient) {
+				data := []byte(`[{"token":"` + testShareToken + `","session_id":"` + testSessionID + `","expires_at":"tomorrow"}]`)
+				mockClient.On("Get", mock.Anything, "/session_shares", mock.Anything).
+					Return(data, 1, nil)
+			},
+			expectedError: errors.New("failed to parse share token expiry"),
+		},
 		{
 			name:      "invalid_token_not_found",
 			token:     "invalid-token",
@@ -437,7 +698,7 @@ func TestAuditRepository_ValidateShareToken(t *testing.T) {
 					Return([]byte{}, 0, errors.New("network error"))
 			},
 			expectedValid: false,
-			expectedError: errors.New("failed to validate share token: network error"),
+			expectedError: errors.New("failed to validate share token: service temporarily unavailable: network error"),
 		},
 		{
 			name:      "error_json_parse_failure",
@@ -472,7 +733,7 @@ func TestAuditRepository_ValidateShareToken(t *testing.T) {
 			tt.setupMocks(mockClient)
 
 			// Execute
-			valid, err := repo.ValidateShareToken(context.Background(), tt.token, tt.sessionID)
+			valid, expiresAt, err := repo.ValidateShareToken(context.Background(), tt.token, tt.sessionID)
 
 			// Assert
 			if tt.expectedError != nil {
@@ -482,6 +743,7 @@ func TestAuditRepository_ValidateShareToken(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.expectedValid, valid)
+				assert.True(t, tt.expectedExpiry.Equal(expiresAt))
 			}
 
 			// Verify all expectations were met
@@ -490,6 +752,1020 @@ func TestAuditRepository_ValidateShareToken(t *testing.T) {
 	}
 }
 
+func TestAuditRepository_CreateEntry(t *testing.T) {
+	details := json.RawMessage(`{"slide":1}`)
+	entry := &domain.AuditEntry{
+		SessionID: testSessionID,
+		UserID:    testUserID,
+		Action:    "edit",
+		Details:   details,
+		IPAddress: "192.168.1.1",
+	}
+	expectedRow := auditLogRow{
+		SessionID: testSessionID,
+		UserID:    testUserID,
+		Action:    "edit",
+		Details:   details,
+		IPAddress: "192.168.1.1",
+	}
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockSupabaseClient)
+		expectedID    string
+		expectedError string
+	}{
+		{
+			name: "success_create_entry",
+			setupMocks: func(mockClient *MockSupabaseClient) {
+				created := []domain.AuditEntry{{ID: "audit-001", SessionID: testSessionID, UserID: testUserID, Action: "edit"}}
+				data, _ := json.Marshal(created)
+				mockClient.On("Post", mock.Anything, insertEndpoint, expectedRow).Return(data, nil)
+			},
+			expectedID: "audit-001",
+		},
+		{
+			name: "error_client_failure",
+			setupMocks: func(mockClient *MockSupabaseClient) {
+				mockClient.On("Post", mock.Anything, insertEndpoint, expectedRow).
+					Return([]byte{}, errors.New("network error"))
+			},
+			expectedError: "failed to create audit log: service temporarily unavailable: network error",
+		},
+		{
+			name: "error_empty_response",
+			setupMocks: func(mockClient *MockSupabaseClient) {
+				mockClient.On("Post", mock.Anything, insertEndpoint, expectedRow).Return([]byte(`[]`), nil)
+			},
+			expectedError: "empty response",
+		},
+		{
+			name: "error_json_parse_failure",
+			setupMocks: func(mockClient *MockSupabaseClient) {
+				mockClient.On("Post", mock.Anything, insertEndpoint, expectedRow).Return([]byte(`{"invalid": json}`), nil)
+			},
+			expectedError: "failed to parse created audit log",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockSupabaseClient{}
+			repo := NewAuditRepository(mockClient, zap.NewNop())
+
+			tt.setupMocks(mockClient)
+
+			result, err := repo.CreateEntry(context.Background(), entry)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedID, result.ID)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditRepository_CreateEntry_ReturnsStoredColumns(t *testing.T) {
+	mockClient := &MockSupabaseClient{}
+	repo := NewAuditRepository(mockClient, zap.NewNop())
+
+	// The audit_logs row as stored, keyed by the aliases in the insert's select
+	stored := `[{"id":"audit-001","sessionId":"` + testSessionID + `","userId":"` + testUserID + `","action":"edit",` +
+		`"timestamp":"2023-12-01T10:30:00Z","details":{"slide":1},"ipAddress":"192.168.1.1","userAgent":"Mozilla/5.0",` +
+		`"deletedAt":null,"performedBy":"admin-789"}]`
+	mockClient.On("Post", mock.Anything, insertEndpoint, mock.Anything).Return([]byte(stored), nil)
+
+	created, err := repo.CreateEntry(context.Background(), &domain.AuditEntry{
+		SessionID:   testSessionID,
+		UserID:      testUserID,
+		Action:      "edit",
+		IPAddress:   "192.168.1.1",
+		PerformedBy: "admin-789",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, testSessionID, created.SessionID)
+	assert.Equal(t, testUserID, created.UserID)
+	assert.Equal(t, "192.168.1.1", created.IPAddress)
+	assert.Equal(t, "Mozilla/5.0", created.UserAgent)
+	assert.Equal(t, "admin-789", created.PerformedBy)
+	assert.Equal(t, time.Date(2023, 12, 1, 10, 30, 0, 0, time.UTC), created.Timestamp)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAuditRepository_CreateEntries(t *testing.T) {
+	entries := []domain.AuditEntry{
+		{SessionID: testSessionID, UserID: testUserID, Action: "edit"},
+		{SessionID: testSessionID, UserID: testUserID, Action: "merge"},
+	}
+	expectedRows := []auditLogRow{
+		{SessionID: testSessionID, UserID: testUserID, Action: "edit"},
+		{SessionID: testSessionID, UserID: testUserID, Action: "merge"},
+	}
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockSupabaseClient)
+		expectedIDs   []string
+		expectedError string
+	}{
+		{
+			name: "success_single_insert",
+			setupMocks: func(mockClient *MockSupabaseClient) {
+				created := []domain.AuditEntry{{ID: "audit-101"}, {ID: "audit-102"}}
+				data, _ := json.Marshal(created)
+				mockClient.On("Post", mock.Anything, insertEndpoint, expectedRows).Return(data, nil).Once()
+			},
+			expectedIDs: []string{"audit-101", "audit-102"},
+		},
+		{
+			name: "error_row_count_mismatch",
+			setupMocks: func(mockClient *MockSupabaseClient) {
+				mockClient.On("Post", mock.Anything, insertEndpoint, expectedRows).Return([]byte(`[{"id":"audit-101"}]`), nil)
+			},
+			expectedError: "expected 2 rows, got 1",
+		},
+		{
+			name: "error_client_failure",
+			setupMocks: func(mockClient *MockSupabaseClient) {
+				mockClient.On("Post", mock.Anything, insertEndpoint, expectedRows).
+					Return([]byte{}, errors.New("network error"))
+			},
+			expectedError: "failed to create audit logs: service temporarily unavailable: network error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockSupabaseClient{}
+			repo := NewAuditRepository(mockClient, zap.NewNop())
+
+			tt.setupMocks(mockClient)
+
+			result, err := repo.CreateEntries(context.Background(), entries)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				ids := make([]string, len(result))
+				for i, entry := range result {
+					ids[i] = entry.ID
+				}
+				assert.Equal(t, tt.expectedIDs, ids)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditRepository_CreateEntries_ImportedTimestamp(t *testing.T) {
+	mockClient := &MockSupabaseClient{}
+	repo := NewAuditRepository(mockClient, zap.NewNop())
+
+	historical := time.Date(2023, 11, 1, 10, 0, 0, 0, time.FixedZone("CET", 3600))
+	utc := historical.UTC()
+	expectedRows := []auditLogRow{
+		{SessionID: testSessionID, UserID: testUserID, Action: "edit", Timestamp: &utc},
+		{SessionID: testSessionID, UserID: testUserID, Action: "merge"},
+	}
+	mockClient.On("Post", mock.Anything, insertEndpoint, expectedRows).
+		Return([]byte(`[{"id":"audit-101"},{"id":"audit-102"}]`), nil)
+
+	_, err := repo.CreateEntries(context.Background(), []domain.AuditEntry{
+		{SessionID: testSessionID, UserID: testUserID, Action: "edit", Timestamp: historical},
+		{SessionID: testSessionID, UserID: testUserID, Action: "merge"},
+	})
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAuditRepository_PerformedBy(t *testing.T) {
+	t.Run("written_only_when_delegated", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		expectedRows := []auditLogRow{
+			{SessionID: testSessionID, UserID: testUserID, Action: "edit", PerformedBy: "admin-789"},
+			{SessionID: testSessionID, UserID: testUserID, Action: "merge"},
+		}
+		// Inserted rows come back keyed by the insert's aliased select
+		mockClient.On("Post", mock.Anything, insertEndpoint, expectedRows).
+			Return([]byte(`[`+
+				`{"id":"audit-101","sessionId":"`+testSessionID+`","userId":"`+testUserID+`","action":"edit","timestamp":"2023-12-01T10:30:00Z","deletedAt":null,"performedBy":"admin-789"},`+
+				`{"id":"audit-102","sessionId":"`+testSessionID+`","userId":"`+testUserID+`","action":"merge","timestamp":"2023-12-01T10:30:00Z","deletedAt":null,"performedBy":null}`+
+				`]`), nil)
+
+		created, err := repo.CreateEntries(context.Background(), []domain.AuditEntry{
+			{SessionID: testSessionID, UserID: testUserID, Action: "edit", PerformedBy: "admin-789"},
+			{SessionID: testSessionID, UserID: testUserID, Action: "merge"},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "admin-789", created[0].PerformedBy)
+		assert.Equal(t, testUserID, created[0].UserID)
+		assert.Empty(t, created[1].PerformedBy)
+		mockClient.AssertExpectations(t)
+
+		// Self-performed rows don't send the column at all
+		data, err := json.Marshal(expectedRows[1])
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "performed_by")
+	})
+
+	t.Run("read_back", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		// performed_by is only returned under its json name because the select aliases it
+		mockClient.On("Get", mock.Anything, "/audit_logs", mock.MatchedBy(func(params map[string]string) bool {
+			return params["select"] == allColumns
+		})).Return([]byte(`[{"id":"audit-101","sessionId":"`+testSessionID+`","userId":"`+testUserID+`","action":"edit","timestamp":"2023-12-01T10:30:00Z","deletedAt":null,"performedBy":"admin-789"}]`), 1, nil)
+
+		entry, err := repo.GetEntry(context.Background(), testSessionID, "audit-101")
+
+		require.NoError(t, err)
+		assert.Equal(t, "admin-789", entry.PerformedBy)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("selected_by_field_name", func(t *testing.T) {
+		assert.Equal(t, "id,performedBy:performed_by", selectColumns([]string{"id", "performedBy"}))
+	})
+}
+
+func TestAuditRepository_CountActionsSince(t *testing.T) {
+	since := time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)
+	expectedParams := map[string]string{
+		"session_id": "eq." + testSessionID,
+		"timestamp":  "gte.2023-12-01T00:00:00Z",
+		"deleted_at": "is.null",
+		"select":     "action",
+	}
+
+	mockClient := &MockSupabaseClient{}
+	repo := NewAuditRepository(mockClient, zap.NewNop())
+	mockClient.On("Get", mock.Anything, "/audit_logs", expectedParams).
+		Return([]byte(`[{"action":"edit"},{"action":"merge"},{"action":"edit"}]`), 3, nil)
+
+	counts, err := repo.CountActionsSince(context.Background(), testSessionID, since)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"edit": 2, "merge": 1}, counts)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAuditRepository_FindActivityBounds(t *testing.T) {
+	paramsFor := func(order string) map[string]string {
+		return map[string]string{
+			"session_id": "eq." + testSessionID,
+			"order":      order,
+			"limit":      "1",
+			"deleted_at": "is.null",
+			"select":     "timestamp",
+		}
+	}
+
+	t.Run("success_both_timestamps", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+		mockClient.On("Get", mock.Anything, "/audit_logs", paramsFor("timestamp.asc")).
+			Return([]byte(`[{"timestamp":"2023-11-01T09:00:00Z"}]`), 1, nil)
+		mockClient.On("Get", mock.Anything, "/audit_logs", paramsFor("timestamp.desc")).
+			Return([]byte(`[{"timestamp":"2023-12-01T10:30:00Z"}]`), 1, nil)
+
+		oldest, latest, err := repo.FindActivityBounds(context.Background(), testSessionID, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2023, 11, 1, 9, 0, 0, 0, time.UTC), *oldest)
+		assert.Equal(t, time.Date(2023, 12, 1, 10, 30, 0, 0, time.UTC), *latest)
+		assert.True(t, oldest.Before(*latest))
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("success_excluding_actions", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+		for _, order := range []string{"timestamp.asc", "timestamp.desc"} {
+			params := paramsFor(order)
+			params["action"] = "not.in.(export,share)"
+			mockClient.On("Get", mock.Anything, "/audit_logs", params).
+				Return([]byte(`[{"timestamp":"2023-11-15T09:00:00Z"}]`), 1, nil)
+		}
+
+		oldest, latest, err := repo.FindActivityBounds(context.Background(), testSessionID, []string{"export", "share"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2023, 11, 15, 9, 0, 0, 0, time.UTC), *oldest)
+		assert.Equal(t, time.Date(2023, 11, 15, 9, 0, 0, 0, time.UTC), *latest)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("success_no_activity", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+		mockClient.On("Get", mock.Anything, "/audit_logs", mock.Anything).Return([]byte(`[]`), 0, nil)
+
+		oldest, latest, err := repo.FindActivityBounds(context.Background(), testSessionID, nil)
+
+		assert.NoError(t, err)
+		assert.Nil(t, oldest)
+		assert.Nil(t, latest)
+	})
+
+	t.Run("error_client_failure", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+		mockClient.On("Get", mock.Anything, "/audit_logs", mock.Anything).
+			Return([]byte{}, 0, errors.New("network error"))
+
+		_, _, err := repo.FindActivityBounds(context.Background(), testSessionID, nil)
+
+		assert.EqualError(t, err, "failed to fetch activity timestamp: service temporarily unavailable: network error")
+		assert.ErrorIs(t, err, domain.ErrServiceUnavailable)
+	})
+}
+
+func TestAuditRepository_Summary_IgnoresTombstones(t *testing.T) {
+	mockClient := &MockSupabaseClient{}
+	repo := NewAuditRepository(mockClient, zap.NewNop())
+
+	// Only the live edit is returned once deleted rows are filtered out; a
+	// tombstoned merge would otherwise be counted and widen the bounds
+	mockClient.On("Get", mock.Anything, "/audit_logs", mock.MatchedBy(func(params map[string]string) bool {
+		return params["deleted_at"] == "is.null" && params["select"] == "action"
+	})).Return([]byte(`[{"action":"edit"}]`), 1, nil)
+	mockClient.On("Get", mock.Anything, "/audit_logs", mock.MatchedBy(func(params map[string]string) bool {
+		return params["deleted_at"] == "is.null" && params["select"] == "timestamp"
+	})).Return([]byte(`[{"timestamp":"2023-11-15T09:00:00Z"}]`), 1, nil)
+	mockClient.On("Get", mock.Anything, "/audit_logs", mock.MatchedBy(func(params map[string]string) bool {
+		return params["select"] == "action"
+	})).Return([]byte(`[{"action":"edit"},{"action":"merge"}]`), 2, nil)
+	mockClient.On("Get", mock.Anything, "/audit_logs", mock.Anything).
+		Return([]byte(`[{"timestamp":"2023-12-20T09:00:00Z"}]`), 1, nil)
+
+	counts, err := repo.CountActionsSince(context.Background(), testSessionID, time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"edit": 1}, counts)
+
+	oldest, latest, err := repo.FindActivityBounds(context.Background(), testSessionID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 11, 15, 9, 0, 0, 0, time.UTC), *oldest)
+	assert.Equal(t, time.Date(2023, 11, 15, 9, 0, 0, 0, time.UTC), *latest)
+}
+
+func TestAuditRepository_ListSessionsByUser(t *testing.T) {
+	t.Run("success_ordered_by_activity", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		expectedParams := map[string]string{
+			"user_id":               "eq." + testUserID,
+			"select":                "id,user_id,audit_logs(timestamp)",
+			"audit_logs.order":      "timestamp.desc",
+			"audit_logs.limit":      "1",
+			"audit_logs.deleted_at": "is.null",
+		}
+		data := []byte(`[
+			{"id":"session-idle","user_id":"` + testUserID + `","audit_logs":[]},
+			{"id":"session-old","user_id":"` + testUserID + `","audit_logs":[{"timestamp":"2023-11-01T09:00:00Z"}]},
+			{"id":"session-new","user_id":"` + testUserID + `","audit_logs":[{"timestamp":"2023-12-01T10:30:00Z"}]}
+		]`)
+		mockClient.On("Get", mock.Anything, "/sessions", expectedParams).Return(data, 0, nil)
+
+		sessions, err := repo.ListSessionsByUser(context.Background(), testUserID)
+
+		require.NoError(t, err)
+		require.Len(t, sessions, 3)
+		assert.Equal(t, "session-new", sessions[0].SessionID)
+		assert.Equal(t, "session-old", sessions[1].SessionID)
+		assert.Equal(t, "session-idle", sessions[2].SessionID)
+		assert.Equal(t, time.Date(2023, 12, 1, 10, 30, 0, 0, time.UTC), *sessions[0].LastActivity)
+		assert.Nil(t, sessions[2].LastActivity)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("error_upstream_unavailable", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+		mockClient.On("Get", mock.Anything, "/sessions", mock.Anything).
+			Return([]byte{}, 503, &SupabaseHTTPError{Status: 503})
+
+		_, err := repo.ListSessionsByUser(context.Background(), testUserID)
+
+		assert.ErrorIs(t, err, domain.ErrServiceUnavailable)
+	})
+}
+
+func TestAuditRepository_ValidateShareTokens(t *testing.T) {
+	const otherSessionID = "test-session-456"
+	const expiredSessionID = "test-session-789"
+
+	mockClient := &MockSupabaseClient{}
+	repo := NewAuditRepository(mockClient, zap.NewNop())
+
+	expectedParams := map[string]string{
+		"token":      "eq.share-token",
+		"session_id": "in.(" + testSessionID + "," + otherSessionID + "," + expiredSessionID + ")",
+		"select":     "token,session_id,expires_at",
+	}
+	data := []byte(`[
+		{"token":"share-token","session_id":"` + testSessionID + `","expires_at":"2099-01-01T00:00:00Z"},
+		{"token":"share-token","session_id":"` + otherSessionID + `"},
+		{"token":"share-token","session_id":"` + expiredSessionID + `","expires_at":"2000-01-01T00:00:00Z"}
+	]`)
+	mockClient.On("Get", mock.Anything, "/session_shares", expectedParams).Return(data, 0, nil).Once()
+
+	valid, err := repo.ValidateShareTokens(context.Background(), "share-token", []string{testSessionID, otherSessionID, expiredSessionID})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{
+		testSessionID:  time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC),
+		otherSessionID: {},
+	}, valid)
+	mockClient.AssertExpectations(t)
+	mockClient.AssertNumberOfCalls(t, "Get", 1)
+}
+
+func TestAuditRepository_ListActiveShares(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		activeOnly := mock.MatchedBy(func(params map[string]string) bool {
+			return params["session_id"] == "eq."+testSessionID &&
+				strings.HasPrefix(params["or"], "(expires_at.is.null,expires_at.gt.") &&
+				params["order"] == "expires_at.asc.nullslast"
+		})
+		data := []byte(`[
+			{"token":"share-token-1","session_id":"` + testSessionID + `","expires_at":"2030-01-01T00:00:00Z"},
+			{"token":"share-token-2","session_id":"` + testSessionID + `"}
+		]`)
+		mockClient.On("Get", mock.Anything, "/session_shares", activeOnly).Return(data, 0, nil)
+
+		shares, err := repo.ListActiveShares(context.Background(), testSessionID)
+
+		require.NoError(t, err)
+		require.Len(t, shares, 2)
+		assert.Equal(t, "share-token-1", shares[0].Token)
+		assert.Equal(t, "2030-01-01T00:00:00Z", shares[0].ExpiresAt)
+		assert.Empty(t, shares[1].ExpiresAt)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("error_upstream_unavailable", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+		mockClient.On("Get", mock.Anything, "/session_shares", mock.Anything).
+			Return([]byte{}, 503, &SupabaseHTTPError{Status: 503})
+
+		_, err := repo.ListActiveShares(context.Background(), testSessionID)
+
+		assert.ErrorIs(t, err, domain.ErrServiceUnavailable)
+	})
+}
+
+func TestAuditRepository_FindBySessionID_SinceID(t *testing.T) {
+	const sinceID = "550e8400-e29b-41d4-a716-446655440099"
+	lookupParams := map[string]string{
+		"id":         "eq." + sinceID,
+		"session_id": "eq." + testSessionID,
+		"deleted_at": "is.null",
+		"limit":      "1",
+		"select":     "timestamp",
+	}
+
+	t.Run("returns_newer_entries_ascending", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Get", mock.Anything, "/audit_logs", lookupParams).
+			Return([]byte(`[{"timestamp":"2024-01-15T10:30:00.123456Z"}]`), 0, nil)
+
+		entries := createTestAuditEntries()[:2]
+		data, _ := json.Marshal(entries)
+		mockClient.On("GetRange", mock.Anything, "/audit_logs", map[string]string{
+			"session_id": "eq." + testSessionID,
+			"deleted_at": "is.null",
+			"order":      "timestamp.asc,id.asc",
+			"select":     allColumns,
+			"and":        "(or(timestamp.gt.2024-01-15T10:30:00.123456Z,and(timestamp.eq.2024-01-15T10:30:00.123456Z,id.gt." + sinceID + ")))",
+		}, 0, 10).Return(data, 2, nil)
+
+		result, count, err := repo.FindBySessionID(context.Background(), testSessionID, 10, 0, domain.HistoryFilter{SinceID: sinceID})
+
+		assert.NoError(t, err)
+		assert.Equal(t, entries, result)
+		assert.Equal(t, 2, count)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("same_timestamp_entries_ordered_by_id", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Get", mock.Anything, "/audit_logs", lookupParams).
+			Return([]byte(`[{"timestamp":"2024-01-15T10:30:00Z"}]`), 0, nil)
+
+		// Entries written in the same instant as the cursor, and each other,
+		// come back in ID order on every read
+		ts := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+		entries := []domain.AuditEntry{
+			{ID: "550e8400-e29b-41d4-a716-4466554400a0", SessionID: testSessionID, Action: "edit", Timestamp: ts},
+			{ID: "550e8400-e29b-41d4-a716-4466554400b0", SessionID: testSessionID, Action: "edit", Timestamp: ts},
+			{ID: "550e8400-e29b-41d4-a716-446655440001", SessionID: testSessionID, Action: "merge", Timestamp: ts.Add(time.Second)},
+		}
+		data, _ := json.Marshal(entries)
+		mockClient.On("GetRange", mock.Anything, "/audit_logs", mock.MatchedBy(func(params map[string]string) bool {
+			return params["order"] == "timestamp.asc,id.asc" &&
+				params["and"] == "(or(timestamp.gt.2024-01-15T10:30:00Z,and(timestamp.eq.2024-01-15T10:30:00Z,id.gt."+sinceID+")))"
+		}), 0, 10).Return(data, 3, nil)
+
+		for i := 0; i < 2; i++ {
+			result, _, err := repo.FindBySessionID(context.Background(), testSessionID, 10, 0, domain.HistoryFilter{SinceID: sinceID})
+			assert.NoError(t, err)
+			assert.Equal(t, entries, result)
+		}
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("foreign_entry_not_found", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		// The entry exists but belongs to another session, so the scoped lookup is empty
+		mockClient.On("Get", mock.Anything, "/audit_logs", lookupParams).
+			Return([]byte(`[]`), 0, nil)
+
+		result, count, err := repo.FindBySessionID(context.Background(), testSessionID, 10, 0, domain.HistoryFilter{SinceID: sinceID})
+
+		assert.ErrorIs(t, err, domain.ErrEntryNotFound)
+		assert.Nil(t, result)
+		assert.Equal(t, 0, count)
+		assert.Equal(t, http.StatusNotFound, domain.ToAPIError(err).Status)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestAuditRepository_FindBySessionID_TimeRange(t *testing.T) {
+	from := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 16, 5, 30, 0, 0, time.FixedZone("IST", 5*3600+1800))
+
+	tests := []struct {
+		name           string
+		filter         domain.HistoryFilter
+		expectedParams map[string]string
+	}{
+		{
+			name:   "from_only",
+			filter: domain.HistoryFilter{From: from},
+			expectedParams: map[string]string{
+				"timestamp": "gte.2024-01-15T00:00:00Z",
+			},
+		},
+		{
+			name:   "to_only_converted_to_utc",
+			filter: domain.HistoryFilter{To: to},
+			expectedParams: map[string]string{
+				"timestamp": "lt.2024-01-16T00:00:00Z",
+			},
+		},
+		{
+			name:   "from_and_to",
+			filter: domain.HistoryFilter{From: from, To: to},
+			expectedParams: map[string]string{
+				"and": "(timestamp.gte.2024-01-15T00:00:00Z,timestamp.lt.2024-01-16T00:00:00Z)",
+			},
+		},
+		{
+			name: "from_and_to_with_composite_filter",
+			filter: domain.HistoryFilter{From: from, To: to, Where: &domain.FilterExpr{Logic: "or", Children: []domain.FilterExpr{
+				{Column: "action", Operator: "eq", Value: "edit"},
+				{Column: "userId", Operator: "eq", Value: "user-1"},
+			}}},
+			expectedParams: map[string]string{
+				"and": "(timestamp.gte.2024-01-15T00:00:00Z,timestamp.lt.2024-01-16T00:00:00Z,or(action.eq.edit,user_id.eq.user-1))",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockSupabaseClient{}
+			repo := NewAuditRepository(mockClient, zap.NewNop())
+
+			expectedParams := map[string]string{
+				"session_id": "eq." + testSessionID,
+				"deleted_at": "is.null",
+				"order":      "timestamp.desc,id.desc",
+				"select":     allColumns,
+			}
+			for key, value := range tt.expectedParams {
+				expectedParams[key] = value
+			}
+			mockClient.On("GetRange", mock.Anything, "/audit_logs", expectedParams, 0, 10).Return([]byte(`[]`), 0, nil)
+
+			_, _, err := repo.FindBySessionID(context.Background(), testSessionID, 10, 0, tt.filter)
+
+			assert.NoError(t, err)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditRepository_FindBySessionID_CompositeFilter(t *testing.T) {
+	mockClient := &MockSupabaseClient{}
+	repo := NewAuditRepository(mockClient, zap.NewNop())
+
+	where, err := domain.ParseFilterExpr("or(action.eq.edit,and(action.eq.merge,userId.eq.user-1))")
+	require.NoError(t, err)
+
+	expectedParams := map[string]string{
+		"session_id": "eq." + testSessionID,
+		"deleted_at": "is.null",
+		"order":      "timestamp.desc,id.desc",
+		"select":     allColumns,
+		"and":        "(or(action.eq.edit,and(action.eq.merge,user_id.eq.user-1)))",
+	}
+	mockClient.On("GetRange", mock.Anything, "/audit_logs", expectedParams, 0, 10).Return([]byte(`[]`), 0, nil)
+
+	_, _, err = repo.FindBySessionID(context.Background(), testSessionID, 10, 0, domain.HistoryFilter{Where: where})
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAuditRepository_FindBySessionID_CompositeFilterRevalidated(t *testing.T) {
+	mockClient := &MockSupabaseClient{}
+	repo := NewAuditRepository(mockClient, zap.NewNop())
+
+	// Built directly rather than parsed, so the repository is the last line of defence
+	where := &domain.FilterExpr{Column: "session_id", Operator: "neq", Value: "x"}
+
+	_, _, err := repo.FindBySessionID(context.Background(), testSessionID, 10, 0, domain.HistoryFilter{Where: where})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidFilter)
+	mockClient.AssertNotCalled(t, "GetRange")
+}
+
+func TestAuditRepository_FindBySessionID_IncludeSession(t *testing.T) {
+	tests := []struct {
+		name            string
+		filter          domain.HistoryFilter
+		expectedSelect  string
+		response        string
+		expectedSession *domain.SessionMetadata
+	}{
+		{
+			name:           "not_requested",
+			filter:         domain.HistoryFilter{},
+			expectedSelect: allColumns,
+			response:       `[{"id":"entry-1","action":"edit"}]`,
+		},
+		{
+			name:            "requested",
+			filter:          domain.HistoryFilter{IncludeSession: true},
+			expectedSelect:  allColumns + ",sessions(title,user_id)",
+			response:        `[{"id":"entry-1","action":"edit","sessions":{"title":"Q3 deck","user_id":"owner-1"}}]`,
+			expectedSession: &domain.SessionMetadata{Title: "Q3 deck", OwnerID: "owner-1"},
+		},
+		{
+			name:            "requested_with_fields",
+			filter:          domain.HistoryFilter{Fields: []string{"id", "action"}, IncludeSession: true},
+			expectedSelect:  "id,action,sessions(title,user_id)",
+			response:        `[{"id":"entry-1","action":"edit","sessions":{"title":"Q3 deck","user_id":"owner-1"}}]`,
+			expectedSession: &domain.SessionMetadata{Title: "Q3 deck", OwnerID: "owner-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockSupabaseClient{}
+			repo := NewAuditRepository(mockClient, zap.NewNop())
+
+			expectedParams := map[string]string{
+				"session_id": "eq." + testSessionID,
+				"deleted_at": "is.null",
+				"order":      "timestamp.desc,id.desc",
+				"select":     tt.expectedSelect,
+			}
+			mockClient.On("GetRange", mock.Anything, "/audit_logs", expectedParams, 0, 10).Return([]byte(tt.response), 1, nil)
+
+			entries, _, err := repo.FindBySessionID(context.Background(), testSessionID, 10, 0, tt.filter)
+
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			assert.Equal(t, "entry-1", entries[0].ID)
+			assert.Equal(t, tt.expectedSession, entries[0].Session)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSelectColumns_AliasesEveryColumn(t *testing.T) {
+	// Unaliased snake_case columns would be dropped when decoding into AuditEntry
+	assert.Equal(t, allColumns, selectColumns(nil))
+	for field, column := range auditLogColumns {
+		if field != column {
+			assert.Contains(t, strings.Split(selectColumns(nil), ","), field+":"+column)
+		}
+	}
+}
+
+func TestAuditRepository_FindBySessionID_Deleted(t *testing.T) {
+	deletedAt := time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		filter          domain.HistoryFilter
+		expectedParams  map[string]string
+		response        string
+		expectedDeleted *time.Time
+	}{
+		{
+			name:   "tombstones_hidden_by_default",
+			filter: domain.HistoryFilter{},
+			expectedParams: map[string]string{
+				"session_id": "eq." + testSessionID,
+				"deleted_at": "is.null",
+				"order":      "timestamp.desc,id.desc",
+				"select":     allColumns,
+			},
+			response: `[{"id":"entry-1","sessionId":"` + testSessionID + `","userId":"` + testUserID + `","action":"edit","timestamp":"2024-01-15T10:00:00Z","deletedAt":null,"performedBy":null}]`,
+		},
+		{
+			name:   "tombstones_included",
+			filter: domain.HistoryFilter{IncludeDeleted: true},
+			expectedParams: map[string]string{
+				"session_id": "eq." + testSessionID,
+				"order":      "timestamp.desc,id.desc",
+				"select":     allColumns,
+			},
+			response:        `[{"id":"entry-1","sessionId":"` + testSessionID + `","userId":"` + testUserID + `","action":"edit","timestamp":"2024-01-15T10:00:00Z","deletedAt":"2024-01-15T11:00:00Z","performedBy":null}]`,
+			expectedDeleted: &deletedAt,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockSupabaseClient{}
+			repo := NewAuditRepository(mockClient, zap.NewNop())
+
+			mockClient.On("GetRange", mock.Anything, "/audit_logs", tt.expectedParams, 0, 10).Return([]byte(tt.response), 1, nil)
+
+			entries, _, err := repo.FindBySessionID(context.Background(), testSessionID, 10, 0, tt.filter)
+
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			assert.Equal(t, tt.expectedDeleted, entries[0].DeletedAt)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditRepository_CountBySessionID(t *testing.T) {
+	t.Run("counts_without_fetching_rows", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		expectedParams := map[string]string{
+			"session_id": "eq." + testSessionID,
+			"deleted_at": "is.null",
+			"action":     "in.(edit,merge)",
+			"select":     "id",
+			"limit":      "0",
+		}
+		mockClient.On("Get", mock.Anything, "/audit_logs", expectedParams).Return([]byte("[]"), 42, nil)
+
+		count, err := repo.CountBySessionID(context.Background(), testSessionID, domain.HistoryFilter{
+			Actions:        []string{"edit", "merge"},
+			Fields:         []string{"id", "action"},
+			IncludeSession: true,
+			CountOnly:      true,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 42, count)
+		mockClient.AssertExpectations(t)
+		mockClient.AssertNotCalled(t, "GetRange", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("upstream_error", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Get", mock.Anything, "/audit_logs", mock.Anything).
+			Return([]byte(nil), http.StatusServiceUnavailable, &SupabaseError{Message: "down", Status: http.StatusServiceUnavailable})
+
+		count, err := repo.CountBySessionID(context.Background(), testSessionID, domain.HistoryFilter{CountOnly: true})
+
+		require.Error(t, err)
+		assert.Zero(t, count)
+		var coder StatusCoder
+		require.ErrorAs(t, err, &coder)
+		assert.Equal(t, http.StatusServiceUnavailable, coder.StatusCode())
+	})
+}
+
+func TestAuditRepository_UpstreamStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		clientErr      error
+		expectedStatus int
+	}{
+		{name: "supabase_5xx", status: http.StatusServiceUnavailable, clientErr: &SupabaseError{Message: "upstream down", Status: http.StatusServiceUnavailable}, expectedStatus: http.StatusServiceUnavailable},
+		{name: "non_json_5xx", status: http.StatusBadGateway, clientErr: &SupabaseHTTPError{Status: http.StatusBadGateway, Body: "bad gateway"}, expectedStatus: http.StatusServiceUnavailable},
+		{name: "transport_failure", clientErr: errors.New("connection refused"), expectedStatus: http.StatusServiceUnavailable},
+		{name: "query_timeout", clientErr: fmt.Errorf("request failed: %w", context.DeadlineExceeded), expectedStatus: http.StatusGatewayTimeout},
+		{name: "supabase_4xx", status: http.StatusBadRequest, clientErr: &SupabaseError{Message: "column does not exist", Status: http.StatusBadRequest}, expectedStatus: http.StatusInternalServerError},
+	}
+
+	// Every read behind the summary and share-token checks maps upstream failures
+	reads := map[string]func(AuditRepository) error{
+		"CountActionsSince": func(repo AuditRepository) error {
+			_, err := repo.CountActionsSince(context.Background(), testSessionID, time.Now())
+			return err
+		},
+		"FindActivityBounds": func(repo AuditRepository) error {
+			_, _, err := repo.FindActivityBounds(context.Background(), testSessionID, nil)
+			return err
+		},
+		"ValidateShareToken": func(repo AuditRepository) error {
+			_, _, err := repo.ValidateShareToken(context.Background(), "share-token", testSessionID)
+			return err
+		},
+		"ValidateShareTokens": func(repo AuditRepository) error {
+			_, err := repo.ValidateShareTokens(context.Background(), "share-token", []string{testSessionID})
+			return err
+		},
+	}
+
+	for readName, read := range reads {
+		for _, tt := range tests {
+			t.Run(readName+"/"+tt.name, func(t *testing.T) {
+				mockClient := &MockSupabaseClient{}
+				repo := NewAuditRepository(mockClient, zap.NewNop())
+
+				mockClient.On("Get", mock.Anything, mock.Anything, mock.Anything).Return([]byte(nil), tt.status, tt.clientErr)
+
+				err := read(repo)
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tt.clientErr)
+				assert.Equal(t, tt.expectedStatus, domain.ToAPIError(err).Status)
+			})
+		}
+	}
+
+	// Writes map them the same way; Post carries the status only in the error
+	writes := map[string]func(AuditRepository) error{
+		"CreateEntry": func(repo AuditRepository) error {
+			_, err := repo.CreateEntry(context.Background(), &domain.AuditEntry{SessionID: testSessionID, UserID: testUserID, Action: "edit"})
+			return err
+		},
+		"CreateEntries": func(repo AuditRepository) error {
+			_, err := repo.CreateEntries(context.Background(), []domain.AuditEntry{{SessionID: testSessionID, UserID: testUserID, Action: "edit"}})
+			return err
+		},
+	}
+
+	for writeName, write := range writes {
+		for _, tt := range tests {
+			t.Run(writeName+"/"+tt.name, func(t *testing.T) {
+				mockClient := &MockSupabaseClient{}
+				repo := NewAuditRepository(mockClient, zap.NewNop())
+
+				mockClient.On("Post", mock.Anything, insertEndpoint, mock.Anything).Return([]byte(nil), tt.clientErr)
+
+				err := write(repo)
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tt.clientErr)
+				assert.Equal(t, tt.expectedStatus, domain.ToAPIError(err).Status)
+			})
+		}
+	}
+}
+
+func TestAuditRepository_FindBySessionID_Deduplicates(t *testing.T) {
+	const callers = 10
+
+	mockClient := &MockSupabaseClient{}
+	repo := NewAuditRepository(mockClient, zap.NewNop())
+
+	entries := createTestAuditEntries()
+	data, _ := json.Marshal(entries)
+	release := make(chan struct{})
+	mockClient.On("GetRange", mock.Anything, "/audit_logs", mock.Anything, 0, 10).
+		Run(func(mock.Arguments) { <-release }).
+		Return(data, 4, nil).Once()
+
+	var wg sync.WaitGroup
+	results := make(chan int, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, count, err := repo.FindBySessionID(context.Background(), testSessionID, 10, 0, domain.HistoryFilter{})
+			assert.NoError(t, err)
+			assert.Equal(t, entries, result)
+			results <- count
+		}()
+	}
+
+	// Give every caller time to join the in-flight query before it completes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for count := range results {
+		assert.Equal(t, 4, count)
+	}
+	mockClient.AssertNumberOfCalls(t, "GetRange", 1)
+	assert.Equal(t, int64(callers), repo.HistoryReads())
+	assert.Equal(t, int64(callers-1), repo.CoalescedHistoryReads())
+}
+
+func TestAuditRepository_FindBySessionID_LeaderCancelled(t *testing.T) {
+	mockClient := &MockSupabaseClient{}
+	repo := NewAuditRepository(mockClient, zap.NewNop())
+
+	entries := createTestAuditEntries()
+	data, _ := json.Marshal(entries)
+	started := make(chan struct{})
+	// The leader's query fails once its caller's context is cancelled
+	mockClient.On("GetRange", mock.Anything, "/audit_logs", mock.Anything, 0, 10).
+		Run(func(args mock.Arguments) {
+			close(started)
+			<-args.Get(0).(context.Context).Done()
+		}).
+		Return([]byte(nil), 0, context.Canceled).Once()
+	mockClient.On("GetRange", mock.Anything, "/audit_logs", mock.Anything, 0, 10).
+		Return(data, 2, nil).Once()
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, _, err := repo.FindBySessionID(leaderCtx, testSessionID, 10, 0, domain.HistoryFilter{})
+		leaderErr <- err
+	}()
+	<-started
+
+	type page struct {
+		entries []domain.AuditEntry
+		count   int
+		err     error
+	}
+	follower := make(chan page, 1)
+	go func() {
+		result, count, err := repo.FindBySessionID(context.Background(), testSessionID, 10, 0, domain.HistoryFilter{})
+		follower <- page{result, count, err}
+	}()
+
+	// Let the follower join the in-flight query before the leader goes away
+	require.Eventually(t, func() bool { return repo.HistoryReads() == 2 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	assert.ErrorIs(t, <-leaderErr, context.Canceled)
+	got := <-follower
+	require.NoError(t, got.err)
+	assert.Equal(t, entries, got.entries)
+	assert.Equal(t, 2, got.count)
+	mockClient.AssertNumberOfCalls(t, "GetRange", 2)
+}
+
+func TestAuditRepository_FindBySessionID_ErrorsNotShared(t *testing.T) {
+	mockClient := &MockSupabaseClient{}
+	repo := NewAuditRepository(mockClient, zap.NewNop())
+
+	mockClient.On("GetRange", mock.Anything, "/audit_logs", mock.Anything, 0, 10).
+		Return([]byte{}, 0, errors.New("network error")).Once()
+	mockClient.On("GetRange", mock.Anything, "/audit_logs", mock.Anything, 0, 10).
+		Return([]byte(`[]`), 0, nil).Once()
+
+	_, _, err := repo.FindBySessionID(context.Background(), testSessionID, 10, 0, domain.HistoryFilter{})
+	assert.Error(t, err)
+
+	// Once the failed call has returned, the next read goes back to Supabase
+	result, _, err := repo.FindBySessionID(context.Background(), testSessionID, 10, 0, domain.HistoryFilter{})
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+	mockClient.AssertNumberOfCalls(t, "GetRange", 2)
+}
+
 func TestNewAuditRepository(t *testing.T) {
 	mockClient := &MockSupabaseClient{}
 	logger := zap.NewNop()
@@ -4,12 +4,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"audit-service/internal/config"
+	"audit-service/internal/domain"
+	"audit-service/pkg/timing"
 
 	"go.uber.org/zap"
 )
@@ -17,15 +23,21 @@ import (
 // SupabaseClientInterface defines the interface for Supabase client operations
 type SupabaseClientInterface interface {
 	Get(ctx context.Context, endpoint string, queryParams map[string]string) ([]byte, int, error)
+	GetRange(ctx context.Context, endpoint string, queryParams map[string]string, offset, limit int) ([]byte, int, error)
 	Post(ctx context.Context, endpoint string, payload interface{}) ([]byte, error)
 }
 
 // SupabaseClient handles communication with Supabase REST API
 type SupabaseClient struct {
+	// baseURL takes writes; readURL serves GET queries and equals baseURL
+	// unless a read replica is configured
 	baseURL    string
+	readURL    string
 	httpClient *http.Client
 	headers    map[string]string
-	logger     *zap.Logger
+	// sem bounds concurrent requests; nil means unbounded
+	sem    chan struct{}
+	logger *zap.Logger
 }
 
 // NewSupabaseClient creates a new Supabase REST API client
@@ -37,17 +49,63 @@ func NewSupabaseClient(cfg *config.Config, logger *zap.Logger) *SupabaseClient {
 			MaxIdleConns:        cfg.HTTPMaxIdleConns,
 			MaxIdleConnsPerHost: cfg.HTTPMaxConnsPerHost,
 			IdleConnTimeout:     cfg.HTTPIdleConnTimeout,
+			// The transport asks for gzip and decompresses responses itself, as long
+			// as no Accept-Encoding header is set on the request
+			DisableCompression: false,
 		},
 	}
 
+	var sem chan struct{}
+	if cfg.HTTPMaxConcurrent > 0 {
+		sem = make(chan struct{}, cfg.HTTPMaxConcurrent)
+	}
+
+	baseURL := fmt.Sprintf("%s/rest/v1", cfg.SupabaseURL)
+	readURL := baseURL
+	if cfg.SupabaseReadURL != "" {
+		readURL = fmt.Sprintf("%s/rest/v1", cfg.SupabaseReadURL)
+	}
+
 	return &SupabaseClient{
-		baseURL:    fmt.Sprintf("%s/rest/v1", cfg.SupabaseURL),
+		baseURL:    baseURL,
+		readURL:    readURL,
 		httpClient: httpClient,
 		headers:    cfg.GetSupabaseHeaders(),
+		sem:        sem,
 		logger:     logger,
 	}
 }
 
+// InFlight returns the number of requests currently holding a concurrency slot
+func (c *SupabaseClient) InFlight() int {
+	return len(c.sem)
+}
+
+// MaxConcurrent returns the concurrency limit, or 0 when unbounded
+func (c *SupabaseClient) MaxConcurrent() int {
+	return cap(c.sem)
+}
+
+// acquire waits for a free concurrency slot or for ctx to be done
+func (c *SupabaseClient) acquire(ctx context.Context) error {
+	if c.sem == nil {
+		return nil
+	}
+	select {
+	case c.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for a free connection slot: %w", ctx.Err())
+	}
+}
+
+// release frees a slot taken by acquire
+func (c *SupabaseClient) release() {
+	if c.sem != nil {
+		<-c.sem
+	}
+}
+
 // SupabaseResponse represents a generic Supabase API response
 type SupabaseResponse struct {
 	Data  json.RawMessage `json:"data"`
@@ -55,12 +113,18 @@ type SupabaseResponse struct {
 	Count int             `json:"count,omitempty"`
 }
 
+// StatusCoder is implemented by errors that carry the HTTP status Supabase responded with
+type StatusCoder interface {
+	StatusCode() int
+}
+
 // SupabaseError represents an error from Supabase
 type SupabaseError struct {
 	Message string `json:"message"`
 	Details string `json:"details,omitempty"`
 	Hint    string `json:"hint,omitempty"`
 	Code    string `json:"code,omitempty"`
+	Status  int    `json:"-"`
 }
 
 // Error implements the error interface
@@ -68,8 +132,47 @@ func (e *SupabaseError) Error() string {
 	return e.Message
 }
 
-// Get performs a GET request to Supabase
+// StatusCode returns the HTTP status of the failed response
+func (e *SupabaseError) StatusCode() int {
+	return e.Status
+}
+
+// SupabaseHTTPError represents a failed response whose body is not a Supabase error
+type SupabaseHTTPError struct {
+	Status int
+	Body   string
+}
+
+// Error implements the error interface
+func (e *SupabaseHTTPError) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s", e.Status, e.Body)
+}
+
+// StatusCode returns the HTTP status of the failed response
+func (e *SupabaseHTTPError) StatusCode() int {
+	return e.Status
+}
+
+// Get performs a GET request to Supabase, against the read URL when one is configured
 func (c *SupabaseClient) Get(ctx context.Context, endpoint string, queryParams map[string]string) ([]byte, int, error) {
+	return c.get(ctx, endpoint, queryParams, nil)
+}
+
+// GetRange performs a GET request that pages with the Range header rather than
+// limit/offset query parameters. A page past the end yields an empty array.
+func (c *SupabaseClient) GetRange(ctx context.Context, endpoint string, queryParams map[string]string, offset, limit int) ([]byte, int, error) {
+	if limit <= 0 {
+		return c.get(ctx, endpoint, queryParams, nil)
+	}
+	return c.get(ctx, endpoint, queryParams, map[string]string{
+		"Range-Unit": "items",
+		"Range":      fmt.Sprintf("%d-%d", offset, offset+limit-1),
+	})
+}
+
+// get performs a GET request with optional extra headers. The returned count is
+// the total from Content-Range, or the HTTP status when the request failed.
+func (c *SupabaseClient) get(ctx context.Context, endpoint string, queryParams map[string]string, extraHeaders map[string]string) ([]byte, int, error) {
 	// Build URL with query parameters
 	fullURL, err := c.buildURL(endpoint, queryParams)
 	if err != nil {
@@ -86,6 +189,9 @@ func (c *SupabaseClient) Get(ctx context.Context, endpoint string, queryParams m
 	for key, value := range c.headers {
 		req.Header.Set(key, value)
 	}
+	for key, value := range extraHeaders {
+		req.Header.Set(key, value)
+	}
 
 	// Log request
 	c.logger.Debug("making supabase request",
@@ -93,7 +199,15 @@ func (c *SupabaseClient) Get(ctx context.Context, endpoint string, queryParams m
 		zap.String("url", fullURL),
 	)
 
-	// Execute request
+	// Execute request once a concurrency slot is free
+	if err := c.acquire(ctx); err != nil {
+		return nil, 0, err
+	}
+	defer c.release()
+
+	start := time.Now()
+	defer timing.Record(ctx, "supabase", start)
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, 0, fmt.Errorf("request failed: %w", err)
@@ -101,9 +215,9 @@ func (c *SupabaseClient) Get(ctx context.Context, endpoint string, queryParams m
 	defer resp.Body.Close()
 
 	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	body, err := readBody(ctx, resp.Body)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to read response: %w", err)
+		return nil, 0, err
 	}
 
 	// Log response
@@ -112,24 +226,43 @@ func (c *SupabaseClient) Get(ctx context.Context, endpoint string, queryParams m
 		zap.Int("body_size", len(body)),
 	)
 
+	// Extract count from headers if available
+	count := parseContentRangeTotal(resp.Header.Get("Content-Range"))
+
+	// A requested range starting past the last row is not an error for paging
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && extraHeaders["Range"] != "" {
+		return []byte("[]"), count, nil
+	}
+
 	// Check for errors
 	if resp.StatusCode >= 400 {
-		var supErr SupabaseError
-		if err := json.Unmarshal(body, &supErr); err == nil && supErr.Message != "" {
-			return nil, resp.StatusCode, &supErr
-		}
-		return nil, resp.StatusCode, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+		err := parseErrorResponse(resp.StatusCode, body)
+		c.logError(http.MethodGet, endpoint, resp.StatusCode, err)
+		return nil, resp.StatusCode, rateLimitError(resp, err)
 	}
 
-	// Extract count from headers if available
-	count := 0
-	if contentRange := resp.Header.Get("Content-Range"); contentRange != "" {
-		// Parse count from Content-Range header (e.g., "0-9/100")
-		var rangeStart, rangeEnd int
-		fmt.Sscanf(contentRange, "%d-%d/%d", &rangeStart, &rangeEnd, &count)
+	return body, count, nil
+}
+
+// parseContentRangeTotal returns the total from a Content-Range header such as
+// "0-9/100" or "*/0". Unknown totals ("0-9/*") are reported as 0.
+func parseContentRangeTotal(contentRange string) int {
+	_, total, found := strings.Cut(contentRange, "/")
+	if !found {
+		return 0
 	}
+	count, err := strconv.Atoi(total)
+	if err != nil {
+		return 0
+	}
+	return count
+}
 
-	return body, count, nil
+// Ping checks that Supabase answers authenticated queries by reading at most
+// one session ID. With a read URL configured, that is the endpoint checked.
+func (c *SupabaseClient) Ping(ctx context.Context) error {
+	_, _, err := c.get(ctx, "/sessions", map[string]string{"select": "id", "limit": "1"}, nil)
+	return err
 }
 
 // Post performs a POST request to Supabase
@@ -153,8 +286,18 @@ func (c *SupabaseClient) Post(ctx context.Context, endpoint string, payload inte
 	for key, value := range c.headers {
 		req.Header.Set(key, value)
 	}
+	// Ask PostgREST to return the inserted rows
+	req.Header.Set("Prefer", "return=representation")
+
+	// Execute request once a concurrency slot is free
+	if err := c.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer c.release()
+
+	start := time.Now()
+	defer timing.Record(ctx, "supabase", start)
 
-	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -162,26 +305,103 @@ func (c *SupabaseClient) Post(ctx context.Context, endpoint string, payload inte
 	defer resp.Body.Close()
 
 	// Read response
-	body, err := io.ReadAll(resp.Body)
+	body, err := readBody(ctx, resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
 
 	// Check for errors
 	if resp.StatusCode >= 400 {
-		var supErr SupabaseError
-		if err := json.Unmarshal(body, &supErr); err == nil && supErr.Message != "" {
-			return nil, &supErr
-		}
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+		err := parseErrorResponse(resp.StatusCode, body)
+		c.logError(http.MethodPost, endpoint, resp.StatusCode, err)
+		return nil, rateLimitError(resp, err)
 	}
 
 	return body, nil
 }
 
-// buildURL constructs the full URL with query parameters
+// readBody reads a response body, closing it as soon as ctx is done so an
+// abandoned request does not keep reading. A read cut short by ctx returns the
+// context error rather than an ambiguous transport error.
+func readBody(ctx context.Context, body io.ReadCloser) ([]byte, error) {
+	stop := context.AfterFunc(ctx, func() { body.Close() })
+	defer stop()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("response read aborted: %w", ctxErr)
+		}
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return data, nil
+}
+
+// parseErrorResponse converts an error response body into a SupabaseError when possible,
+// falling back to a SupabaseHTTPError
+func parseErrorResponse(status int, body []byte) error {
+	var supErr SupabaseError
+	if err := json.Unmarshal(body, &supErr); err == nil && supErr.Message != "" {
+		supErr.Status = status
+		return &supErr
+	}
+	return &SupabaseHTTPError{Status: status, Body: string(body)}
+}
+
+// rateLimitError reports a 429 as the service being unavailable, carrying the
+// upstream Retry-After so callers can back off; other errors are returned as is
+func rateLimitError(resp *http.Response, err error) error {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return err
+	}
+	return &domain.RetryAfterError{
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		Err:        fmt.Errorf("%w: rate limited by supabase: %w", domain.ErrServiceUnavailable, err),
+	}
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP
+// date. Missing, invalid or past values yield zero.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// logError logs a failed request, flattening SupabaseError fields so they can be filtered on
+func (c *SupabaseClient) logError(method, endpoint string, status int, err error) {
+	fields := []zap.Field{
+		zap.String("method", method),
+		zap.String("endpoint", endpoint),
+		zap.Int("status", status),
+		zap.Error(err),
+	}
+
+	var supErr *SupabaseError
+	if errors.As(err, &supErr) {
+		fields = append(fields,
+			zap.String("supabase_code", supErr.Code),
+			zap.String("supabase_details", supErr.Details),
+			zap.String("supabase_hint", supErr.Hint),
+		)
+	}
+
+	c.logger.Error("supabase request failed", fields...)
+}
+
+// buildURL constructs the full read URL with query parameters
 func (c *SupabaseClient) buildURL(endpoint string, queryParams map[string]string) (string, error) {
-	baseURL := fmt.Sprintf("%s%s", c.baseURL, endpoint)
+	baseURL := fmt.Sprintf("%s%s", c.readURL, endpoint)
 
 	if len(queryParams) == 0 {
 		return baseURL, nil
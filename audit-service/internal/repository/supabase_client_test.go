@@ -1,17 +1,23 @@
 package repository
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
 	"audit-service/internal/config"
+	"audit-service/internal/domain"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestSupabaseClient_Get(t *testing.T) {
@@ -220,6 +226,7 @@ func TestSupabaseClient_Post(t *testing.T) {
 					assert.Equal(t, "POST", r.Method)
 					assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
 					assert.Equal(t, "test-key", r.Header.Get("apikey"))
+					assert.Equal(t, "return=representation", r.Header.Get("Prefer"))
 
 					// Verify payload
 					var payload map[string]interface{}
@@ -408,3 +415,466 @@ func TestNewSupabaseClient(t *testing.T) {
 	assert.NotNil(t, client.headers)
 	assert.Equal(t, logger, client.logger)
 }
+
+func TestSupabaseClient_MaxConcurrent(t *testing.T) {
+	const limit = 2
+
+	started := make(chan struct{}, limit+1)
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-unblock
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		SupabaseURL:       server.URL,
+		HTTPTimeout:       10 * time.Second,
+		HTTPMaxConcurrent: limit,
+	}
+	client := NewSupabaseClient(cfg, zap.NewNop())
+	assert.Equal(t, limit, client.MaxConcurrent())
+
+	// Saturate the semaphore
+	var wg sync.WaitGroup
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := client.Get(context.Background(), "/test", nil)
+			assert.NoError(t, err)
+		}()
+	}
+	for i := 0; i < limit; i++ {
+		<-started
+	}
+	assert.Equal(t, limit, client.InFlight())
+
+	// The next call must wait for a slot
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Post(context.Background(), "/test", map[string]string{})
+		done <- err
+	}()
+
+	select {
+	case <-started:
+		t.Fatal("request exceeded the concurrency limit")
+	case <-done:
+		t.Fatal("request completed while the semaphore was saturated")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Completing the in-flight calls lets the waiting one through
+	close(unblock)
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("blocked request never completed")
+	}
+	wg.Wait()
+	assert.Equal(t, 0, client.InFlight())
+}
+
+func TestSupabaseClient_MaxConcurrent_ContextCanceled(t *testing.T) {
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	cfg := &config.Config{
+		SupabaseURL:       server.URL,
+		HTTPTimeout:       10 * time.Second,
+		HTTPMaxConcurrent: 1,
+	}
+	client := NewSupabaseClient(cfg, zap.NewNop())
+
+	go client.Get(context.Background(), "/test", nil)
+	require.Eventually(t, func() bool { return client.InFlight() == 1 }, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, _, err := client.Get(ctx, "/test", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestSupabaseClient_Get_ContextCanceledDuringBodyRead(t *testing.T) {
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Stream the start of the body, then stall
+		w.Write([]byte(`[{"id":"audit-001"},`))
+		w.(http.Flusher).Flush()
+		<-unblock
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	cfg := &config.Config{
+		SupabaseURL: server.URL,
+		HTTPTimeout: 10 * time.Second,
+	}
+	client := NewSupabaseClient(cfg, zap.NewNop())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err := client.Get(ctx, "/test", nil)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "response read aborted")
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestSupabaseClient_LogsSupabaseErrorFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"column does not exist","code":"42703","details":"audit_logs.slide","hint":"Check the column name"}`))
+	}))
+	defer server.Close()
+
+	core, logs := observer.New(zap.ErrorLevel)
+	cfg := &config.Config{
+		SupabaseURL: server.URL,
+		HTTPTimeout: 10 * time.Second,
+	}
+	client := NewSupabaseClient(cfg, zap.New(core))
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{
+			name: "get",
+			call: func() error {
+				_, _, err := client.Get(context.Background(), "/audit_logs", nil)
+				return err
+			},
+		},
+		{
+			name: "post",
+			call: func() error {
+				_, err := client.Post(context.Background(), "/audit_logs", map[string]string{})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.TakeAll()
+
+			err := tt.call()
+			require.Error(t, err)
+
+			entries := logs.FilterMessage("supabase request failed").All()
+			require.Len(t, entries, 1)
+			fields := entries[0].ContextMap()
+			assert.Equal(t, "42703", fields["supabase_code"])
+			assert.Equal(t, "audit_logs.slide", fields["supabase_details"])
+			assert.Equal(t, "Check the column name", fields["supabase_hint"])
+			assert.Equal(t, int64(http.StatusBadRequest), fields["status"])
+		})
+	}
+}
+
+func TestSupabaseClient_UserAgent(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		SupabaseURL:   server.URL,
+		HTTPTimeout:   10 * time.Second,
+		HTTPUserAgent: "audit-service/1.2.3",
+	}
+	client := NewSupabaseClient(cfg, zap.NewNop())
+
+	_, _, err := client.Get(context.Background(), "/audit_logs", nil)
+	require.NoError(t, err)
+	_, err = client.Post(context.Background(), "/audit_logs", map[string]string{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"audit-service/1.2.3", "audit-service/1.2.3"}, userAgents)
+}
+
+func TestSupabaseClient_Get_EstimatedCount(t *testing.T) {
+	var prefer string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefer = r.Header.Get("Prefer")
+		w.Header().Set("Content-Range", "0-9/123456")
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		SupabaseURL:       server.URL,
+		SupabaseCountMode: "estimated",
+		HTTPTimeout:       10 * time.Second,
+	}
+	client := NewSupabaseClient(cfg, zap.NewNop())
+
+	_, count, err := client.Get(context.Background(), "/audit_logs", nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "count=estimated", prefer)
+	assert.Equal(t, 123456, count)
+}
+
+func TestSupabaseClient_ErrorStatusCode(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		expectedType interface{}
+	}{
+		{name: "supabase_error", status: http.StatusBadRequest, body: `{"message":"bad query"}`, expectedType: &SupabaseError{}},
+		{name: "plain_error", status: http.StatusBadGateway, body: `<html>bad gateway</html>`, expectedType: &SupabaseHTTPError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewSupabaseClient(&config.Config{SupabaseURL: server.URL, HTTPTimeout: 10 * time.Second}, zap.NewNop())
+
+			_, _, err := client.Get(context.Background(), "/audit_logs", nil)
+
+			require.Error(t, err)
+			assert.IsType(t, tt.expectedType, err)
+			var coder StatusCoder
+			require.ErrorAs(t, err, &coder)
+			assert.Equal(t, tt.status, coder.StatusCode())
+		})
+	}
+}
+
+func TestSupabaseClient_RateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"message":"rate limit exceeded"}`))
+	}))
+	defer server.Close()
+
+	client := NewSupabaseClient(&config.Config{SupabaseURL: server.URL, HTTPTimeout: 10 * time.Second}, zap.NewNop())
+
+	_, status, err := client.Get(context.Background(), "/audit_logs", nil)
+
+	assert.Equal(t, http.StatusTooManyRequests, status)
+	assert.ErrorIs(t, err, domain.ErrServiceUnavailable)
+	var retryErr *domain.RetryAfterError
+	require.ErrorAs(t, err, &retryErr)
+	assert.Equal(t, 30*time.Second, retryErr.RetryAfter)
+
+	apiErr := domain.ToAPIError(fmt.Errorf("failed to fetch audit logs: %w", err))
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.Status)
+	assert.Equal(t, 30*time.Second, apiErr.RetryAfter)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{name: "seconds", value: "120", expected: 2 * time.Minute},
+		{name: "http_date", value: "Mon, 15 Jan 2024 10:00:45 GMT", expected: 45 * time.Second},
+		{name: "past_date", value: "Mon, 15 Jan 2024 09:00:00 GMT", expected: 0},
+		{name: "negative", value: "-5", expected: 0},
+		{name: "invalid", value: "soon", expected: 0},
+		{name: "missing", value: "", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseRetryAfter(tt.value, now))
+		})
+	}
+}
+
+func TestSupabaseClient_GetRange(t *testing.T) {
+	var headers http.Header
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Range", "20-29/137")
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewSupabaseClient(&config.Config{SupabaseURL: server.URL, HTTPTimeout: 10 * time.Second}, zap.NewNop())
+
+	_, count, err := client.GetRange(context.Background(), "/audit_logs", map[string]string{"order": "timestamp.desc"}, 20, 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, 137, count)
+	assert.Equal(t, "items", headers.Get("Range-Unit"))
+	assert.Equal(t, "20-29", headers.Get("Range"))
+	assert.NotContains(t, query, "limit=")
+	assert.NotContains(t, query, "offset=")
+}
+
+func TestSupabaseClient_Ping(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		expectError bool
+	}{
+		{name: "up", status: http.StatusOK},
+		{name: "down", status: http.StatusServiceUnavailable, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path, query string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				query = r.URL.RawQuery
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`[]`))
+			}))
+			defer server.Close()
+
+			client := NewSupabaseClient(&config.Config{SupabaseURL: server.URL, HTTPTimeout: 10 * time.Second}, zap.NewNop())
+
+			err := client.Ping(context.Background())
+
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, "/rest/v1/sessions", path)
+			assert.Contains(t, query, "limit=1")
+		})
+	}
+}
+
+func TestSupabaseClient_ReadURL(t *testing.T) {
+	var primaryHits, readHits []string
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryHits = append(primaryHits, r.Method)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`[]`))
+	}))
+	defer primary.Close()
+	replica := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		readHits = append(readHits, r.Method)
+		w.Write([]byte(`[]`))
+	}))
+	defer replica.Close()
+
+	client := NewSupabaseClient(&config.Config{
+		SupabaseURL:     primary.URL,
+		SupabaseReadURL: replica.URL,
+		HTTPTimeout:     10 * time.Second,
+	}, zap.NewNop())
+	ctx := context.Background()
+
+	_, _, err := client.Get(ctx, "/audit_logs", nil)
+	require.NoError(t, err)
+	_, _, err = client.GetRange(ctx, "/audit_logs", nil, 0, 10)
+	require.NoError(t, err)
+	_, err = client.Post(ctx, "/audit_logs", map[string]string{"action": "edit"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{http.MethodGet, http.MethodGet}, readHits)
+	assert.Equal(t, []string{http.MethodPost}, primaryHits)
+}
+
+func TestSupabaseClient_ReadURL_FallsBackToPrimary(t *testing.T) {
+	var hits []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, r.Method)
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewSupabaseClient(&config.Config{SupabaseURL: server.URL, HTTPTimeout: 10 * time.Second}, zap.NewNop())
+
+	_, _, err := client.Get(context.Background(), "/audit_logs", nil)
+	require.NoError(t, err)
+	_, err = client.Post(context.Background(), "/audit_logs", map[string]string{"action": "edit"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{http.MethodGet, http.MethodPost}, hits)
+	assert.Equal(t, client.baseURL, client.readURL)
+}
+
+func TestSupabaseClient_Get_GzipResponse(t *testing.T) {
+	body := []byte(`[{"id":"audit-1","action":"edit"}]`)
+	var acceptEncoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Range", "0-0/1")
+		gz := gzip.NewWriter(w)
+		gz.Write(body)
+		gz.Close()
+	}))
+	defer server.Close()
+
+	client := NewSupabaseClient(&config.Config{SupabaseURL: server.URL, HTTPTimeout: 10 * time.Second}, zap.NewNop())
+
+	data, count, err := client.Get(context.Background(), "/audit_logs", nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "gzip", acceptEncoding)
+	assert.JSONEq(t, string(body), string(data))
+	assert.Equal(t, 1, count)
+}
+
+func TestSupabaseClient_GetRange_PastEnd(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Range", "*/42")
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		w.Write([]byte(`{"code":"PGRST103","message":"Requested range not satisfiable"}`))
+	}))
+	defer server.Close()
+
+	client := NewSupabaseClient(&config.Config{SupabaseURL: server.URL, HTTPTimeout: 10 * time.Second}, zap.NewNop())
+
+	data, count, err := client.GetRange(context.Background(), "/audit_logs", nil, 100, 10)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `[]`, string(data))
+	assert.Equal(t, 42, count)
+}
+
+func TestParseContentRangeTotal(t *testing.T) {
+	tests := []struct {
+		contentRange string
+		expected     int
+	}{
+		{contentRange: "0-9/100", expected: 100},
+		{contentRange: "*/0", expected: 0},
+		{contentRange: "*/42", expected: 42},
+		{contentRange: "0-9/*", expected: 0},
+		{contentRange: "", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentRange, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseContentRangeTotal(tt.contentRange))
+		})
+	}
+}
@@ -1,10 +1,18 @@
 package service
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"slices"
+	"time"
 
+	"audit-service/internal/config"
 	"audit-service/internal/domain"
 	"audit-service/internal/repository"
 	"audit-service/pkg/cache"
@@ -14,49 +22,150 @@ import (
 
 // AuditService defines the interface for audit business logic
 type AuditService interface {
-	GetAuditLogs(ctx context.Context, sessionID, userID string, isShareToken bool, pagination domain.PaginationParams) (*domain.AuditResponse, error)
+	GetAuditLogs(ctx context.Context, sessionID, userID string, isShareToken bool, pagination domain.PaginationParams, filter domain.HistoryFilter) (*domain.AuditResponse, error)
+	CreateAuditEntry(ctx context.Context, sessionID, userID, idempotencyKey string, req domain.CreateAuditEntryRequest) (*domain.AuditEntry, bool, error)
+	CreateAuditEntries(ctx context.Context, sessionID, userID string, reqs []domain.CreateAuditEntryRequest) ([]domain.AuditEntry, error)
+	GetSummary(ctx context.Context, sessionID, userID string, isShareToken bool, window string) (*domain.AuditSummary, error)
+	GetAuditEntry(ctx context.Context, sessionID, userID string, isShareToken bool, entryID string) (*domain.AuditEntry, error)
+	ListSessions(ctx context.Context, userID string) ([]domain.SessionSummary, error)
+	ListShares(ctx context.Context, sessionID, userID string) ([]domain.ShareGrant, error)
+	ImportAuditEntries(ctx context.Context, sessionID string, r io.Reader) (*domain.ImportSummary, error)
+	WaitForEntries(ctx context.Context, sessionID, userID string, isShareToken bool, filter domain.HistoryFilter, wait time.Duration) (bool, error)
 }
 
 // auditService implements the AuditService interface
 type auditService struct {
-	repo   repository.AuditRepository
-	cache  *cache.TokenCache
-	logger *zap.Logger
+	cfg         *config.Config
+	repo        repository.AuditRepository
+	cache       *cache.TokenCache
+	idempotency *cache.IdempotencyStore
+	actions     domain.ActionSet
+	// shareHidden are the actions share-token callers never see
+	shareHidden []string
+	// schemas validates write details per action; nil when validation is disabled
+	schemas domain.DetailsSchemas
+	// stale holds recent history pages to fall back on during outages; nil when disabled
+	stale *cache.ResponseCache
+	// responses holds recent history pages to answer repeated reads; nil when disabled
+	responses *cache.ResponseCache
+	logger    *zap.Logger
 }
 
 // NewAuditService creates a new audit service instance
-func NewAuditService(repo repository.AuditRepository, cache *cache.TokenCache, logger *zap.Logger) AuditService {
+func NewAuditService(cfg *config.Config, repo repository.AuditRepository, cache *cache.TokenCache, idempotency *cache.IdempotencyStore, logger *zap.Logger) AuditService {
 	return &auditService{
-		repo:   repo,
-		cache:  cache,
-		logger: logger,
+		cfg:         cfg,
+		repo:        repo,
+		cache:       cache,
+		idempotency: idempotency,
+		actions:     domain.NewActionSet(cfg.ExtraAuditActions),
+		shareHidden: domain.ParseActions(cfg.ShareHiddenActions),
+		schemas:     newDetailsSchemas(cfg),
+		stale:       newStaleCache(cfg),
+		responses:   newResponseCache(cfg),
+		logger:      logger,
 	}
 }
 
-// GetAuditLogs retrieves audit logs for a session with permission validation
-func (s *auditService) GetAuditLogs(ctx context.Context, sessionID, userID string, isShareToken bool, pagination domain.PaginationParams) (*domain.AuditResponse, error) {
-	// Validate pagination
-	pagination.Validate()
+// newDetailsSchemas returns the details schemas to enforce on writes, or nil if disabled
+func newDetailsSchemas(cfg *config.Config) domain.DetailsSchemas {
+	if !cfg.DetailsSchemaValidation {
+		return nil
+	}
+	return domain.NewDetailsSchemas()
+}
+
+// newStaleCache returns the cache used to serve stale history, or nil if disabled
+func newStaleCache(cfg *config.Config) *cache.ResponseCache {
+	if !cfg.ServeStale {
+		return nil
+	}
+	return cache.NewResponseCache(cfg.StaleTTL, cfg.CacheCleanupInterval, cfg.StaleCacheMaxSessions)
+}
+
+// newResponseCache returns the cache used to answer repeated history reads, or nil if disabled
+func newResponseCache(cfg *config.Config) *cache.ResponseCache {
+	if !cfg.ResponseCacheEnabled {
+		return nil
+	}
+	return cache.NewResponseCache(cfg.ResponseCacheTTL, cfg.CacheCleanupInterval, cfg.ResponseCacheSessions)
+}
 
-	// If not using share token, validate ownership
-	if !isShareToken {
-		if err := s.validateOwnership(ctx, sessionID, userID); err != nil {
+// GetAuditLogs retrieves audit logs for a session with permission validation
+func (s *auditService) GetAuditLogs(ctx context.Context, sessionID, userID string, isShareToken bool, pagination domain.PaginationParams, filter domain.HistoryFilter) (*domain.AuditResponse, error) {
+	// Apply this deployment's page size policy
+	if s.cfg.StrictPagination {
+		if err := pagination.ValidateStrict(s.cfg.DefaultPageSize, s.cfg.MaxPageSize); err != nil {
 			return nil, err
 		}
+	} else {
+		pagination.Validate(s.cfg.DefaultPageSize, s.cfg.MaxPageSize)
+	}
+
+	// Bound all Supabase calls for this request by the query timeout
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.QueryTimeout)
+	defer cancel()
+
+	// Don't start any queries if the caller has already gone away
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrTimeout, err)
+	}
+
+	if err := s.validateActionFilter(filter.Actions); err != nil {
+		return nil, err
+	}
+	// Tombstoned entries are only for the owner
+	if filter.IncludeDeleted && isShareToken {
+		return nil, domain.ErrForbidden
+	}
+	visible := true
+	if isShareToken {
+		filter, visible = s.hideShareActions(filter)
+	}
+
+	cacheKey := historyCacheKey(sessionID, userID, isShareToken, pagination, filter)
+
+	if err := s.validateReadAccess(ctx, sessionID, userID, isShareToken); err != nil {
+		if response, ok := s.serveStale(cacheKey, err); ok {
+			return response, nil
+		}
+		return nil, err
+	}
+
+	// Access is checked on every read; only the history itself comes from the cache
+	if s.responses != nil {
+		if cached, found := s.responses.Get(cacheKey); found {
+			response := cached.(domain.AuditResponse)
+			return &response, nil
+		}
+	}
+
+	// Every requested action is hidden from this caller, so there is nothing to fetch
+	if !visible {
+		return &domain.AuditResponse{
+			Items:      []domain.AuditEntry{},
+			Pagination: pagination,
+			CountMode:  s.cfg.SupabaseCountMode,
+		}, nil
 	}
-	// Share token validation is already done in the auth middleware
 
 	// Fetch audit logs
-	entries, totalCount, err := s.repo.FindBySessionID(ctx, sessionID, pagination.Limit, pagination.Offset)
+	entries, totalCount, err := s.fetchHistory(ctx, sessionID, pagination, filter)
 	if err != nil {
 		if errors.Is(err, domain.ErrSessionNotFound) {
 			return nil, domain.ErrNotFound
 		}
+		if errors.Is(err, domain.ErrEntryNotFound) {
+			return nil, err
+		}
 		s.logger.Error("failed to fetch audit logs",
 			zap.String("session_id", sessionID),
 			zap.String("user_id", userID),
 			zap.Error(err),
 		)
+		if response, ok := s.serveStale(cacheKey, err); ok {
+			return response, nil
+		}
 		return nil, fmt.Errorf("failed to fetch audit logs: %w", err)
 	}
 
@@ -64,6 +173,21 @@ func (s *auditService) GetAuditLogs(ctx context.Context, sessionID, userID strin
 	response := &domain.AuditResponse{
 		TotalCount: totalCount,
 		Items:      entries,
+		Pagination: pagination,
+		CountMode:  s.cfg.SupabaseCountMode,
+	}
+	if filter.IncludeSession && len(entries) > 0 {
+		// Every row embeds the same session, so take it from the first
+		response.Session = entries[0].Session
+	}
+	if s.cfg.PaginationHints && !filter.CountOnly {
+		response.SetPaginationHints()
+	}
+	if s.stale != nil {
+		s.stale.Set(sessionID, cacheKey, *response)
+	}
+	if s.responses != nil {
+		s.responses.Set(sessionID, cacheKey, *response)
 	}
 
 	s.logger.Info("audit logs retrieved",
@@ -77,7 +201,641 @@ func (s *auditService) GetAuditLogs(ctx context.Context, sessionID, userID strin
 	return response, nil
 }
 
-// validateOwnership checks if the user owns the session
+// WaitForEntries holds a long-poll history read until entries newer than
+// filter.SinceID exist, reporting false once wait (capped at
+// LONG_POLL_MAX_WAIT) passes without any. Access is checked once up front;
+// after that each check is a count query, every LONG_POLL_INTERVAL.
+func (s *auditService) WaitForEntries(ctx context.Context, sessionID, userID string, isShareToken bool, filter domain.HistoryFilter, wait time.Duration) (bool, error) {
+	if filter.SinceID == "" || wait <= 0 || s.cfg.LongPollMaxWait <= 0 {
+		return true, nil
+	}
+	wait = min(wait, s.cfg.LongPollMaxWait)
+
+	if err := s.validateActionFilter(filter.Actions); err != nil {
+		return false, err
+	}
+	if filter.IncludeDeleted && isShareToken {
+		return false, domain.ErrForbidden
+	}
+	// New entries the caller can't see must not end the wait
+	visible := true
+	if isShareToken {
+		filter, visible = s.hideShareActions(filter)
+	}
+
+	accessCtx, cancel := context.WithTimeout(ctx, s.cfg.QueryTimeout)
+	err := s.validateReadAccess(accessCtx, sessionID, userID, isShareToken)
+	cancel()
+	if err != nil {
+		return false, err
+	}
+
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	ticker := time.NewTicker(s.cfg.LongPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if visible {
+			found, err := s.hasNewEntries(ctx, sessionID, filter)
+			if err != nil {
+				return false, err
+			}
+			if found {
+				// Pages cached before the new entries arrived are out of date
+				s.invalidateResponses(sessionID)
+				return true, nil
+			}
+		}
+
+		select {
+		case <-deadline.C:
+			return false, nil
+		case <-ctx.Done():
+			return false, fmt.Errorf("%w: %v", domain.ErrTimeout, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// hasNewEntries counts the entries matching filter, bounded by the query timeout
+func (s *auditService) hasNewEntries(ctx context.Context, sessionID string, filter domain.HistoryFilter) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.QueryTimeout)
+	defer cancel()
+
+	count, err := s.repo.CountBySessionID(ctx, sessionID, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// fetchHistory fetches a page of audit logs, or for count-only reads just
+// the total, with no items
+func (s *auditService) fetchHistory(ctx context.Context, sessionID string, pagination domain.PaginationParams, filter domain.HistoryFilter) ([]domain.AuditEntry, int, error) {
+	if !filter.CountOnly {
+		return s.repo.FindBySessionID(ctx, sessionID, pagination.Limit, pagination.Offset, filter)
+	}
+	totalCount, err := s.repo.CountBySessionID(ctx, sessionID, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return []domain.AuditEntry{}, totalCount, nil
+}
+
+// validateActionFilter checks the action filter against the cap and the accepted actions
+func (s *auditService) validateActionFilter(actions []string) error {
+	if len(actions) > s.cfg.MaxActionFilters {
+		return &domain.ActionFilterLimitError{Max: s.cfg.MaxActionFilters}
+	}
+	for _, action := range actions {
+		if !s.actions.Contains(action) {
+			return fmt.Errorf("%w: %q", domain.ErrInvalidAction, action)
+		}
+	}
+	return nil
+}
+
+// hideShareActions removes the owner-only actions from a share-token caller's
+// filter. It reports false when the caller asked only for hidden actions.
+func (s *auditService) hideShareActions(filter domain.HistoryFilter) (domain.HistoryFilter, bool) {
+	if len(s.shareHidden) == 0 {
+		return filter, true
+	}
+	if len(filter.Actions) == 0 {
+		filter.ExcludeActions = s.shareHidden
+		return filter, true
+	}
+
+	visible := make([]string, 0, len(filter.Actions))
+	for _, action := range filter.Actions {
+		if !s.isShareHidden(action) {
+			visible = append(visible, action)
+		}
+	}
+	if len(visible) == 0 {
+		return filter, false
+	}
+	filter.Actions = visible
+	return filter, true
+}
+
+// isShareHidden reports whether share-token callers may not see the action
+func (s *auditService) isShareHidden(action string) bool {
+	for _, hidden := range s.shareHidden {
+		if hidden == action {
+			return true
+		}
+	}
+	return false
+}
+
+// historyCacheKey identifies a caller's history page. The caller is part of the key
+// so a cached page is only ever served to someone who was already allowed to read it.
+func historyCacheKey(sessionID, userID string, isShareToken bool, pagination domain.PaginationParams, filter domain.HistoryFilter) string {
+	principal := "user:" + userID
+	if isShareToken {
+		principal = "share"
+	}
+	return fmt.Sprintf("%s|%s|%d|%d|%s", principal, sessionID, pagination.Limit, pagination.Offset, filter.Key())
+}
+
+// serveStale returns the last good response for key when err indicates Supabase is unavailable
+func (s *auditService) serveStale(key string, err error) (*domain.AuditResponse, bool) {
+	if s.stale == nil || !errors.Is(err, domain.ErrServiceUnavailable) {
+		return nil, false
+	}
+	cached, found := s.stale.Get(key)
+	if !found {
+		return nil, false
+	}
+	response := cached.(domain.AuditResponse)
+	response.Stale = true
+
+	s.logger.Warn("serving stale audit logs",
+		zap.String("stale_key", key),
+		zap.Error(err),
+	)
+	return &response, true
+}
+
+// GetSummary aggregates a session's audit activity over the requested window
+// (e.g. "7d"), falling back to the configured default window when empty
+func (s *auditService) GetSummary(ctx context.Context, sessionID, userID string, isShareToken bool, window string) (*domain.AuditSummary, error) {
+	duration, err := domain.ParseWindow(window, s.cfg.SummaryDefaultWindow, s.cfg.SummaryMaxWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bound all Supabase calls for this request by the query timeout
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.QueryTimeout)
+	defer cancel()
+
+	if err := s.validateReadAccess(ctx, sessionID, userID, isShareToken); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.repo.CountActionsSince(ctx, sessionID, time.Now().Add(-duration))
+	if err != nil {
+		s.logger.Error("failed to count audit actions",
+			zap.String("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to build summary: %w", err)
+	}
+
+	// Share-token callers must not learn when hidden actions happened either
+	var hidden []string
+	if isShareToken {
+		hidden = s.shareHidden
+	}
+	oldest, latest, err := s.repo.FindActivityBounds(ctx, sessionID, hidden)
+	if err != nil {
+		s.logger.Error("failed to fetch activity bounds",
+			zap.String("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to build summary: %w", err)
+	}
+
+	total := 0
+	for action, count := range counts {
+		if isShareToken && s.isShareHidden(action) {
+			delete(counts, action)
+			continue
+		}
+		total += count
+	}
+
+	return &domain.AuditSummary{
+		SessionID:      sessionID,
+		Window:         duration.String(),
+		TotalCount:     total,
+		ActionCounts:   counts,
+		LastActivity:   latest,
+		OldestActivity: oldest,
+	}, nil
+}
+
+// GetAuditEntry returns a single entry of a session the caller may read
+func (s *auditService) GetAuditEntry(ctx context.Context, sessionID, userID string, isShareToken bool, entryID string) (*domain.AuditEntry, error) {
+	if err := s.validateReadAccess(ctx, sessionID, userID, isShareToken); err != nil {
+		return nil, err
+	}
+
+	entry, err := s.repo.GetEntry(ctx, sessionID, entryID)
+	if err != nil {
+		if errors.Is(err, domain.ErrEntryNotFound) {
+			return nil, err
+		}
+		s.logger.Error("failed to fetch audit entry",
+			zap.String("session_id", sessionID),
+			zap.String("entry_id", entryID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch audit entry: %w", err)
+	}
+	// Hidden entries don't exist as far as reviewers are concerned
+	if isShareToken && s.isShareHidden(entry.Action) {
+		return nil, domain.ErrEntryNotFound
+	}
+	return entry, nil
+}
+
+// ListSessions returns the user's sessions ordered by their latest audit activity.
+// Callers are responsible for checking the requester may see the user's sessions.
+func (s *auditService) ListSessions(ctx context.Context, userID string) ([]domain.SessionSummary, error) {
+	sessions, err := s.repo.ListSessionsByUser(ctx, userID)
+	if err != nil {
+		s.logger.Error("failed to list sessions",
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// ListShares lists the active share tokens of a session for its owner, with
+// the tokens masked so the listing can't be used to access the session
+func (s *auditService) ListShares(ctx context.Context, sessionID, userID string) ([]domain.ShareGrant, error) {
+	if err := s.validateOwnership(ctx, sessionID, userID); err != nil {
+		return nil, err
+	}
+
+	shares, err := s.repo.ListActiveShares(ctx, sessionID)
+	if err != nil {
+		s.logger.Error("failed to list share tokens",
+			zap.String("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to list share tokens: %w", err)
+	}
+
+	grants := make([]domain.ShareGrant, 0, len(shares))
+	for _, share := range shares {
+		grant := domain.ShareGrant{Token: domain.MaskToken(share.Token)}
+		if share.ExpiresAt != "" {
+			expiresAt, err := time.Parse(time.RFC3339, share.ExpiresAt)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse share token expiry: %w", err)
+			}
+			expiresAt = expiresAt.UTC()
+			grant.ExpiresAt = &expiresAt
+		}
+		grants = append(grants, grant)
+	}
+	return grants, nil
+}
+
+// CreateAuditEntry records a new audit entry for a session owned by the user.
+// When an idempotency key is supplied, a repeated request with the same payload
+// returns the originally created entry (and true) instead of inserting again.
+func (s *auditService) CreateAuditEntry(ctx context.Context, sessionID, userID, idempotencyKey string, req domain.CreateAuditEntryRequest) (*domain.AuditEntry, bool, error) {
+	newEntry, err := s.actions.NewEntry(sessionID, userID, req)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := s.checkDetailsSize(-1, req.Details); err != nil {
+		return nil, false, err
+	}
+	if err := s.validateDetails(req.Action, req.Details); err != nil {
+		return nil, false, err
+	}
+
+	if err := s.authorizeWrite(ctx, sessionID, userID, req.ActorUserID != ""); err != nil {
+		return nil, false, err
+	}
+
+	// Claim the idempotency key before writing
+	var key, fingerprint string
+	if idempotencyKey != "" {
+		key = fmt.Sprintf("%s:%s:%s", sessionID, userID, idempotencyKey)
+		fingerprint = requestFingerprint(req)
+
+		if existing, reserved := s.idempotency.Reserve(key, fingerprint); !reserved {
+			if existing.Fingerprint != fingerprint {
+				s.logger.Warn("idempotency key reused with different payload",
+					zap.String("session_id", sessionID),
+					zap.String("user_id", userID),
+				)
+				return nil, false, domain.ErrIdempotencyConflict
+			}
+			entry, ok := existing.Value.(*domain.AuditEntry)
+			if !ok {
+				// Original request is still in flight
+				return nil, false, domain.ErrIdempotencyConflict
+			}
+			return entry, true, nil
+		}
+	}
+
+	entry, err := s.repo.CreateEntry(ctx, &newEntry)
+	s.invalidateResponses(sessionID)
+	if err != nil {
+		if key != "" {
+			s.idempotency.Release(key)
+		}
+		s.logger.Error("failed to create audit entry",
+			zap.String("session_id", sessionID),
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, false, fmt.Errorf("failed to create audit entry: %w", err)
+	}
+
+	if key != "" {
+		s.idempotency.Complete(key, fingerprint, entry)
+	}
+
+	s.logger.Info("audit entry created",
+		zap.String("session_id", sessionID),
+		zap.String("user_id", userID),
+		zap.String("action", req.Action),
+	)
+
+	return entry, false, nil
+}
+
+// CreateAuditEntries records a batch of audit entries in a single insert.
+// The whole batch is rejected if any entry fails validation.
+func (s *auditService) CreateAuditEntries(ctx context.Context, sessionID, userID string, reqs []domain.CreateAuditEntryRequest) ([]domain.AuditEntry, error) {
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("%w: batch is empty", domain.ErrInvalidBatch)
+	}
+	if len(reqs) > s.cfg.MaxBatchSize {
+		return nil, fmt.Errorf("%w: batch of %d exceeds maximum of %d", domain.ErrInvalidBatch, len(reqs), s.cfg.MaxBatchSize)
+	}
+
+	// Validate every entry before writing anything
+	entries := make([]domain.AuditEntry, len(reqs))
+	var invalid []int
+	for i, req := range reqs {
+		entry, err := s.actions.NewEntry(sessionID, userID, req)
+		if errors.Is(err, domain.ErrInvalidAction) {
+			invalid = append(invalid, i)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = entry
+	}
+	if len(invalid) > 0 {
+		return nil, &domain.BatchValidationError{InvalidIndices: invalid}
+	}
+	for i, req := range reqs {
+		if err := s.checkDetailsSize(i, req.Details); err != nil {
+			return nil, err
+		}
+		if err := s.validateDetails(req.Action, req.Details); err != nil {
+			var detailsErr *domain.DetailsValidationError
+			if errors.As(err, &detailsErr) {
+				detailsErr.Index = i
+			}
+			return nil, err
+		}
+	}
+
+	onBehalf := false
+	for _, req := range reqs {
+		onBehalf = onBehalf || req.ActorUserID != ""
+	}
+	if err := s.authorizeWrite(ctx, sessionID, userID, onBehalf); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.CreateEntries(ctx, entries)
+	s.invalidateResponses(sessionID)
+	if err != nil {
+		s.logger.Error("failed to create audit entries",
+			zap.String("session_id", sessionID),
+			zap.String("user_id", userID),
+			zap.Int("count", len(entries)),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to create audit entries: %w", err)
+	}
+
+	s.logger.Info("audit entries created",
+		zap.String("session_id", sessionID),
+		zap.String("user_id", userID),
+		zap.Int("count", len(created)),
+	)
+
+	return created, nil
+}
+
+// invalidateResponses drops the session's cached history pages after a write.
+// It runs even when the write failed, since a timed-out insert may still have landed.
+func (s *auditService) invalidateResponses(sessionID string) {
+	if s.responses != nil {
+		s.responses.Invalidate(sessionID)
+	}
+}
+
+// ImportAuditEntries backfills a session's history from ndjson, one entry per
+// line, inserting valid lines MAX_BATCH_SIZE at a time. Invalid lines are
+// skipped and reported in the summary; reading stops after MAX_IMPORT_LINES
+// or once ctx is done, leaving the rest of the body unread.
+func (s *auditService) ImportAuditEntries(ctx context.Context, sessionID string, r io.Reader) (*domain.ImportSummary, error) {
+	if err := s.validateSessionExists(ctx, sessionID); err != nil {
+		return nil, err
+	}
+	defer s.invalidateResponses(sessionID)
+
+	summary := &domain.ImportSummary{Errors: []domain.ImportError{}}
+	var batch []domain.AuditEntry
+	var batchLines []int
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := ctx.Err(); err != nil {
+			// The caller is gone or out of time, so the batch is reported rather than sent
+			for _, line := range batchLines {
+				summary.AddError(line, "import stopped: "+err.Error())
+			}
+		} else if _, err := s.repo.CreateEntries(ctx, batch); err != nil {
+			s.logger.Error("failed to import audit entries",
+				zap.String("session_id", sessionID),
+				zap.Int("first_line", batchLines[0]),
+				zap.Int("count", len(batch)),
+				zap.Error(err),
+			)
+			for _, line := range batchLines {
+				summary.AddError(line, "insert failed")
+			}
+		} else {
+			summary.Inserted += len(batch)
+		}
+		batch, batchLines = nil, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), int(s.cfg.MaxBodyBytes))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		if err := ctx.Err(); err != nil {
+			summary.AddError(lineNo, "import stopped: "+err.Error())
+			summary.Truncated = true
+			break
+		}
+		if lineNo > s.cfg.MaxImportLines {
+			summary.AddError(lineNo, fmt.Sprintf("imports are limited to %d lines", s.cfg.MaxImportLines))
+			summary.Truncated = true
+			break
+		}
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		entry, err := s.parseImportLine(sessionID, raw)
+		if err != nil {
+			summary.AddError(lineNo, err.Error())
+			continue
+		}
+		batch = append(batch, entry)
+		batchLines = append(batchLines, lineNo)
+		if len(batch) == s.cfg.MaxBatchSize {
+			flush()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		// An overlong line or a broken body ends the import; what was read is still inserted
+		summary.AddError(lineNo+1, "failed to read line: "+err.Error())
+		summary.Truncated = true
+	}
+	flush()
+
+	s.logger.Info("audit entries imported",
+		zap.String("session_id", sessionID),
+		zap.Int("inserted", summary.Inserted),
+		zap.Int("failed", summary.Failed),
+		zap.Bool("truncated", summary.Truncated),
+	)
+
+	return summary, nil
+}
+
+// parseImportLine decodes and validates one import line into an entry for sessionID
+func (s *auditService) parseImportLine(sessionID string, raw []byte) (domain.AuditEntry, error) {
+	var line domain.ImportLine
+	if err := json.Unmarshal(raw, &line); err != nil {
+		return domain.AuditEntry{}, errors.New("invalid JSON")
+	}
+	if line.UserID == "" {
+		return domain.AuditEntry{}, errors.New("userId is required")
+	}
+	if line.Timestamp.IsZero() {
+		return domain.AuditEntry{}, errors.New("timestamp is required")
+	}
+	entry, err := s.actions.NewEntry(sessionID, line.UserID, domain.CreateAuditEntryRequest{
+		Action:    line.Action,
+		Details:   line.Details,
+		IPAddress: line.IPAddress,
+		UserAgent: line.UserAgent,
+	})
+	if errors.Is(err, domain.ErrInvalidAction) {
+		return domain.AuditEntry{}, fmt.Errorf("invalid action %q", line.Action)
+	}
+	if err != nil {
+		return domain.AuditEntry{}, err
+	}
+	err = s.checkDetailsSize(-1, line.Details)
+	if err == nil {
+		err = s.validateDetails(line.Action, line.Details)
+	}
+	if err != nil {
+		var detailsErr *domain.DetailsValidationError
+		if errors.As(err, &detailsErr) {
+			return domain.AuditEntry{}, fmt.Errorf("%s %s", detailsErr.Path(), detailsErr.Reason)
+		}
+		return domain.AuditEntry{}, err
+	}
+	entry.Timestamp = line.Timestamp
+	return entry, nil
+}
+
+// validateDetails checks write details against the action's schema and, for
+// edit and merge, the rules of their typed constructors. Nothing is checked
+// when schema validation is disabled.
+func (s *auditService) validateDetails(action string, details json.RawMessage) error {
+	if s.schemas == nil {
+		return nil
+	}
+	if err := s.schemas.Validate(action, details); err != nil {
+		return err
+	}
+	return domain.ValidateBuiltinDetails(action, details)
+}
+
+// checkDetailsSize rejects details larger than MaxDetailsBytes. index is the
+// entry's position in a batch, or -1 for a single entry.
+func (s *auditService) checkDetailsSize(index int, details json.RawMessage) error {
+	if int64(len(details)) <= s.cfg.MaxDetailsBytes {
+		return nil
+	}
+	return &domain.DetailsValidationError{
+		Index:  index,
+		Reason: fmt.Sprintf("must be at most %d bytes", s.cfg.MaxDetailsBytes),
+	}
+}
+
+// requestFingerprint hashes the parts of a write request that must match on replay
+func requestFingerprint(req domain.CreateAuditEntryRequest) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Action))
+	hash.Write([]byte{0})
+	hash.Write(req.Details)
+	hash.Write([]byte{0})
+	hash.Write([]byte(req.ActorUserID))
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// validateReadAccess checks that the caller may read the session's audit logs.
+// JWT callers must own the session; share tokens are already validated by the
+// auth middleware, but the session itself may no longer exist.
+func (s *auditService) validateReadAccess(ctx context.Context, sessionID, userID string, isShareToken bool) error {
+	if isShareToken {
+		return s.validateSessionExists(ctx, sessionID)
+	}
+	return s.validateOwnership(ctx, sessionID, userID)
+}
+
+// validateSessionExists checks that the session exists without checking ownership
+func (s *auditService) validateSessionExists(ctx context.Context, sessionID string) error {
+	if _, err := s.repo.GetSession(ctx, sessionID); err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	return nil
+}
+
+// authorizeWrite checks that the user may write to the session. Recording
+// entries on behalf of another actor is reserved for ADMIN_USER_IDS, who need
+// not own the session; everyone else must.
+func (s *auditService) authorizeWrite(ctx context.Context, sessionID, userID string, onBehalf bool) error {
+	if !onBehalf {
+		return s.validateOwnership(ctx, sessionID, userID)
+	}
+	if !slices.Contains(s.cfg.AdminUserIDs, userID) {
+		s.logger.Warn("actor override by non-admin",
+			zap.String("session_id", sessionID),
+			zap.String("user_id", userID),
+		)
+		return domain.ErrForbidden
+	}
+	return s.validateSessionExists(ctx, sessionID)
+}
+
+// validateOwnership checks if the user owns the session. With
+// HideForbiddenSessions set, a non-owner gets ErrNotFound just like a missing
+// session, so callers can't probe which session IDs exist.
 func (s *auditService) validateOwnership(ctx context.Context, sessionID, userID string) error {
 	// Get session info
 	session, err := s.repo.GetSession(ctx, sessionID)
@@ -95,6 +853,9 @@ func (s *auditService) validateOwnership(ctx context.Context, sessionID, userID
 			zap.String("user_id", userID),
 			zap.String("owner_id", session.UserID),
 		)
+		if s.cfg.HideForbiddenSessions {
+			return domain.ErrNotFound
+		}
 		return domain.ErrForbidden
 	}
 
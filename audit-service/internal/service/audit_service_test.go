@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
+	"audit-service/internal/config"
 	"audit-service/internal/domain"
 	"audit-service/internal/repository"
 	"audit-service/mocks"
@@ -15,6 +18,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -27,6 +31,23 @@ const (
 )
 
 // Helper functions to create test data
+func testConfig() *config.Config {
+	return &config.Config{
+		QueryTimeout:         5 * time.Second,
+		MaxPageSize:          100,
+		DefaultPageSize:      50,
+		MaxBatchSize:         100,
+		MaxBatchSessions:     10,
+		MaxImportLines:       1000,
+		MaxActionFilters:     10,
+		MaxBodyBytes:         1 << 20,
+		MaxDetailsBytes:      64 << 10,
+		MaxResponseBytes:     10 << 20,
+		SummaryDefaultWindow: 168 * time.Hour,
+		SummaryMaxWindow:     720 * time.Hour,
+	}
+}
+
 func createSampleAuditEntries() []domain.AuditEntry {
 	now := time.Now()
 	details1, _ := json.Marshal(map[string]interface{}{"slide": 1, "text": "updated"})
@@ -123,7 +144,7 @@ func TestAuditService_GetAuditLogs(t *testing.T) {
 
 				// Mock audit logs retrieval
 				entries := createSampleAuditEntries()
-				mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 0).
+				mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 0, domain.HistoryFilter{}).
 					Return(entries, 4, nil)
 			},
 			expectedResult: createSampleAuditResponse(),
@@ -136,9 +157,12 @@ func TestAuditService_GetAuditLogs(t *testing.T) {
 			isShareToken: true,
 			pagination:   createSamplePaginationParams(),
 			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
-				// Share token - no ownership validation needed
+				// Share token - only session existence is checked
+				mockRepo.On("GetSession", mock.Anything, testSessionID).
+					Return(createSampleSession(), nil)
+
 				entries := createSampleAuditEntries()
-				mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 0).
+				mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 0, domain.HistoryFilter{}).
 					Return(entries, 4, nil)
 			},
 			expectedResult: createSampleAuditResponse(),
@@ -157,7 +181,7 @@ func TestAuditService_GetAuditLogs(t *testing.T) {
 
 				// Mock paginated audit logs retrieval
 				entries := generateAuditEntries(30, testSessionID, testUserID)
-				mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 50, 20).
+				mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 50, 20, domain.HistoryFilter{}).
 					Return(entries[20:], 100, nil)
 			},
 			expectedResult: &domain.AuditResponse{
@@ -204,12 +228,28 @@ func TestAuditService_GetAuditLogs(t *testing.T) {
 			isShareToken: true,
 			pagination:   createSamplePaginationParams(),
 			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
-				mockRepo.On("FindBySessionID", mock.Anything, "non-existent-session", 10, 0).
+				mockRepo.On("GetSession", mock.Anything, "non-existent-session").
+					Return(createSampleSession(), nil)
+				mockRepo.On("FindBySessionID", mock.Anything, "non-existent-session", 10, 0, domain.HistoryFilter{}).
 					Return(nil, 0, domain.ErrSessionNotFound)
 			},
 			expectedResult: nil,
 			expectedError:  domain.ErrNotFound,
 		},
+		{
+			name:         "error_share_token_missing_session",
+			sessionID:    "non-existent-session",
+			userID:       "",
+			isShareToken: true,
+			pagination:   createSamplePaginationParams(),
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				// FindBySessionID must not be reached for a missing session
+				mockRepo.On("GetSession", mock.Anything, "non-existent-session").
+					Return(nil, domain.ErrSessionNotFound)
+			},
+			expectedResult: nil,
+			expectedError:  domain.ErrNotFound,
+		},
 		{
 			name:         "error_repository_failure",
 			sessionID:    testSessionID,
@@ -220,7 +260,7 @@ func TestAuditService_GetAuditLogs(t *testing.T) {
 				mockRepo.On("GetSession", mock.Anything, testSessionID).
 					Return(createSampleSession(), nil)
 
-				mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 0).
+				mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 0, domain.HistoryFilter{}).
 					Return(nil, 0, errors.New("database connection failed"))
 			},
 			expectedResult: nil,
@@ -246,7 +286,9 @@ func TestAuditService_GetAuditLogs(t *testing.T) {
 			isShareToken: true,
 			pagination:   createSamplePaginationParams(),
 			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
-				mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 0).
+				mockRepo.On("GetSession", mock.Anything, testSessionID).
+					Return(createSampleSession(), nil)
+				mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 0, domain.HistoryFilter{}).
 					Return([]domain.AuditEntry{}, 0, nil)
 			},
 			expectedResult: &domain.AuditResponse{
@@ -268,7 +310,7 @@ func TestAuditService_GetAuditLogs(t *testing.T) {
 			)
 			logger := zap.NewNop()
 
-			service := NewAuditService(mockRepo, tokenCache, logger)
+			service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), logger)
 
 			// Configure mocks
 			tt.setupMocks(mockRepo)
@@ -280,6 +322,7 @@ func TestAuditService_GetAuditLogs(t *testing.T) {
 				tt.userID,
 				tt.isShareToken,
 				tt.pagination,
+				domain.HistoryFilter{},
 			)
 
 			// Assert
@@ -376,6 +419,7 @@ func TestAuditService_validateOwnership(t *testing.T) {
 			logger := zap.NewNop()
 
 			service := &auditService{
+				cfg:    testConfig(),
 				repo:   mockRepo,
 				cache:  tokenCache,
 				logger: logger,
@@ -416,8 +460,1482 @@ func TestNewAuditService(t *testing.T) {
 	)
 	logger := zap.NewNop()
 
-	service := NewAuditService(mockRepo, tokenCache, logger)
+	service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), logger)
 
 	assert.NotNil(t, service)
 	assert.Implements(t, (*AuditService)(nil), service)
 }
+
+func TestAuditService_CreateAuditEntry(t *testing.T) {
+	editRequest := domain.CreateAuditEntryRequest{
+		Action:  "edit",
+		Details: json.RawMessage(`{"slide":1}`),
+	}
+	createdEntry := &domain.AuditEntry{
+		ID:        "audit-100",
+		SessionID: testSessionID,
+		UserID:    testUserID,
+		Action:    "edit",
+	}
+
+	tests := []struct {
+		name           string
+		userID         string
+		request        domain.CreateAuditEntryRequest
+		setupMocks     func(*mocks.MockAuditRepository)
+		expectedError  error
+		expectedResult *domain.AuditEntry
+	}{
+		{
+			name:    "success_create_entry",
+			userID:  testUserID,
+			request: editRequest,
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("GetSession", mock.Anything, testSessionID).
+					Return(createSampleSession(), nil)
+				mockRepo.On("CreateEntry", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+					return entry.SessionID == testSessionID && entry.UserID == testUserID && entry.Action == "edit"
+				})).Return(createdEntry, nil)
+			},
+			expectedResult: createdEntry,
+		},
+		{
+			name:    "error_invalid_action",
+			userID:  testUserID,
+			request: domain.CreateAuditEntryRequest{Action: "delete"},
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				// Validation fails before any repository call
+			},
+			expectedError: domain.ErrInvalidAction,
+		},
+		{
+			name:    "error_not_owner",
+			userID:  testOtherUserID,
+			request: editRequest,
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("GetSession", mock.Anything, testSessionID).
+					Return(createSampleSession(), nil)
+			},
+			expectedError: domain.ErrForbidden,
+		},
+		{
+			name:    "error_repository_failure",
+			userID:  testUserID,
+			request: editRequest,
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("GetSession", mock.Anything, testSessionID).
+					Return(createSampleSession(), nil)
+				mockRepo.On("CreateEntry", mock.Anything, mock.Anything).
+					Return(nil, errors.New("database error"))
+			},
+			expectedError: errors.New("failed to create audit entry: database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+			tt.setupMocks(mockRepo)
+
+			result, replayed, err := service.CreateAuditEntry(context.Background(), testSessionID, tt.userID, "", tt.request)
+
+			assert.False(t, replayed)
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				if errors.Is(tt.expectedError, domain.ErrInvalidAction) || tt.expectedError == domain.ErrForbidden {
+					assert.ErrorIs(t, err, tt.expectedError)
+				} else {
+					assert.Contains(t, err.Error(), tt.expectedError.Error())
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResult, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditService_CreateAuditEntry_Idempotency(t *testing.T) {
+	editRequest := domain.CreateAuditEntryRequest{
+		Action:  "edit",
+		Details: json.RawMessage(`{"slide":1}`),
+	}
+	createdEntry := &domain.AuditEntry{
+		ID:        "audit-100",
+		SessionID: testSessionID,
+		UserID:    testUserID,
+		Action:    "edit",
+	}
+
+	t.Run("replay_returns_original_entry", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+		service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+		mockRepo.On("CreateEntry", mock.Anything, mock.Anything).Return(createdEntry, nil).Once()
+
+		first, replayed, err := service.CreateAuditEntry(context.Background(), testSessionID, testUserID, "key-1", editRequest)
+		assert.NoError(t, err)
+		assert.False(t, replayed)
+
+		second, replayed, err := service.CreateAuditEntry(context.Background(), testSessionID, testUserID, "key-1", editRequest)
+		assert.NoError(t, err)
+		assert.True(t, replayed)
+		assert.Equal(t, first, second)
+
+		// Only one insert reached the repository
+		mockRepo.AssertNumberOfCalls(t, "CreateEntry", 1)
+	})
+
+	t.Run("different_payload_conflicts", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+		service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+		mockRepo.On("CreateEntry", mock.Anything, mock.Anything).Return(createdEntry, nil).Once()
+
+		_, _, err := service.CreateAuditEntry(context.Background(), testSessionID, testUserID, "key-1", editRequest)
+		assert.NoError(t, err)
+
+		mergeRequest := domain.CreateAuditEntryRequest{
+			Action:  "merge",
+			Details: json.RawMessage(`{"slides":[1,2]}`),
+		}
+		result, replayed, err := service.CreateAuditEntry(context.Background(), testSessionID, testUserID, "key-1", mergeRequest)
+		assert.ErrorIs(t, err, domain.ErrIdempotencyConflict)
+		assert.False(t, replayed)
+		assert.Nil(t, result)
+
+		mockRepo.AssertNumberOfCalls(t, "CreateEntry", 1)
+	})
+
+	t.Run("failed_write_can_be_retried", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+		service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+		mockRepo.On("CreateEntry", mock.Anything, mock.Anything).Return(nil, errors.New("database error")).Once()
+		mockRepo.On("CreateEntry", mock.Anything, mock.Anything).Return(createdEntry, nil).Once()
+
+		_, _, err := service.CreateAuditEntry(context.Background(), testSessionID, testUserID, "key-1", editRequest)
+		assert.Error(t, err)
+
+		result, replayed, err := service.CreateAuditEntry(context.Background(), testSessionID, testUserID, "key-1", editRequest)
+		assert.NoError(t, err)
+		assert.False(t, replayed)
+		assert.Equal(t, createdEntry, result)
+	})
+}
+
+func TestAuditService_CreateAuditEntries(t *testing.T) {
+	createdEntries := []domain.AuditEntry{
+		{ID: "audit-101", SessionID: testSessionID, UserID: testUserID, Action: "edit"},
+		{ID: "audit-102", SessionID: testSessionID, UserID: testUserID, Action: "merge"},
+	}
+
+	tests := []struct {
+		name            string
+		requests        []domain.CreateAuditEntryRequest
+		setupMocks      func(*mocks.MockAuditRepository)
+		expectedIndices []int
+		expectedError   error
+	}{
+		{
+			name: "success_valid_batch",
+			requests: []domain.CreateAuditEntryRequest{
+				{Action: "edit", Details: json.RawMessage(`{"slide":1}`)},
+				{Action: "merge", Details: json.RawMessage(`{"slides":[1,2]}`)},
+			},
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("GetSession", mock.Anything, testSessionID).
+					Return(createSampleSession(), nil)
+				mockRepo.On("CreateEntries", mock.Anything, mock.MatchedBy(func(entries []domain.AuditEntry) bool {
+					return len(entries) == 2 && entries[0].Action == "edit" && entries[1].Action == "merge" &&
+						entries[0].SessionID == testSessionID && entries[1].UserID == testUserID
+				})).Return(createdEntries, nil)
+			},
+		},
+		{
+			name: "error_mixed_validity_batch",
+			requests: []domain.CreateAuditEntryRequest{
+				{Action: "edit"},
+				{Action: "delete"},
+				{Action: "merge"},
+				{Action: ""},
+			},
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				// Nothing is written when any entry is invalid
+			},
+			expectedIndices: []int{1, 3},
+			expectedError:   domain.ErrInvalidAction,
+		},
+		{
+			name:     "error_empty_batch",
+			requests: []domain.CreateAuditEntryRequest{},
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+			},
+			expectedError: domain.ErrInvalidBatch,
+		},
+		{
+			name:     "error_batch_too_large",
+			requests: make([]domain.CreateAuditEntryRequest, 101),
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+			},
+			expectedError: domain.ErrInvalidBatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+			tt.setupMocks(mockRepo)
+
+			result, err := service.CreateAuditEntries(context.Background(), testSessionID, testUserID, tt.requests)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+
+				var batchErr *domain.BatchValidationError
+				if tt.expectedIndices != nil {
+					assert.True(t, errors.As(err, &batchErr))
+					assert.Equal(t, tt.expectedIndices, batchErr.InvalidIndices)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, createdEntries, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditService_CreateAuditEntry_CustomAction(t *testing.T) {
+	cfg := testConfig()
+	cfg.ExtraAuditActions = []string{"translate"}
+
+	mockRepo := mocks.NewMockAuditRepository(t)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+	created := &domain.AuditEntry{ID: "audit-200", SessionID: testSessionID, UserID: testUserID, Action: "translate"}
+	mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+	mockRepo.On("CreateEntry", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == "translate"
+	})).Return(created, nil)
+
+	entry, _, err := service.CreateAuditEntry(context.Background(), testSessionID, testUserID, "", domain.CreateAuditEntryRequest{Action: "translate"})
+	assert.NoError(t, err)
+	assert.Equal(t, created, entry)
+
+	// Unregistered actions are still rejected
+	_, _, err = service.CreateAuditEntry(context.Background(), testSessionID, testUserID, "", domain.CreateAuditEntryRequest{Action: "summarize"})
+	assert.ErrorIs(t, err, domain.ErrInvalidAction)
+}
+
+func TestAuditService_CreateAuditEntry_ActorOverride(t *testing.T) {
+	const (
+		adminID = "admin-789"
+		actorID = "550e8400-e29b-41d4-a716-446655440002"
+	)
+	req := domain.CreateAuditEntryRequest{Action: "edit", ActorUserID: actorID}
+
+	t.Run("admin_records_for_actor", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.AdminUserIDs = []string{adminID}
+		mockRepo := mocks.NewMockAuditRepository(t)
+		tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+		service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+		// The admin doesn't own the session; it only has to exist
+		created := &domain.AuditEntry{ID: "audit-300", SessionID: testSessionID, UserID: actorID, Action: "edit", PerformedBy: adminID}
+		mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+		mockRepo.On("CreateEntry", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+			return entry.UserID == actorID && entry.PerformedBy == adminID
+		})).Return(created, nil)
+
+		entry, _, err := service.CreateAuditEntry(context.Background(), testSessionID, adminID, "", req)
+
+		require.NoError(t, err)
+		assert.Equal(t, created, entry)
+	})
+
+	t.Run("non_admin_rejected", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.AdminUserIDs = []string{adminID}
+		mockRepo := mocks.NewMockAuditRepository(t)
+		tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+		service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+		// Even the session owner may not attribute entries to someone else
+		_, _, err := service.CreateAuditEntry(context.Background(), testSessionID, testUserID, "", domain.CreateAuditEntryRequest{Action: "edit", ActorUserID: actorID})
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+
+		_, err = service.CreateAuditEntries(context.Background(), testSessionID, testUserID, []domain.CreateAuditEntryRequest{
+			{Action: "edit"},
+			{Action: "merge", ActorUserID: actorID},
+		})
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("invalid_actor_rejected", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.AdminUserIDs = []string{adminID}
+		mockRepo := mocks.NewMockAuditRepository(t)
+		tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+		service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+		// Rejected before the session is looked up or anything is written
+		_, _, err := service.CreateAuditEntry(context.Background(), testSessionID, adminID, "", domain.CreateAuditEntryRequest{Action: "edit", ActorUserID: "not-a-uuid"})
+		assert.ErrorIs(t, err, domain.ErrInvalidActorID)
+
+		_, err = service.CreateAuditEntries(context.Background(), testSessionID, adminID, []domain.CreateAuditEntryRequest{
+			{Action: "edit"},
+			{Action: "merge", ActorUserID: "not-a-uuid"},
+		})
+		assert.ErrorIs(t, err, domain.ErrInvalidActorID)
+		assert.Equal(t, http.StatusBadRequest, domain.ToAPIError(err).Status)
+	})
+}
+
+func TestAuditService_CreateAuditEntry_DetailsSchema(t *testing.T) {
+	cfg := testConfig()
+	cfg.DetailsSchemaValidation = true
+
+	mockRepo := mocks.NewMockAuditRepository(t)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+	created := &domain.AuditEntry{ID: "audit-300", SessionID: testSessionID, UserID: testUserID, Action: "merge"}
+	mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil).Once()
+	mockRepo.On("CreateEntry", mock.Anything, mock.Anything).Return(created, nil).Once()
+
+	entry, _, err := service.CreateAuditEntry(context.Background(), testSessionID, testUserID, "", domain.CreateAuditEntryRequest{
+		Action:  "merge",
+		Details: json.RawMessage(`{"slides":[1,2]}`),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, created, entry)
+
+	// Invalid details are rejected before touching the repository
+	_, _, err = service.CreateAuditEntry(context.Background(), testSessionID, testUserID, "", domain.CreateAuditEntryRequest{
+		Action:  "merge",
+		Details: json.RawMessage(`{"slides":3}`),
+	})
+	assert.ErrorIs(t, err, domain.ErrInvalidDetails)
+	var detailsErr *domain.DetailsValidationError
+	if assert.ErrorAs(t, err, &detailsErr) {
+		assert.Equal(t, "slides", detailsErr.Field)
+	}
+
+	// Well-typed details still have to meet NewEditEntry's rules
+	_, _, err = service.CreateAuditEntry(context.Background(), testSessionID, testUserID, "", domain.CreateAuditEntryRequest{
+		Action:  "edit",
+		Details: json.RawMessage(`{"slide":0}`),
+	})
+	if assert.ErrorAs(t, err, &detailsErr) {
+		assert.Equal(t, "slide", detailsErr.Field)
+	}
+}
+
+func TestAuditService_CreateAuditEntries_DetailsSchema(t *testing.T) {
+	cfg := testConfig()
+	cfg.DetailsSchemaValidation = true
+
+	mockRepo := mocks.NewMockAuditRepository(t)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+	_, err := service.CreateAuditEntries(context.Background(), testSessionID, testUserID, []domain.CreateAuditEntryRequest{
+		{Action: "edit", Details: json.RawMessage(`{"slide":1}`)},
+		{Action: "merge", Details: json.RawMessage(`{"slide":2}`)},
+	})
+
+	var detailsErr *domain.DetailsValidationError
+	if assert.ErrorAs(t, err, &detailsErr) {
+		assert.Equal(t, 1, detailsErr.Index)
+		assert.Equal(t, "slides", detailsErr.Field)
+	}
+}
+
+func TestAuditService_CreateAuditEntry_UpstreamUnavailable(t *testing.T) {
+	// As the repository returns a Supabase 503 on insert
+	upstreamErr := fmt.Errorf("failed to create audit log: %w: %w", domain.ErrServiceUnavailable, &repository.SupabaseError{Message: "upstream down", Status: http.StatusServiceUnavailable})
+
+	mockRepo := mocks.NewMockAuditRepository(t)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+	mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+	mockRepo.On("CreateEntry", mock.Anything, mock.Anything).Return(nil, upstreamErr)
+	mockRepo.On("CreateEntries", mock.Anything, mock.Anything).Return(nil, upstreamErr)
+
+	_, _, err := service.CreateAuditEntry(context.Background(), testSessionID, testUserID, "", domain.CreateAuditEntryRequest{Action: "edit"})
+	assert.ErrorIs(t, err, domain.ErrServiceUnavailable)
+	assert.Equal(t, http.StatusServiceUnavailable, domain.ToAPIError(err).Status)
+
+	_, err = service.CreateAuditEntries(context.Background(), testSessionID, testUserID, []domain.CreateAuditEntryRequest{{Action: "edit"}})
+	assert.ErrorIs(t, err, domain.ErrServiceUnavailable)
+	assert.Equal(t, http.StatusServiceUnavailable, domain.ToAPIError(err).Status)
+}
+
+func TestAuditService_CreateAuditEntry_DetailsTooLarge(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxDetailsBytes = 64
+
+	mockRepo := mocks.NewMockAuditRepository(t)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+	oversized := json.RawMessage(`{"note":"` + strings.Repeat("x", 64) + `"}`)
+
+	// Rejected before touching the repository
+	_, _, err := service.CreateAuditEntry(context.Background(), testSessionID, testUserID, "", domain.CreateAuditEntryRequest{
+		Action:  "edit",
+		Details: oversized,
+	})
+	assert.ErrorIs(t, err, domain.ErrInvalidDetails)
+	var detailsErr *domain.DetailsValidationError
+	if assert.ErrorAs(t, err, &detailsErr) {
+		assert.Equal(t, -1, detailsErr.Index)
+		assert.Equal(t, "details", detailsErr.Path())
+		assert.Equal(t, "must be at most 64 bytes", detailsErr.Reason)
+	}
+
+	_, err = service.CreateAuditEntries(context.Background(), testSessionID, testUserID, []domain.CreateAuditEntryRequest{
+		{Action: "edit", Details: json.RawMessage(`{"slide":1}`)},
+		{Action: "edit", Details: oversized},
+	})
+	if assert.ErrorAs(t, err, &detailsErr) {
+		assert.Equal(t, 1, detailsErr.Index)
+	}
+}
+
+func TestAuditService_ImportAuditEntries(t *testing.T) {
+	const (
+		edit1 = `{"userId":"user-1","action":"edit","timestamp":"2023-11-01T09:00:00Z","details":{"slide":1}}`
+		edit2 = `{"userId":"user-2","action":"edit","timestamp":"2023-11-01T09:05:00+01:00"}`
+		merge = `{"userId":"user-1","action":"merge","timestamp":"2023-11-02T10:00:00Z"}`
+	)
+
+	tests := []struct {
+		name             string
+		body             string
+		maxBatchSize     int
+		maxImportLines   int
+		expectedBatches  []int
+		expectedInserted int
+		expectedErrors   []domain.ImportError
+		expectTruncated  bool
+	}{
+		{
+			name:             "clean_import_in_batches",
+			body:             edit1 + "\n" + edit2 + "\n\n" + merge + "\n",
+			maxBatchSize:     2,
+			expectedBatches:  []int{2, 1},
+			expectedInserted: 3,
+			expectedErrors:   []domain.ImportError{},
+		},
+		{
+			name: "invalid_lines_skipped",
+			body: edit1 + "\n" +
+				`{"userId":"user-1","action":"hack","timestamp":"2023-11-01T09:00:00Z"}` + "\n" +
+				`{"action":"edit","timestamp":"2023-11-01T09:00:00Z"}` + "\n" +
+				`{"userId":"user-1","action":"edit"}` + "\n" +
+				`not json` + "\n" +
+				merge,
+			maxBatchSize:     100,
+			expectedBatches:  []int{2},
+			expectedInserted: 2,
+			expectedErrors: []domain.ImportError{
+				{Line: 2, Error: `invalid action "hack"`},
+				{Line: 3, Error: "userId is required"},
+				{Line: 4, Error: "timestamp is required"},
+				{Line: 5, Error: "invalid JSON"},
+			},
+		},
+		{
+			name:             "stops_at_line_limit",
+			body:             edit1 + "\n" + edit2 + "\n" + merge + "\n",
+			maxBatchSize:     100,
+			maxImportLines:   2,
+			expectedBatches:  []int{2},
+			expectedInserted: 2,
+			expectedErrors:   []domain.ImportError{{Line: 3, Error: "imports are limited to 2 lines"}},
+			expectTruncated:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.MaxBatchSize = tt.maxBatchSize
+			if tt.maxImportLines > 0 {
+				cfg.MaxImportLines = tt.maxImportLines
+			}
+
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+			mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+			var batches []int
+			mockRepo.On("CreateEntries", mock.Anything, mock.MatchedBy(func(entries []domain.AuditEntry) bool {
+				for _, entry := range entries {
+					if entry.SessionID != testSessionID || entry.UserID == "" || entry.Timestamp.IsZero() {
+						return false
+					}
+				}
+				return true
+			})).Run(func(args mock.Arguments) {
+				batches = append(batches, len(args.Get(1).([]domain.AuditEntry)))
+			}).Return(func(_ context.Context, entries []domain.AuditEntry) []domain.AuditEntry { return entries }, nil)
+
+			summary, err := service.ImportAuditEntries(context.Background(), testSessionID, strings.NewReader(tt.body))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedBatches, batches)
+			assert.Equal(t, tt.expectedInserted, summary.Inserted)
+			assert.Equal(t, len(tt.expectedErrors), summary.Failed)
+			assert.Equal(t, tt.expectedErrors, summary.Errors)
+			assert.Equal(t, tt.expectTruncated, summary.Truncated)
+		})
+	}
+}
+
+func TestAuditService_ImportAuditEntries_StopsWhenCancelled(t *testing.T) {
+	body := `{"userId":"user-1","action":"edit","timestamp":"2023-11-01T09:00:00Z"}` + "\n" +
+		`{"userId":"user-2","action":"edit","timestamp":"2023-11-01T09:05:00Z"}` + "\n" +
+		`{"userId":"user-1","action":"merge","timestamp":"2023-11-02T10:00:00Z"}` + "\n"
+
+	cfg := testConfig()
+	cfg.MaxBatchSize = 1
+
+	mockRepo := mocks.NewMockAuditRepository(t)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+	// The client goes away while the first batch is being inserted
+	mockRepo.On("CreateEntries", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		cancel()
+	}).Return(func(_ context.Context, entries []domain.AuditEntry) []domain.AuditEntry { return entries }, nil).Once()
+
+	summary, err := service.ImportAuditEntries(ctx, testSessionID, strings.NewReader(body))
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Inserted)
+	assert.Equal(t, []domain.ImportError{{Line: 2, Error: "import stopped: context canceled"}}, summary.Errors)
+	assert.True(t, summary.Truncated)
+}
+
+func TestAuditService_ImportAuditEntries_SessionNotFound(t *testing.T) {
+	mockRepo := mocks.NewMockAuditRepository(t)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+	mockRepo.On("GetSession", mock.Anything, testSessionID).Return(nil, domain.ErrSessionNotFound)
+
+	summary, err := service.ImportAuditEntries(context.Background(), testSessionID, strings.NewReader(`{}`))
+
+	assert.Nil(t, summary)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestAuditService_GetSummary(t *testing.T) {
+	oldest := time.Date(2023, 11, 1, 9, 0, 0, 0, time.UTC)
+	latest := time.Date(2023, 12, 1, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		isShareToken  bool
+		window        string
+		setupMocks    func(*mocks.MockAuditRepository)
+		expectedError error
+	}{
+		{
+			name:   "success_default_window",
+			window: "",
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+				mockRepo.On("CountActionsSince", mock.Anything, testSessionID, mock.MatchedBy(func(since time.Time) bool {
+					return time.Since(since) > 167*time.Hour && time.Since(since) < 169*time.Hour
+				})).Return(map[string]int{"edit": 2, "merge": 1}, nil)
+				mockRepo.On("FindActivityBounds", mock.Anything, testSessionID, []string(nil)).Return(&oldest, &latest, nil)
+			},
+		},
+		{
+			name:         "success_share_token",
+			isShareToken: true,
+			window:       "7d",
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+				mockRepo.On("CountActionsSince", mock.Anything, testSessionID, mock.Anything).
+					Return(map[string]int{"edit": 2, "merge": 1}, nil)
+				mockRepo.On("FindActivityBounds", mock.Anything, testSessionID, []string(nil)).Return(&oldest, &latest, nil)
+			},
+		},
+		{
+			name:          "error_window_over_max",
+			window:        "90d",
+			setupMocks:    func(mockRepo *mocks.MockAuditRepository) {},
+			expectedError: domain.ErrInvalidWindow,
+		},
+		{
+			name:   "error_repository_failure",
+			window: "7d",
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+				mockRepo.On("CountActionsSince", mock.Anything, testSessionID, mock.Anything).
+					Return(nil, domain.ErrServiceUnavailable)
+			},
+			expectedError: domain.ErrServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+			tt.setupMocks(mockRepo)
+
+			summary, err := service.GetSummary(context.Background(), testSessionID, testUserID, tt.isShareToken, tt.window)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, summary)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 3, summary.TotalCount)
+				assert.Equal(t, map[string]int{"edit": 2, "merge": 1}, summary.ActionCounts)
+				assert.Equal(t, &latest, summary.LastActivity)
+				assert.Equal(t, &oldest, summary.OldestActivity)
+				assert.True(t, summary.OldestActivity.Before(*summary.LastActivity))
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditService_GetSummary_UpstreamStatus(t *testing.T) {
+	cfg := testConfig()
+	cfg.QueryTimeout = time.Second
+
+	hasDeadline := mock.MatchedBy(func(ctx context.Context) bool {
+		deadline, ok := ctx.Deadline()
+		return ok && time.Until(deadline) <= cfg.QueryTimeout
+	})
+
+	t.Run("supabase_5xx", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+		service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+		mockRepo.On("GetSession", hasDeadline, testSessionID).Return(createSampleSession(), nil)
+		mockRepo.On("CountActionsSince", hasDeadline, testSessionID, mock.Anything).
+			Return(nil, fmt.Errorf("failed to count audit actions: %w: %w", domain.ErrServiceUnavailable, &repository.SupabaseHTTPError{Status: http.StatusBadGateway}))
+
+		summary, err := service.GetSummary(context.Background(), testSessionID, testUserID, false, "7d")
+
+		assert.Nil(t, summary)
+		assert.Equal(t, http.StatusServiceUnavailable, domain.ToAPIError(err).Status)
+	})
+
+	t.Run("query_timeout", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+		service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+		mockRepo.On("GetSession", hasDeadline, testSessionID).Return(createSampleSession(), nil)
+		mockRepo.On("CountActionsSince", hasDeadline, testSessionID, mock.Anything).Return(map[string]int{"edit": 1}, nil)
+		mockRepo.On("FindActivityBounds", hasDeadline, testSessionID, []string(nil)).
+			Return(nil, nil, fmt.Errorf("failed to fetch activity timestamp: %w", context.DeadlineExceeded))
+
+		summary, err := service.GetSummary(context.Background(), testSessionID, testUserID, false, "7d")
+
+		assert.Nil(t, summary)
+		assert.Equal(t, http.StatusGatewayTimeout, domain.ToAPIError(err).Status)
+	})
+}
+
+func TestAuditService_GetAuditEntry(t *testing.T) {
+	const entryID = "audit-001"
+	entry := createSampleAuditEntries()[0]
+
+	tests := []struct {
+		name          string
+		userID        string
+		setupMocks    func(*mocks.MockAuditRepository)
+		expectedError error
+	}{
+		{
+			name:   "success",
+			userID: testUserID,
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+				mockRepo.On("GetEntry", mock.Anything, testSessionID, entryID).Return(&entry, nil)
+			},
+		},
+		{
+			name:   "error_not_owner",
+			userID: "other-user",
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+			},
+			expectedError: domain.ErrForbidden,
+		},
+		{
+			name:   "error_entry_not_found",
+			userID: testUserID,
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+				mockRepo.On("GetEntry", mock.Anything, testSessionID, entryID).Return(nil, domain.ErrEntryNotFound)
+			},
+			expectedError: domain.ErrEntryNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+			tt.setupMocks(mockRepo)
+
+			result, err := service.GetAuditEntry(context.Background(), testSessionID, tt.userID, false, entryID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, &entry, result)
+		})
+	}
+}
+
+func TestAuditService_GetAuditLogs_CanceledContext(t *testing.T) {
+	mockRepo := mocks.NewMockAuditRepository(t)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// No repository expectations: Supabase must not be called
+	result, err := service.GetAuditLogs(ctx, testSessionID, testUserID, false, createSamplePaginationParams(), domain.HistoryFilter{})
+
+	assert.ErrorIs(t, err, domain.ErrTimeout)
+	assert.Nil(t, result)
+}
+
+func TestAuditService_GetAuditLogs_AppliesQueryTimeout(t *testing.T) {
+	mockRepo := mocks.NewMockAuditRepository(t)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+	hasDeadline := mock.MatchedBy(func(ctx context.Context) bool {
+		deadline, ok := ctx.Deadline()
+		return ok && time.Until(deadline) <= 5*time.Second
+	})
+	mockRepo.On("GetSession", hasDeadline, testSessionID).Return(createSampleSession(), nil)
+	mockRepo.On("FindBySessionID", hasDeadline, testSessionID, 10, 0, domain.HistoryFilter{}).Return(createSampleAuditEntries(), 4, nil)
+
+	_, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, false, createSamplePaginationParams(), domain.HistoryFilter{})
+
+	assert.NoError(t, err)
+}
+
+func TestAuditService_GetAuditLogs_PaginationHints(t *testing.T) {
+	tests := []struct {
+		name            string
+		paginationHints bool
+		expectedJSON    string
+	}{
+		{name: "enabled", paginationHints: true, expectedJSON: `{"totalCount":4,"items":[],"hasMore":false,"offsetBeyondTotal":true}`},
+		{name: "disabled_by_default", paginationHints: false, expectedJSON: `{"totalCount":4,"items":[]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.PaginationHints = tt.paginationHints
+
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+			mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+			mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 40, domain.HistoryFilter{}).
+				Return([]domain.AuditEntry{}, 4, nil)
+
+			result, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, false,
+				domain.PaginationParams{Limit: 10, Offset: 40}, domain.HistoryFilter{})
+
+			require.NoError(t, err)
+			encoded, err := json.Marshal(result)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expectedJSON, string(encoded))
+		})
+	}
+}
+
+func TestAuditService_GetAuditLogs_StrictPagination(t *testing.T) {
+	tests := []struct {
+		name          string
+		strict        bool
+		limit         int
+		expectedLimit int
+		expectedErr   error
+	}{
+		{name: "strict_rejects_limit_above_max", strict: true, limit: 101, expectedErr: domain.ErrInvalidPagination},
+		{name: "strict_accepts_max", strict: true, limit: 100, expectedLimit: 100},
+		{name: "lenient_clamps_by_default", strict: false, limit: 101, expectedLimit: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.StrictPagination = tt.strict
+
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+			if tt.expectedErr == nil {
+				mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+				mockRepo.On("FindBySessionID", mock.Anything, testSessionID, tt.expectedLimit, 0, domain.HistoryFilter{}).
+					Return([]domain.AuditEntry{}, 0, nil)
+			}
+
+			_, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, false,
+				domain.PaginationParams{Limit: tt.limit}, domain.HistoryFilter{})
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestAuditService_GetAuditLogs_ServeStale(t *testing.T) {
+	outage := fmt.Errorf("failed to fetch audit logs: %w", domain.ErrServiceUnavailable)
+
+	tests := []struct {
+		name       string
+		serveStale bool
+	}{
+		{name: "enabled", serveStale: true},
+		{name: "disabled", serveStale: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.ServeStale = tt.serveStale
+			cfg.StaleTTL = time.Minute
+			cfg.StaleCacheMaxSessions = 10
+
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+			mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+			mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 0, domain.HistoryFilter{}).
+				Return(createSampleAuditEntries(), 4, nil).Once()
+			mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 0, domain.HistoryFilter{}).
+				Return(nil, 0, outage).Once()
+
+			// Fresh responses are served as-is
+			fresh, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, false, createSamplePaginationParams(), domain.HistoryFilter{})
+			require.NoError(t, err)
+			assert.False(t, fresh.Stale)
+
+			// During an outage the last good page is served, if enabled
+			result, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, false, createSamplePaginationParams(), domain.HistoryFilter{})
+			if tt.serveStale {
+				require.NoError(t, err)
+				assert.True(t, result.Stale)
+				assert.Equal(t, fresh.Items, result.Items)
+				assert.Equal(t, fresh.TotalCount, result.TotalCount)
+				return
+			}
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, domain.ErrServiceUnavailable)
+			assert.Equal(t, http.StatusServiceUnavailable, domain.ToAPIError(err).Status)
+		})
+	}
+}
+
+func TestAuditService_GetAuditLogs_ServeStaleIsPerCaller(t *testing.T) {
+	cfg := testConfig()
+	cfg.ServeStale = true
+	cfg.StaleTTL = time.Minute
+	cfg.StaleCacheMaxSessions = 10
+
+	mockRepo := mocks.NewMockAuditRepository(t)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+	mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil).Once()
+	mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 0, domain.HistoryFilter{}).
+		Return(createSampleAuditEntries(), 4, nil).Once()
+	mockRepo.On("GetSession", mock.Anything, testSessionID).
+		Return(nil, fmt.Errorf("failed to fetch session: %w", domain.ErrServiceUnavailable))
+
+	_, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, false, createSamplePaginationParams(), domain.HistoryFilter{})
+	require.NoError(t, err)
+
+	// Another caller never gets the owner's cached page
+	result, err := service.GetAuditLogs(context.Background(), testSessionID, testOtherUserID, false, createSamplePaginationParams(), domain.HistoryFilter{})
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domain.ErrServiceUnavailable)
+}
+
+func TestAuditService_GetAuditLogs_ResponseCache(t *testing.T) {
+	cfg := testConfig()
+	cfg.ResponseCacheEnabled = true
+	cfg.ResponseCacheTTL = time.Minute
+	cfg.ResponseCacheSessions = 10
+
+	mockRepo := mocks.NewMockAuditRepository(t)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+	entries := createSampleAuditEntries()
+	mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+	mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 0, domain.HistoryFilter{}).
+		Return(entries[1:], 3, nil).Once()
+	mockRepo.On("CreateEntry", mock.Anything, mock.Anything).Return(&entries[0], nil).Once()
+	mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 0, domain.HistoryFilter{}).
+		Return(entries, 4, nil).Once()
+
+	// The second identical read is answered from the cache
+	first, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, false, createSamplePaginationParams(), domain.HistoryFilter{})
+	require.NoError(t, err)
+	cached, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, false, createSamplePaginationParams(), domain.HistoryFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, first, cached)
+	mockRepo.AssertNumberOfCalls(t, "FindBySessionID", 1)
+
+	// Recording an entry clears the session's pages, so the next read sees it
+	_, _, err = service.CreateAuditEntry(context.Background(), testSessionID, testUserID, "", domain.CreateAuditEntryRequest{Action: "edit"})
+	require.NoError(t, err)
+
+	result, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, false, createSamplePaginationParams(), domain.HistoryFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.TotalCount)
+	mockRepo.AssertNumberOfCalls(t, "FindBySessionID", 2)
+}
+
+func TestAuditService_GetAuditLogs_ReturnsResolvedPagination(t *testing.T) {
+	mockRepo := mocks.NewMockAuditRepository(t)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+	mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+	mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 100, 0, domain.HistoryFilter{}).Return(createSampleAuditEntries(), 4, nil)
+
+	result, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, false,
+		domain.PaginationParams{Limit: 200, Offset: -5}, domain.HistoryFilter{})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaginationParams{Limit: 100, Offset: 0}, result.Pagination)
+}
+
+func TestAuditService_GetAuditLogs_ConfiguredPageSizes(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxPageSize = 200
+	cfg.DefaultPageSize = 75
+
+	tests := []struct {
+		name          string
+		requested     int
+		expectedLimit int
+	}{
+		{name: "default_applied", requested: 0, expectedLimit: 75},
+		{name: "above_old_max_allowed", requested: 150, expectedLimit: 150},
+		{name: "capped_at_configured_max", requested: 500, expectedLimit: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+			mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+			mockRepo.On("FindBySessionID", mock.Anything, testSessionID, tt.expectedLimit, 0, domain.HistoryFilter{}).Return(createSampleAuditEntries(), 4, nil)
+
+			result, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, false,
+				domain.PaginationParams{Limit: tt.requested}, domain.HistoryFilter{})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedLimit, result.Pagination.Limit)
+		})
+	}
+}
+
+func TestAuditService_GetAuditLogs_IncludeSession(t *testing.T) {
+	metadata := &domain.SessionMetadata{Title: "Q3 deck", OwnerID: testUserID}
+
+	tests := []struct {
+		name            string
+		includeSession  bool
+		expectedSession *domain.SessionMetadata
+	}{
+		{name: "requested", includeSession: true, expectedSession: metadata},
+		{name: "not_requested", includeSession: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+			filter := domain.HistoryFilter{IncludeSession: tt.includeSession}
+			entries := createSampleAuditEntries()
+			if tt.includeSession {
+				for i := range entries {
+					entries[i].Session = metadata
+				}
+			}
+			mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+			mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 0, filter).Return(entries, len(entries), nil)
+
+			result, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, false, createSamplePaginationParams(), filter)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSession, result.Session)
+		})
+	}
+}
+
+func TestAuditService_GetAuditLogs_CountOnly(t *testing.T) {
+	mockRepo := mocks.NewMockAuditRepository(t)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+	filter := domain.HistoryFilter{Actions: []string{"edit"}, CountOnly: true}
+	mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+	mockRepo.On("CountBySessionID", mock.Anything, testSessionID, filter).Return(42, nil)
+
+	result, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, false, createSamplePaginationParams(), filter)
+
+	require.NoError(t, err)
+	assert.Equal(t, 42, result.TotalCount)
+	assert.Empty(t, result.Items)
+	mockRepo.AssertNotCalled(t, "FindBySessionID", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuditService_GetAuditLogs_IncludeDeleted(t *testing.T) {
+	filter := domain.HistoryFilter{IncludeDeleted: true}
+
+	t.Run("owner", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+		service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+		deletedAt := time.Now().UTC()
+		entries := createSampleAuditEntries()
+		entries[0].DeletedAt = &deletedAt
+		mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+		mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 0, filter).Return(entries, len(entries), nil)
+
+		result, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, false, createSamplePaginationParams(), filter)
+
+		require.NoError(t, err)
+		assert.Equal(t, &deletedAt, result.Items[0].DeletedAt)
+	})
+
+	t.Run("share_token_forbidden", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+		service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+		result, err := service.GetAuditLogs(context.Background(), testSessionID, "", true, createSamplePaginationParams(), filter)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+}
+
+func TestAuditService_GetAuditLogs_HideForbiddenSessions(t *testing.T) {
+	tests := []struct {
+		name          string
+		hide          bool
+		sessionErr    error
+		expectedError error
+	}{
+		{name: "default_non_owner_forbidden", hide: false, expectedError: domain.ErrForbidden},
+		{name: "default_missing_not_found", hide: false, sessionErr: domain.ErrSessionNotFound, expectedError: domain.ErrNotFound},
+		{name: "hidden_non_owner_not_found", hide: true, expectedError: domain.ErrNotFound},
+		{name: "hidden_missing_not_found", hide: true, sessionErr: domain.ErrSessionNotFound, expectedError: domain.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			cfg := testConfig()
+			cfg.HideForbiddenSessions = tt.hide
+			service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+			if tt.sessionErr != nil {
+				mockRepo.On("GetSession", mock.Anything, testSessionID).Return(nil, tt.sessionErr)
+			} else {
+				mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+			}
+
+			result, err := service.GetAuditLogs(context.Background(), testSessionID, "other-user", false, createSamplePaginationParams(), domain.HistoryFilter{})
+
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestAuditService_WaitForEntries(t *testing.T) {
+	const sinceID = "550e8400-e29b-41d4-a716-446655440009"
+	filter := domain.HistoryFilter{SinceID: sinceID}
+
+	newService := func(t *testing.T) (AuditService, *mocks.MockAuditRepository) {
+		cfg := testConfig()
+		cfg.LongPollMaxWait = time.Second
+		cfg.LongPollInterval = 10 * time.Millisecond
+		mockRepo := mocks.NewMockAuditRepository(t)
+		tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+		return NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop()), mockRepo
+	}
+
+	t.Run("returns_once_entries_appear", func(t *testing.T) {
+		service, mockRepo := newService(t)
+		mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+		mockRepo.On("CountBySessionID", mock.Anything, testSessionID, filter).Return(0, nil).Twice()
+		mockRepo.On("CountBySessionID", mock.Anything, testSessionID, filter).Return(2, nil).Once()
+
+		found, err := service.WaitForEntries(context.Background(), testSessionID, testUserID, false, filter, 30*time.Second)
+
+		require.NoError(t, err)
+		assert.True(t, found)
+	})
+
+	t.Run("times_out_without_entries", func(t *testing.T) {
+		service, mockRepo := newService(t)
+		mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+		mockRepo.On("CountBySessionID", mock.Anything, testSessionID, filter).Return(0, nil)
+
+		start := time.Now()
+		found, err := service.WaitForEntries(context.Background(), testSessionID, testUserID, false, filter, 50*time.Millisecond)
+
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("capped_at_max_wait", func(t *testing.T) {
+		service, mockRepo := newService(t)
+		service.(*auditService).cfg.LongPollMaxWait = 100 * time.Millisecond
+		mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+		mockRepo.On("CountBySessionID", mock.Anything, testSessionID, filter).Return(0, nil)
+
+		start := time.Now()
+		found, err := service.WaitForEntries(context.Background(), testSessionID, testUserID, false, filter, time.Hour)
+
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("access_checked_before_waiting", func(t *testing.T) {
+		service, mockRepo := newService(t)
+		mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+
+		_, err := service.WaitForEntries(context.Background(), testSessionID, "other-user", false, filter, 30*time.Second)
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "CountBySessionID", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("disabled_returns_immediately", func(t *testing.T) {
+		service, _ := newService(t)
+		service.(*auditService).cfg.LongPollMaxWait = 0
+
+		found, err := service.WaitForEntries(context.Background(), testSessionID, testUserID, false, filter, 30*time.Second)
+
+		require.NoError(t, err)
+		assert.True(t, found)
+	})
+}
+
+func TestAuditService_ListShares(t *testing.T) {
+	t.Run("owner_sees_masked_tokens", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+		service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+		mockRepo.On("ListActiveShares", mock.Anything, testSessionID).Return([]repository.ShareToken{
+			{Token: "share-token-abcdef123456", SessionID: testSessionID, ExpiresAt: "2030-01-01T01:00:00+01:00"},
+			{Token: "share-token-zyxw9876", SessionID: testSessionID},
+		}, nil)
+
+		grants, err := service.ListShares(context.Background(), testSessionID, testUserID)
+
+		require.NoError(t, err)
+		require.Len(t, grants, 2)
+		assert.Equal(t, "****3456", grants[0].Token)
+		assert.Equal(t, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), *grants[0].ExpiresAt)
+		assert.Equal(t, "****9876", grants[1].Token)
+		assert.Nil(t, grants[1].ExpiresAt)
+		for _, grant := range grants {
+			assert.NotContains(t, grant.Token, "share-token")
+		}
+	})
+
+	t.Run("non_owner_forbidden", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+		service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+
+		grants, err := service.ListShares(context.Background(), testSessionID, "someone-else")
+
+		assert.Nil(t, grants)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+}
+
+func TestAuditService_GetAuditLogs_UpstreamStatus(t *testing.T) {
+	// Errors as the repository returns them, already mapped at the Supabase boundary
+	tests := []struct {
+		name           string
+		repoErr        error
+		expectedStatus int
+	}{
+		{
+			name:           "supabase_5xx",
+			repoErr:        fmt.Errorf("%w: %w", domain.ErrServiceUnavailable, &repository.SupabaseError{Message: "upstream down", Status: http.StatusServiceUnavailable}),
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "query_timeout",
+			repoErr:        context.DeadlineExceeded,
+			expectedStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name:           "supabase_4xx",
+			repoErr:        &repository.SupabaseError{Message: "column does not exist", Status: http.StatusBadRequest},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			service := NewAuditService(testConfig(), mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+			mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+			mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 0, domain.HistoryFilter{}).
+				Return(nil, 0, fmt.Errorf("failed to fetch audit logs: %w", tt.repoErr))
+
+			result, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, false, createSamplePaginationParams(), domain.HistoryFilter{})
+
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, tt.repoErr)
+			assert.Equal(t, tt.expectedStatus, domain.ToAPIError(err).Status)
+		})
+	}
+}
+
+func TestAuditService_GetAuditLogs_ActionFilter(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxActionFilters = 3
+	cfg.ExtraAuditActions = []string{"translate"}
+
+	tests := []struct {
+		name        string
+		actions     []string
+		expectedErr error
+	}{
+		{name: "at_cap", actions: []string{"edit", "view", "translate"}},
+		{name: "over_cap", actions: []string{"edit", "view", "merge", "share"}, expectedErr: domain.ErrTooManyActions},
+		{name: "unknown_action", actions: []string{"edit", "delete"}, expectedErr: domain.ErrInvalidAction},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+			filter := domain.HistoryFilter{Actions: tt.actions}
+			if tt.expectedErr == nil {
+				mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+				mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 0, filter).Return(createSampleAuditEntries(), 4, nil)
+			}
+
+			result, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, false, createSamplePaginationParams(), filter)
+
+			if tt.expectedErr != nil {
+				assert.Nil(t, result)
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Equal(t, http.StatusBadRequest, domain.ToAPIError(err).Status)
+				// The cap is reported back so callers know how many values they may send
+				var limitErr *domain.ActionFilterLimitError
+				if errors.As(err, &limitErr) {
+					assert.Equal(t, cfg.MaxActionFilters, limitErr.Max)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, result)
+		})
+	}
+}
+
+func TestAuditService_GetAuditLogs_ShareHiddenActions(t *testing.T) {
+	cfg := testConfig()
+	cfg.ShareHiddenActions = []string{"export", "share"}
+
+	tests := []struct {
+		name           string
+		isShareToken   bool
+		actions        []string
+		expectedFilter *domain.HistoryFilter
+	}{
+		{name: "owner_sees_everything", isShareToken: false, expectedFilter: &domain.HistoryFilter{}},
+		{name: "owner_may_filter_hidden_actions", isShareToken: false, actions: []string{"export"}, expectedFilter: &domain.HistoryFilter{Actions: []string{"export"}}},
+		{name: "reviewer_excludes_hidden_actions", isShareToken: true, expectedFilter: &domain.HistoryFilter{ExcludeActions: []string{"export", "share"}}},
+		{name: "reviewer_filter_drops_hidden_actions", isShareToken: true, actions: []string{"edit", "export"}, expectedFilter: &domain.HistoryFilter{Actions: []string{"edit"}}},
+		{name: "reviewer_filter_only_hidden_actions", isShareToken: true, actions: []string{"export"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+			mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+			if tt.expectedFilter != nil {
+				mockRepo.On("FindBySessionID", mock.Anything, testSessionID, 10, 0, *tt.expectedFilter).
+					Return(createSampleAuditEntries(), 2, nil)
+			}
+
+			userID := testUserID
+			if tt.isShareToken {
+				userID = ""
+			}
+			result, err := service.GetAuditLogs(context.Background(), testSessionID, userID, tt.isShareToken,
+				createSamplePaginationParams(), domain.HistoryFilter{Actions: tt.actions})
+
+			require.NoError(t, err)
+			if tt.expectedFilter == nil {
+				assert.Empty(t, result.Items)
+				assert.Equal(t, 0, result.TotalCount)
+				return
+			}
+			assert.Len(t, result.Items, 2)
+		})
+	}
+}
+
+func TestAuditService_GetAuditEntry_ShareHiddenActions(t *testing.T) {
+	cfg := testConfig()
+	cfg.ShareHiddenActions = []string{"export"}
+
+	tests := []struct {
+		name         string
+		isShareToken bool
+		expectedErr  error
+	}{
+		{name: "owner", isShareToken: false},
+		{name: "reviewer", isShareToken: true, expectedErr: domain.ErrEntryNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+			entry := &domain.AuditEntry{ID: "audit-003", SessionID: testSessionID, Action: "export"}
+			mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+			mockRepo.On("GetEntry", mock.Anything, testSessionID, "audit-003").Return(entry, nil)
+
+			result, err := service.GetAuditEntry(context.Background(), testSessionID, testUserID, tt.isShareToken, "audit-003")
+
+			if tt.expectedErr != nil {
+				assert.Nil(t, result)
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, entry, result)
+		})
+	}
+}
+
+func TestAuditService_GetSummary_ShareHiddenActions(t *testing.T) {
+	cfg := testConfig()
+	cfg.ShareHiddenActions = []string{"export"}
+
+	tests := []struct {
+		name            string
+		isShareToken    bool
+		expectedCounts  map[string]int
+		expectedTotal   int
+		expectedExclude []string
+	}{
+		{name: "owner", isShareToken: false, expectedCounts: map[string]int{"edit": 2, "export": 1}, expectedTotal: 3},
+		{name: "reviewer", isShareToken: true, expectedCounts: map[string]int{"edit": 2}, expectedTotal: 2, expectedExclude: []string{"export"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockAuditRepository(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+			service := NewAuditService(cfg, mockRepo, tokenCache, cache.NewIdempotencyStore(time.Hour, 10*time.Minute), zap.NewNop())
+
+			mockRepo.On("GetSession", mock.Anything, testSessionID).Return(createSampleSession(), nil)
+			mockRepo.On("CountActionsSince", mock.Anything, testSessionID, mock.Anything).
+				Return(map[string]int{"edit": 2, "export": 1}, nil)
+			// Hidden actions don't count towards the activity bounds either
+			mockRepo.On("FindActivityBounds", mock.Anything, testSessionID, tt.expectedExclude).Return(nil, nil, nil)
+
+			summary, err := service.GetSummary(context.Background(), testSessionID, testUserID, tt.isShareToken, "")
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCounts, summary.ActionCounts)
+			assert.Equal(t, tt.expectedTotal, summary.TotalCount)
+		})
+	}
+}
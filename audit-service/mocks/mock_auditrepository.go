@@ -9,6 +9,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	repository "audit-service/internal/repository"
+
+	time "time"
 )
 
 // MockAuditRepository is an autogenerated mock type for the AuditRepository type
@@ -24,9 +26,359 @@ func (_m *MockAuditRepository) EXPECT() *MockAuditRepository_Expecter {
 	return &MockAuditRepository_Expecter{mock: &_m.Mock}
 }
 
-// FindBySessionID provides a mock function with given fields: ctx, sessionID, limit, offset
-func (_m *MockAuditRepository) FindBySessionID(ctx context.Context, sessionID string, limit int, offset int) ([]domain.AuditEntry, int, error) {
-	ret := _m.Called(ctx, sessionID, limit, offset)
+// CoalescedHistoryReads provides a mock function with no fields
+func (_m *MockAuditRepository) CoalescedHistoryReads() int64 {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for CoalescedHistoryReads")
+	}
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func() int64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	return r0
+}
+
+// MockAuditRepository_CoalescedHistoryReads_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CoalescedHistoryReads'
+type MockAuditRepository_CoalescedHistoryReads_Call struct {
+	*mock.Call
+}
+
+// CoalescedHistoryReads is a helper method to define mock.On call
+func (_e *MockAuditRepository_Expecter) CoalescedHistoryReads() *MockAuditRepository_CoalescedHistoryReads_Call {
+	return &MockAuditRepository_CoalescedHistoryReads_Call{Call: _e.mock.On("CoalescedHistoryReads")}
+}
+
+func (_c *MockAuditRepository_CoalescedHistoryReads_Call) Run(run func()) *MockAuditRepository_CoalescedHistoryReads_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_CoalescedHistoryReads_Call) Return(_a0 int64) *MockAuditRepository_CoalescedHistoryReads_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAuditRepository_CoalescedHistoryReads_Call) RunAndReturn(run func() int64) *MockAuditRepository_CoalescedHistoryReads_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CountActionsSince provides a mock function with given fields: ctx, sessionID, since
+func (_m *MockAuditRepository) CountActionsSince(ctx context.Context, sessionID string, since time.Time) (map[string]int, error) {
+	ret := _m.Called(ctx, sessionID, since)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountActionsSince")
+	}
+
+	var r0 map[string]int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (map[string]int, error)); ok {
+		return rf(ctx, sessionID, since)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) map[string]int); ok {
+		r0 = rf(ctx, sessionID, since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, sessionID, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_CountActionsSince_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountActionsSince'
+type MockAuditRepository_CountActionsSince_Call struct {
+	*mock.Call
+}
+
+// CountActionsSince is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID string
+//   - since time.Time
+func (_e *MockAuditRepository_Expecter) CountActionsSince(ctx interface{}, sessionID interface{}, since interface{}) *MockAuditRepository_CountActionsSince_Call {
+	return &MockAuditRepository_CountActionsSince_Call{Call: _e.mock.On("CountActionsSince", ctx, sessionID, since)}
+}
+
+func (_c *MockAuditRepository_CountActionsSince_Call) Run(run func(ctx context.Context, sessionID string, since time.Time)) *MockAuditRepository_CountActionsSince_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_CountActionsSince_Call) Return(_a0 map[string]int, _a1 error) *MockAuditRepository_CountActionsSince_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditRepository_CountActionsSince_Call) RunAndReturn(run func(context.Context, string, time.Time) (map[string]int, error)) *MockAuditRepository_CountActionsSince_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CountBySessionID provides a mock function with given fields: ctx, sessionID, filter
+func (_m *MockAuditRepository) CountBySessionID(ctx context.Context, sessionID string, filter domain.HistoryFilter) (int, error) {
+	ret := _m.Called(ctx, sessionID, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountBySessionID")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.HistoryFilter) (int, error)); ok {
+		return rf(ctx, sessionID, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.HistoryFilter) int); ok {
+		r0 = rf(ctx, sessionID, filter)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.HistoryFilter) error); ok {
+		r1 = rf(ctx, sessionID, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_CountBySessionID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountBySessionID'
+type MockAuditRepository_CountBySessionID_Call struct {
+	*mock.Call
+}
+
+// CountBySessionID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID string
+//   - filter domain.HistoryFilter
+func (_e *MockAuditRepository_Expecter) CountBySessionID(ctx interface{}, sessionID interface{}, filter interface{}) *MockAuditRepository_CountBySessionID_Call {
+	return &MockAuditRepository_CountBySessionID_Call{Call: _e.mock.On("CountBySessionID", ctx, sessionID, filter)}
+}
+
+func (_c *MockAuditRepository_CountBySessionID_Call) Run(run func(ctx context.Context, sessionID string, filter domain.HistoryFilter)) *MockAuditRepository_CountBySessionID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(domain.HistoryFilter))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_CountBySessionID_Call) Return(_a0 int, _a1 error) *MockAuditRepository_CountBySessionID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditRepository_CountBySessionID_Call) RunAndReturn(run func(context.Context, string, domain.HistoryFilter) (int, error)) *MockAuditRepository_CountBySessionID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateEntries provides a mock function with given fields: ctx, entries
+func (_m *MockAuditRepository) CreateEntries(ctx context.Context, entries []domain.AuditEntry) ([]domain.AuditEntry, error) {
+	ret := _m.Called(ctx, entries)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateEntries")
+	}
+
+	var r0 []domain.AuditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.AuditEntry) ([]domain.AuditEntry, error)); ok {
+		return rf(ctx, entries)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.AuditEntry) []domain.AuditEntry); ok {
+		r0 = rf(ctx, entries)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []domain.AuditEntry) error); ok {
+		r1 = rf(ctx, entries)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_CreateEntries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateEntries'
+type MockAuditRepository_CreateEntries_Call struct {
+	*mock.Call
+}
+
+// CreateEntries is a helper method to define mock.On call
+//   - ctx context.Context
+//   - entries []domain.AuditEntry
+func (_e *MockAuditRepository_Expecter) CreateEntries(ctx interface{}, entries interface{}) *MockAuditRepository_CreateEntries_Call {
+	return &MockAuditRepository_CreateEntries_Call{Call: _e.mock.On("CreateEntries", ctx, entries)}
+}
+
+func (_c *MockAuditRepository_CreateEntries_Call) Run(run func(ctx context.Context, entries []domain.AuditEntry)) *MockAuditRepository_CreateEntries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]domain.AuditEntry))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_CreateEntries_Call) Return(_a0 []domain.AuditEntry, _a1 error) *MockAuditRepository_CreateEntries_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditRepository_CreateEntries_Call) RunAndReturn(run func(context.Context, []domain.AuditEntry) ([]domain.AuditEntry, error)) *MockAuditRepository_CreateEntries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateEntry provides a mock function with given fields: ctx, entry
+func (_m *MockAuditRepository) CreateEntry(ctx context.Context, entry *domain.AuditEntry) (*domain.AuditEntry, error) {
+	ret := _m.Called(ctx, entry)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateEntry")
+	}
+
+	var r0 *domain.AuditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditEntry) (*domain.AuditEntry, error)); ok {
+		return rf(ctx, entry)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditEntry) *domain.AuditEntry); ok {
+		r0 = rf(ctx, entry)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.AuditEntry) error); ok {
+		r1 = rf(ctx, entry)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_CreateEntry_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateEntry'
+type MockAuditRepository_CreateEntry_Call struct {
+	*mock.Call
+}
+
+// CreateEntry is a helper method to define mock.On call
+//   - ctx context.Context
+//   - entry *domain.AuditEntry
+func (_e *MockAuditRepository_Expecter) CreateEntry(ctx interface{}, entry interface{}) *MockAuditRepository_CreateEntry_Call {
+	return &MockAuditRepository_CreateEntry_Call{Call: _e.mock.On("CreateEntry", ctx, entry)}
+}
+
+func (_c *MockAuditRepository_CreateEntry_Call) Run(run func(ctx context.Context, entry *domain.AuditEntry)) *MockAuditRepository_CreateEntry_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*domain.AuditEntry))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_CreateEntry_Call) Return(_a0 *domain.AuditEntry, _a1 error) *MockAuditRepository_CreateEntry_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditRepository_CreateEntry_Call) RunAndReturn(run func(context.Context, *domain.AuditEntry) (*domain.AuditEntry, error)) *MockAuditRepository_CreateEntry_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindActivityBounds provides a mock function with given fields: ctx, sessionID, excludeActions
+func (_m *MockAuditRepository) FindActivityBounds(ctx context.Context, sessionID string, excludeActions []string) (*time.Time, *time.Time, error) {
+	ret := _m.Called(ctx, sessionID, excludeActions)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindActivityBounds")
+	}
+
+	var r0 *time.Time
+	var r1 *time.Time
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) (*time.Time, *time.Time, error)); ok {
+		return rf(ctx, sessionID, excludeActions)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) *time.Time); ok {
+		r0 = rf(ctx, sessionID, excludeActions)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*time.Time)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) *time.Time); ok {
+		r1 = rf(ctx, sessionID, excludeActions)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*time.Time)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, []string) error); ok {
+		r2 = rf(ctx, sessionID, excludeActions)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockAuditRepository_FindActivityBounds_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindActivityBounds'
+type MockAuditRepository_FindActivityBounds_Call struct {
+	*mock.Call
+}
+
+// FindActivityBounds is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID string
+//   - excludeActions []string
+func (_e *MockAuditRepository_Expecter) FindActivityBounds(ctx interface{}, sessionID interface{}, excludeActions interface{}) *MockAuditRepository_FindActivityBounds_Call {
+	return &MockAuditRepository_FindActivityBounds_Call{Call: _e.mock.On("FindActivityBounds", ctx, sessionID, excludeActions)}
+}
+
+func (_c *MockAuditRepository_FindActivityBounds_Call) Run(run func(ctx context.Context, sessionID string, excludeActions []string)) *MockAuditRepository_FindActivityBounds_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]string))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_FindActivityBounds_Call) Return(oldest *time.Time, latest *time.Time, err error) *MockAuditRepository_FindActivityBounds_Call {
+	_c.Call.Return(oldest, latest, err)
+	return _c
+}
+
+func (_c *MockAuditRepository_FindActivityBounds_Call) RunAndReturn(run func(context.Context, string, []string) (*time.Time, *time.Time, error)) *MockAuditRepository_FindActivityBounds_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindBySessionID provides a mock function with given fields: ctx, sessionID, limit, offset, filter
+func (_m *MockAuditRepository) FindBySessionID(ctx context.Context, sessionID string, limit int, offset int, filter domain.HistoryFilter) ([]domain.AuditEntry, int, error) {
+	ret := _m.Called(ctx, sessionID, limit, offset, filter)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindBySessionID")
@@ -35,25 +387,25 @@ func (_m *MockAuditRepository) FindBySessionID(ctx context.Context, sessionID st
 	var r0 []domain.AuditEntry
 	var r1 int
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) ([]domain.AuditEntry, int, error)); ok {
-		return rf(ctx, sessionID, limit, offset)
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int, domain.HistoryFilter) ([]domain.AuditEntry, int, error)); ok {
+		return rf(ctx, sessionID, limit, offset, filter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []domain.AuditEntry); ok {
-		r0 = rf(ctx, sessionID, limit, offset)
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int, domain.HistoryFilter) []domain.AuditEntry); ok {
+		r0 = rf(ctx, sessionID, limit, offset, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]domain.AuditEntry)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) int); ok {
-		r1 = rf(ctx, sessionID, limit, offset)
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int, domain.HistoryFilter) int); ok {
+		r1 = rf(ctx, sessionID, limit, offset, filter)
 	} else {
 		r1 = ret.Get(1).(int)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, string, int, int) error); ok {
-		r2 = rf(ctx, sessionID, limit, offset)
+	if rf, ok := ret.Get(2).(func(context.Context, string, int, int, domain.HistoryFilter) error); ok {
+		r2 = rf(ctx, sessionID, limit, offset, filter)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -71,13 +423,14 @@ type MockAuditRepository_FindBySessionID_Call struct {
 //   - sessionID string
 //   - limit int
 //   - offset int
-func (_e *MockAuditRepository_Expecter) FindBySessionID(ctx interface{}, sessionID interface{}, limit interface{}, offset interface{}) *MockAuditRepository_FindBySessionID_Call {
-	return &MockAuditRepository_FindBySessionID_Call{Call: _e.mock.On("FindBySessionID", ctx, sessionID, limit, offset)}
+//   - filter domain.HistoryFilter
+func (_e *MockAuditRepository_Expecter) FindBySessionID(ctx interface{}, sessionID interface{}, limit interface{}, offset interface{}, filter interface{}) *MockAuditRepository_FindBySessionID_Call {
+	return &MockAuditRepository_FindBySessionID_Call{Call: _e.mock.On("FindBySessionID", ctx, sessionID, limit, offset, filter)}
 }
 
-func (_c *MockAuditRepository_FindBySessionID_Call) Run(run func(ctx context.Context, sessionID string, limit int, offset int)) *MockAuditRepository_FindBySessionID_Call {
+func (_c *MockAuditRepository_FindBySessionID_Call) Run(run func(ctx context.Context, sessionID string, limit int, offset int, filter domain.HistoryFilter)) *MockAuditRepository_FindBySessionID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(int))
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(int), args[4].(domain.HistoryFilter))
 	})
 	return _c
 }
@@ -87,7 +440,67 @@ func (_c *MockAuditRepository_FindBySessionID_Call) Return(_a0 []domain.AuditEnt
 	return _c
 }
 
-func (_c *MockAuditRepository_FindBySessionID_Call) RunAndReturn(run func(context.Context, string, int, int) ([]domain.AuditEntry, int, error)) *MockAuditRepository_FindBySessionID_Call {
+func (_c *MockAuditRepository_FindBySessionID_Call) RunAndReturn(run func(context.Context, string, int, int, domain.HistoryFilter) ([]domain.AuditEntry, int, error)) *MockAuditRepository_FindBySessionID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetEntry provides a mock function with given fields: ctx, sessionID, entryID
+func (_m *MockAuditRepository) GetEntry(ctx context.Context, sessionID string, entryID string) (*domain.AuditEntry, error) {
+	ret := _m.Called(ctx, sessionID, entryID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEntry")
+	}
+
+	var r0 *domain.AuditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*domain.AuditEntry, error)); ok {
+		return rf(ctx, sessionID, entryID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.AuditEntry); ok {
+		r0 = rf(ctx, sessionID, entryID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, sessionID, entryID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_GetEntry_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetEntry'
+type MockAuditRepository_GetEntry_Call struct {
+	*mock.Call
+}
+
+// GetEntry is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID string
+//   - entryID string
+func (_e *MockAuditRepository_Expecter) GetEntry(ctx interface{}, sessionID interface{}, entryID interface{}) *MockAuditRepository_GetEntry_Call {
+	return &MockAuditRepository_GetEntry_Call{Call: _e.mock.On("GetEntry", ctx, sessionID, entryID)}
+}
+
+func (_c *MockAuditRepository_GetEntry_Call) Run(run func(ctx context.Context, sessionID string, entryID string)) *MockAuditRepository_GetEntry_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_GetEntry_Call) Return(_a0 *domain.AuditEntry, _a1 error) *MockAuditRepository_GetEntry_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditRepository_GetEntry_Call) RunAndReturn(run func(context.Context, string, string) (*domain.AuditEntry, error)) *MockAuditRepository_GetEntry_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -151,8 +564,171 @@ func (_c *MockAuditRepository_GetSession_Call) RunAndReturn(run func(context.Con
 	return _c
 }
 
+// HistoryReads provides a mock function with no fields
+func (_m *MockAuditRepository) HistoryReads() int64 {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for HistoryReads")
+	}
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func() int64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	return r0
+}
+
+// MockAuditRepository_HistoryReads_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HistoryReads'
+type MockAuditRepository_HistoryReads_Call struct {
+	*mock.Call
+}
+
+// HistoryReads is a helper method to define mock.On call
+func (_e *MockAuditRepository_Expecter) HistoryReads() *MockAuditRepository_HistoryReads_Call {
+	return &MockAuditRepository_HistoryReads_Call{Call: _e.mock.On("HistoryReads")}
+}
+
+func (_c *MockAuditRepository_HistoryReads_Call) Run(run func()) *MockAuditRepository_HistoryReads_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_HistoryReads_Call) Return(_a0 int64) *MockAuditRepository_HistoryReads_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAuditRepository_HistoryReads_Call) RunAndReturn(run func() int64) *MockAuditRepository_HistoryReads_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListActiveShares provides a mock function with given fields: ctx, sessionID
+func (_m *MockAuditRepository) ListActiveShares(ctx context.Context, sessionID string) ([]repository.ShareToken, error) {
+	ret := _m.Called(ctx, sessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListActiveShares")
+	}
+
+	var r0 []repository.ShareToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]repository.ShareToken, error)); ok {
+		return rf(ctx, sessionID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []repository.ShareToken); ok {
+		r0 = rf(ctx, sessionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.ShareToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, sessionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_ListActiveShares_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListActiveShares'
+type MockAuditRepository_ListActiveShares_Call struct {
+	*mock.Call
+}
+
+// ListActiveShares is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID string
+func (_e *MockAuditRepository_Expecter) ListActiveShares(ctx interface{}, sessionID interface{}) *MockAuditRepository_ListActiveShares_Call {
+	return &MockAuditRepository_ListActiveShares_Call{Call: _e.mock.On("ListActiveShares", ctx, sessionID)}
+}
+
+func (_c *MockAuditRepository_ListActiveShares_Call) Run(run func(ctx context.Context, sessionID string)) *MockAuditRepository_ListActiveShares_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_ListActiveShares_Call) Return(_a0 []repository.ShareToken, _a1 error) *MockAuditRepository_ListActiveShares_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditRepository_ListActiveShares_Call) RunAndReturn(run func(context.Context, string) ([]repository.ShareToken, error)) *MockAuditRepository_ListActiveShares_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSessionsByUser provides a mock function with given fields: ctx, userID
+func (_m *MockAuditRepository) ListSessionsByUser(ctx context.Context, userID string) ([]domain.SessionSummary, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSessionsByUser")
+	}
+
+	var r0 []domain.SessionSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.SessionSummary, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.SessionSummary); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.SessionSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_ListSessionsByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSessionsByUser'
+type MockAuditRepository_ListSessionsByUser_Call struct {
+	*mock.Call
+}
+
+// ListSessionsByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockAuditRepository_Expecter) ListSessionsByUser(ctx interface{}, userID interface{}) *MockAuditRepository_ListSessionsByUser_Call {
+	return &MockAuditRepository_ListSessionsByUser_Call{Call: _e.mock.On("ListSessionsByUser", ctx, userID)}
+}
+
+func (_c *MockAuditRepository_ListSessionsByUser_Call) Run(run func(ctx context.Context, userID string)) *MockAuditRepository_ListSessionsByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_ListSessionsByUser_Call) Return(_a0 []domain.SessionSummary, _a1 error) *MockAuditRepository_ListSessionsByUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditRepository_ListSessionsByUser_Call) RunAndReturn(run func(context.Context, string) ([]domain.SessionSummary, error)) *MockAuditRepository_ListSessionsByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ValidateShareToken provides a mock function with given fields: ctx, token, sessionID
-func (_m *MockAuditRepository) ValidateShareToken(ctx context.Context, token string, sessionID string) (bool, error) {
+func (_m *MockAuditRepository) ValidateShareToken(ctx context.Context, token string, sessionID string) (bool, time.Time, error) {
 	ret := _m.Called(ctx, token, sessionID)
 
 	if len(ret) == 0 {
@@ -160,8 +736,9 @@ func (_m *MockAuditRepository) ValidateShareToken(ctx context.Context, token str
 	}
 
 	var r0 bool
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, error)); ok {
+	var r1 time.Time
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, time.Time, error)); ok {
 		return rf(ctx, token, sessionID)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
@@ -170,13 +747,19 @@ func (_m *MockAuditRepository) ValidateShareToken(ctx context.Context, token str
 		r0 = ret.Get(0).(bool)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) time.Time); ok {
 		r1 = rf(ctx, token, sessionID)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(time.Time)
 	}
 
-	return r0, r1
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, token, sessionID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // MockAuditRepository_ValidateShareToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateShareToken'
@@ -199,12 +782,72 @@ func (_c *MockAuditRepository_ValidateShareToken_Call) Run(run func(ctx context.
 	return _c
 }
 
-func (_c *MockAuditRepository_ValidateShareToken_Call) Return(_a0 bool, _a1 error) *MockAuditRepository_ValidateShareToken_Call {
+func (_c *MockAuditRepository_ValidateShareToken_Call) Return(_a0 bool, _a1 time.Time, _a2 error) *MockAuditRepository_ValidateShareToken_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockAuditRepository_ValidateShareToken_Call) RunAndReturn(run func(context.Context, string, string) (bool, time.Time, error)) *MockAuditRepository_ValidateShareToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ValidateShareTokens provides a mock function with given fields: ctx, token, sessionIDs
+func (_m *MockAuditRepository) ValidateShareTokens(ctx context.Context, token string, sessionIDs []string) (map[string]time.Time, error) {
+	ret := _m.Called(ctx, token, sessionIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateShareTokens")
+	}
+
+	var r0 map[string]time.Time
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) (map[string]time.Time, error)); ok {
+		return rf(ctx, token, sessionIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) map[string]time.Time); ok {
+		r0 = rf(ctx, token, sessionIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]time.Time)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, token, sessionIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_ValidateShareTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateShareTokens'
+type MockAuditRepository_ValidateShareTokens_Call struct {
+	*mock.Call
+}
+
+// ValidateShareTokens is a helper method to define mock.On call
+//   - ctx context.Context
+//   - token string
+//   - sessionIDs []string
+func (_e *MockAuditRepository_Expecter) ValidateShareTokens(ctx interface{}, token interface{}, sessionIDs interface{}) *MockAuditRepository_ValidateShareTokens_Call {
+	return &MockAuditRepository_ValidateShareTokens_Call{Call: _e.mock.On("ValidateShareTokens", ctx, token, sessionIDs)}
+}
+
+func (_c *MockAuditRepository_ValidateShareTokens_Call) Run(run func(ctx context.Context, token string, sessionIDs []string)) *MockAuditRepository_ValidateShareTokens_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]string))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_ValidateShareTokens_Call) Return(_a0 map[string]time.Time, _a1 error) *MockAuditRepository_ValidateShareTokens_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockAuditRepository_ValidateShareToken_Call) RunAndReturn(run func(context.Context, string, string) (bool, error)) *MockAuditRepository_ValidateShareToken_Call {
+func (_c *MockAuditRepository_ValidateShareTokens_Call) RunAndReturn(run func(context.Context, string, []string) (map[string]time.Time, error)) *MockAuditRepository_ValidateShareTokens_Call {
 	_c.Call.Return(run)
 	return _c
 }
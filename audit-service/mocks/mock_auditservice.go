@@ -5,8 +5,11 @@ package mocks
 import (
 	domain "audit-service/internal/domain"
 	context "context"
+	io "io"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // MockAuditService is an autogenerated mock type for the AuditService type
@@ -22,9 +25,201 @@ func (_m *MockAuditService) EXPECT() *MockAuditService_Expecter {
 	return &MockAuditService_Expecter{mock: &_m.Mock}
 }
 
-// GetAuditLogs provides a mock function with given fields: ctx, sessionID, userID, isShareToken, pagination
-func (_m *MockAuditService) GetAuditLogs(ctx context.Context, sessionID string, userID string, isShareToken bool, pagination domain.PaginationParams) (*domain.AuditResponse, error) {
-	ret := _m.Called(ctx, sessionID, userID, isShareToken, pagination)
+// CreateAuditEntries provides a mock function with given fields: ctx, sessionID, userID, reqs
+func (_m *MockAuditService) CreateAuditEntries(ctx context.Context, sessionID string, userID string, reqs []domain.CreateAuditEntryRequest) ([]domain.AuditEntry, error) {
+	ret := _m.Called(ctx, sessionID, userID, reqs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAuditEntries")
+	}
+
+	var r0 []domain.AuditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []domain.CreateAuditEntryRequest) ([]domain.AuditEntry, error)); ok {
+		return rf(ctx, sessionID, userID, reqs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []domain.CreateAuditEntryRequest) []domain.AuditEntry); ok {
+		r0 = rf(ctx, sessionID, userID, reqs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, []domain.CreateAuditEntryRequest) error); ok {
+		r1 = rf(ctx, sessionID, userID, reqs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditService_CreateAuditEntries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAuditEntries'
+type MockAuditService_CreateAuditEntries_Call struct {
+	*mock.Call
+}
+
+// CreateAuditEntries is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID string
+//   - userID string
+//   - reqs []domain.CreateAuditEntryRequest
+func (_e *MockAuditService_Expecter) CreateAuditEntries(ctx interface{}, sessionID interface{}, userID interface{}, reqs interface{}) *MockAuditService_CreateAuditEntries_Call {
+	return &MockAuditService_CreateAuditEntries_Call{Call: _e.mock.On("CreateAuditEntries", ctx, sessionID, userID, reqs)}
+}
+
+func (_c *MockAuditService_CreateAuditEntries_Call) Run(run func(ctx context.Context, sessionID string, userID string, reqs []domain.CreateAuditEntryRequest)) *MockAuditService_CreateAuditEntries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].([]domain.CreateAuditEntryRequest))
+	})
+	return _c
+}
+
+func (_c *MockAuditService_CreateAuditEntries_Call) Return(_a0 []domain.AuditEntry, _a1 error) *MockAuditService_CreateAuditEntries_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditService_CreateAuditEntries_Call) RunAndReturn(run func(context.Context, string, string, []domain.CreateAuditEntryRequest) ([]domain.AuditEntry, error)) *MockAuditService_CreateAuditEntries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateAuditEntry provides a mock function with given fields: ctx, sessionID, userID, idempotencyKey, req
+func (_m *MockAuditService) CreateAuditEntry(ctx context.Context, sessionID string, userID string, idempotencyKey string, req domain.CreateAuditEntryRequest) (*domain.AuditEntry, bool, error) {
+	ret := _m.Called(ctx, sessionID, userID, idempotencyKey, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAuditEntry")
+	}
+
+	var r0 *domain.AuditEntry
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, domain.CreateAuditEntryRequest) (*domain.AuditEntry, bool, error)); ok {
+		return rf(ctx, sessionID, userID, idempotencyKey, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, domain.CreateAuditEntryRequest) *domain.AuditEntry); ok {
+		r0 = rf(ctx, sessionID, userID, idempotencyKey, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, domain.CreateAuditEntryRequest) bool); ok {
+		r1 = rf(ctx, sessionID, userID, idempotencyKey, req)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, string, domain.CreateAuditEntryRequest) error); ok {
+		r2 = rf(ctx, sessionID, userID, idempotencyKey, req)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockAuditService_CreateAuditEntry_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAuditEntry'
+type MockAuditService_CreateAuditEntry_Call struct {
+	*mock.Call
+}
+
+// CreateAuditEntry is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID string
+//   - userID string
+//   - idempotencyKey string
+//   - req domain.CreateAuditEntryRequest
+func (_e *MockAuditService_Expecter) CreateAuditEntry(ctx interface{}, sessionID interface{}, userID interface{}, idempotencyKey interface{}, req interface{}) *MockAuditService_CreateAuditEntry_Call {
+	return &MockAuditService_CreateAuditEntry_Call{Call: _e.mock.On("CreateAuditEntry", ctx, sessionID, userID, idempotencyKey, req)}
+}
+
+func (_c *MockAuditService_CreateAuditEntry_Call) Run(run func(ctx context.Context, sessionID string, userID string, idempotencyKey string, req domain.CreateAuditEntryRequest)) *MockAuditService_CreateAuditEntry_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].(domain.CreateAuditEntryRequest))
+	})
+	return _c
+}
+
+func (_c *MockAuditService_CreateAuditEntry_Call) Return(_a0 *domain.AuditEntry, _a1 bool, _a2 error) *MockAuditService_CreateAuditEntry_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockAuditService_CreateAuditEntry_Call) RunAndReturn(run func(context.Context, string, string, string, domain.CreateAuditEntryRequest) (*domain.AuditEntry, bool, error)) *MockAuditService_CreateAuditEntry_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAuditEntry provides a mock function with given fields: ctx, sessionID, userID, isShareToken, entryID
+func (_m *MockAuditService) GetAuditEntry(ctx context.Context, sessionID string, userID string, isShareToken bool, entryID string) (*domain.AuditEntry, error) {
+	ret := _m.Called(ctx, sessionID, userID, isShareToken, entryID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAuditEntry")
+	}
+
+	var r0 *domain.AuditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool, string) (*domain.AuditEntry, error)); ok {
+		return rf(ctx, sessionID, userID, isShareToken, entryID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool, string) *domain.AuditEntry); ok {
+		r0 = rf(ctx, sessionID, userID, isShareToken, entryID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, bool, string) error); ok {
+		r1 = rf(ctx, sessionID, userID, isShareToken, entryID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditService_GetAuditEntry_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAuditEntry'
+type MockAuditService_GetAuditEntry_Call struct {
+	*mock.Call
+}
+
+// GetAuditEntry is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID string
+//   - userID string
+//   - isShareToken bool
+//   - entryID string
+func (_e *MockAuditService_Expecter) GetAuditEntry(ctx interface{}, sessionID interface{}, userID interface{}, isShareToken interface{}, entryID interface{}) *MockAuditService_GetAuditEntry_Call {
+	return &MockAuditService_GetAuditEntry_Call{Call: _e.mock.On("GetAuditEntry", ctx, sessionID, userID, isShareToken, entryID)}
+}
+
+func (_c *MockAuditService_GetAuditEntry_Call) Run(run func(ctx context.Context, sessionID string, userID string, isShareToken bool, entryID string)) *MockAuditService_GetAuditEntry_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(bool), args[4].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuditService_GetAuditEntry_Call) Return(_a0 *domain.AuditEntry, _a1 error) *MockAuditService_GetAuditEntry_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditService_GetAuditEntry_Call) RunAndReturn(run func(context.Context, string, string, bool, string) (*domain.AuditEntry, error)) *MockAuditService_GetAuditEntry_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAuditLogs provides a mock function with given fields: ctx, sessionID, userID, isShareToken, pagination, filter
+func (_m *MockAuditService) GetAuditLogs(ctx context.Context, sessionID string, userID string, isShareToken bool, pagination domain.PaginationParams, filter domain.HistoryFilter) (*domain.AuditResponse, error) {
+	ret := _m.Called(ctx, sessionID, userID, isShareToken, pagination, filter)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetAuditLogs")
@@ -32,19 +227,19 @@ func (_m *MockAuditService) GetAuditLogs(ctx context.Context, sessionID string,
 
 	var r0 *domain.AuditResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool, domain.PaginationParams) (*domain.AuditResponse, error)); ok {
-		return rf(ctx, sessionID, userID, isShareToken, pagination)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool, domain.PaginationParams, domain.HistoryFilter) (*domain.AuditResponse, error)); ok {
+		return rf(ctx, sessionID, userID, isShareToken, pagination, filter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool, domain.PaginationParams) *domain.AuditResponse); ok {
-		r0 = rf(ctx, sessionID, userID, isShareToken, pagination)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool, domain.PaginationParams, domain.HistoryFilter) *domain.AuditResponse); ok {
+		r0 = rf(ctx, sessionID, userID, isShareToken, pagination, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.AuditResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string, bool, domain.PaginationParams) error); ok {
-		r1 = rf(ctx, sessionID, userID, isShareToken, pagination)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, bool, domain.PaginationParams, domain.HistoryFilter) error); ok {
+		r1 = rf(ctx, sessionID, userID, isShareToken, pagination, filter)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -63,13 +258,14 @@ type MockAuditService_GetAuditLogs_Call struct {
 //   - userID string
 //   - isShareToken bool
 //   - pagination domain.PaginationParams
-func (_e *MockAuditService_Expecter) GetAuditLogs(ctx interface{}, sessionID interface{}, userID interface{}, isShareToken interface{}, pagination interface{}) *MockAuditService_GetAuditLogs_Call {
-	return &MockAuditService_GetAuditLogs_Call{Call: _e.mock.On("GetAuditLogs", ctx, sessionID, userID, isShareToken, pagination)}
+//   - filter domain.HistoryFilter
+func (_e *MockAuditService_Expecter) GetAuditLogs(ctx interface{}, sessionID interface{}, userID interface{}, isShareToken interface{}, pagination interface{}, filter interface{}) *MockAuditService_GetAuditLogs_Call {
+	return &MockAuditService_GetAuditLogs_Call{Call: _e.mock.On("GetAuditLogs", ctx, sessionID, userID, isShareToken, pagination, filter)}
 }
 
-func (_c *MockAuditService_GetAuditLogs_Call) Run(run func(ctx context.Context, sessionID string, userID string, isShareToken bool, pagination domain.PaginationParams)) *MockAuditService_GetAuditLogs_Call {
+func (_c *MockAuditService_GetAuditLogs_Call) Run(run func(ctx context.Context, sessionID string, userID string, isShareToken bool, pagination domain.PaginationParams, filter domain.HistoryFilter)) *MockAuditService_GetAuditLogs_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(bool), args[4].(domain.PaginationParams))
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(bool), args[4].(domain.PaginationParams), args[5].(domain.HistoryFilter))
 	})
 	return _c
 }
@@ -79,7 +275,309 @@ func (_c *MockAuditService_GetAuditLogs_Call) Return(_a0 *domain.AuditResponse,
 	return _c
 }
 
-func (_c *MockAuditService_GetAuditLogs_Call) RunAndReturn(run func(context.Context, string, string, bool, domain.PaginationParams) (*domain.AuditResponse, error)) *MockAuditService_GetAuditLogs_Call {
+func (_c *MockAuditService_GetAuditLogs_Call) RunAndReturn(run func(context.Context, string, string, bool, domain.PaginationParams, domain.HistoryFilter) (*domain.AuditResponse, error)) *MockAuditService_GetAuditLogs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSummary provides a mock function with given fields: ctx, sessionID, userID, isShareToken, window
+func (_m *MockAuditService) GetSummary(ctx context.Context, sessionID string, userID string, isShareToken bool, window string) (*domain.AuditSummary, error) {
+	ret := _m.Called(ctx, sessionID, userID, isShareToken, window)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSummary")
+	}
+
+	var r0 *domain.AuditSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool, string) (*domain.AuditSummary, error)); ok {
+		return rf(ctx, sessionID, userID, isShareToken, window)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool, string) *domain.AuditSummary); ok {
+		r0 = rf(ctx, sessionID, userID, isShareToken, window)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.AuditSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, bool, string) error); ok {
+		r1 = rf(ctx, sessionID, userID, isShareToken, window)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditService_GetSummary_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSummary'
+type MockAuditService_GetSummary_Call struct {
+	*mock.Call
+}
+
+// GetSummary is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID string
+//   - userID string
+//   - isShareToken bool
+//   - window string
+func (_e *MockAuditService_Expecter) GetSummary(ctx interface{}, sessionID interface{}, userID interface{}, isShareToken interface{}, window interface{}) *MockAuditService_GetSummary_Call {
+	return &MockAuditService_GetSummary_Call{Call: _e.mock.On("GetSummary", ctx, sessionID, userID, isShareToken, window)}
+}
+
+func (_c *MockAuditService_GetSummary_Call) Run(run func(ctx context.Context, sessionID string, userID string, isShareToken bool, window string)) *MockAuditService_GetSummary_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(bool), args[4].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuditService_GetSummary_Call) Return(_a0 *domain.AuditSummary, _a1 error) *MockAuditService_GetSummary_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditService_GetSummary_Call) RunAndReturn(run func(context.Context, string, string, bool, string) (*domain.AuditSummary, error)) *MockAuditService_GetSummary_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ImportAuditEntries provides a mock function with given fields: ctx, sessionID, r
+func (_m *MockAuditService) ImportAuditEntries(ctx context.Context, sessionID string, r io.Reader) (*domain.ImportSummary, error) {
+	ret := _m.Called(ctx, sessionID, r)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportAuditEntries")
+	}
+
+	var r0 *domain.ImportSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, io.Reader) (*domain.ImportSummary, error)); ok {
+		return rf(ctx, sessionID, r)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, io.Reader) *domain.ImportSummary); ok {
+		r0 = rf(ctx, sessionID, r)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ImportSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, io.Reader) error); ok {
+		r1 = rf(ctx, sessionID, r)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditService_ImportAuditEntries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ImportAuditEntries'
+type MockAuditService_ImportAuditEntries_Call struct {
+	*mock.Call
+}
+
+// ImportAuditEntries is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID string
+//   - r io.Reader
+func (_e *MockAuditService_Expecter) ImportAuditEntries(ctx interface{}, sessionID interface{}, r interface{}) *MockAuditService_ImportAuditEntries_Call {
+	return &MockAuditService_ImportAuditEntries_Call{Call: _e.mock.On("ImportAuditEntries", ctx, sessionID, r)}
+}
+
+func (_c *MockAuditService_ImportAuditEntries_Call) Run(run func(ctx context.Context, sessionID string, r io.Reader)) *MockAuditService_ImportAuditEntries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(io.Reader))
+	})
+	return _c
+}
+
+func (_c *MockAuditService_ImportAuditEntries_Call) Return(_a0 *domain.ImportSummary, _a1 error) *MockAuditService_ImportAuditEntries_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditService_ImportAuditEntries_Call) RunAndReturn(run func(context.Context, string, io.Reader) (*domain.ImportSummary, error)) *MockAuditService_ImportAuditEntries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSessions provides a mock function with given fields: ctx, userID
+func (_m *MockAuditService) ListSessions(ctx context.Context, userID string) ([]domain.SessionSummary, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSessions")
+	}
+
+	var r0 []domain.SessionSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.SessionSummary, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.SessionSummary); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.SessionSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditService_ListSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSessions'
+type MockAuditService_ListSessions_Call struct {
+	*mock.Call
+}
+
+// ListSessions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockAuditService_Expecter) ListSessions(ctx interface{}, userID interface{}) *MockAuditService_ListSessions_Call {
+	return &MockAuditService_ListSessions_Call{Call: _e.mock.On("ListSessions", ctx, userID)}
+}
+
+func (_c *MockAuditService_ListSessions_Call) Run(run func(ctx context.Context, userID string)) *MockAuditService_ListSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuditService_ListSessions_Call) Return(_a0 []domain.SessionSummary, _a1 error) *MockAuditService_ListSessions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditService_ListSessions_Call) RunAndReturn(run func(context.Context, string) ([]domain.SessionSummary, error)) *MockAuditService_ListSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListShares provides a mock function with given fields: ctx, sessionID, userID
+func (_m *MockAuditService) ListShares(ctx context.Context, sessionID string, userID string) ([]domain.ShareGrant, error) {
+	ret := _m.Called(ctx, sessionID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListShares")
+	}
+
+	var r0 []domain.ShareGrant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]domain.ShareGrant, error)); ok {
+		return rf(ctx, sessionID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []domain.ShareGrant); ok {
+		r0 = rf(ctx, sessionID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ShareGrant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, sessionID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditService_ListShares_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListShares'
+type MockAuditService_ListShares_Call struct {
+	*mock.Call
+}
+
+// ListShares is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID string
+//   - userID string
+func (_e *MockAuditService_Expecter) ListShares(ctx interface{}, sessionID interface{}, userID interface{}) *MockAuditService_ListShares_Call {
+	return &MockAuditService_ListShares_Call{Call: _e.mock.On("ListShares", ctx, sessionID, userID)}
+}
+
+func (_c *MockAuditService_ListShares_Call) Run(run func(ctx context.Context, sessionID string, userID string)) *MockAuditService_ListShares_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuditService_ListShares_Call) Return(_a0 []domain.ShareGrant, _a1 error) *MockAuditService_ListShares_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditService_ListShares_Call) RunAndReturn(run func(context.Context, string, string) ([]domain.ShareGrant, error)) *MockAuditService_ListShares_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WaitForEntries provides a mock function with given fields: ctx, sessionID, userID, isShareToken, filter, wait
+func (_m *MockAuditService) WaitForEntries(ctx context.Context, sessionID string, userID string, isShareToken bool, filter domain.HistoryFilter, wait time.Duration) (bool, error) {
+	ret := _m.Called(ctx, sessionID, userID, isShareToken, filter, wait)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WaitForEntries")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool, domain.HistoryFilter, time.Duration) (bool, error)); ok {
+		return rf(ctx, sessionID, userID, isShareToken, filter, wait)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool, domain.HistoryFilter, time.Duration) bool); ok {
+		r0 = rf(ctx, sessionID, userID, isShareToken, filter, wait)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, bool, domain.HistoryFilter, time.Duration) error); ok {
+		r1 = rf(ctx, sessionID, userID, isShareToken, filter, wait)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditService_WaitForEntries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WaitForEntries'
+type MockAuditService_WaitForEntries_Call struct {
+	*mock.Call
+}
+
+// WaitForEntries is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID string
+//   - userID string
+//   - isShareToken bool
+//   - filter domain.HistoryFilter
+//   - wait time.Duration
+func (_e *MockAuditService_Expecter) WaitForEntries(ctx interface{}, sessionID interface{}, userID interface{}, isShareToken interface{}, filter interface{}, wait interface{}) *MockAuditService_WaitForEntries_Call {
+	return &MockAuditService_WaitForEntries_Call{Call: _e.mock.On("WaitForEntries", ctx, sessionID, userID, isShareToken, filter, wait)}
+}
+
+func (_c *MockAuditService_WaitForEntries_Call) Run(run func(ctx context.Context, sessionID string, userID string, isShareToken bool, filter domain.HistoryFilter, wait time.Duration)) *MockAuditService_WaitForEntries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(bool), args[4].(domain.HistoryFilter), args[5].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockAuditService_WaitForEntries_Call) Return(_a0 bool, _a1 error) *MockAuditService_WaitForEntries_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditService_WaitForEntries_Call) RunAndReturn(run func(context.Context, string, string, bool, domain.HistoryFilter, time.Duration) (bool, error)) *MockAuditService_WaitForEntries_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// IdempotencyStore remembers processed idempotency keys so retried writes can be replayed
+type IdempotencyStore struct {
+	cache *cache.Cache
+	ttl   time.Duration
+}
+
+// IdempotencyRecord stores the outcome of a request made with an idempotency key
+type IdempotencyRecord struct {
+	// Fingerprint identifies the request payload the key was first used with
+	Fingerprint string
+	// Value holds the stored result; nil while the original request is still in flight
+	Value interface{}
+}
+
+// NewIdempotencyStore creates a new idempotency store instance
+func NewIdempotencyStore(ttl, cleanupInterval time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{
+		cache: cache.New(ttl, cleanupInterval),
+		ttl:   ttl,
+	}
+}
+
+// Reserve atomically claims a key for a request fingerprint. If the key was
+// already claimed, the existing record is returned along with false.
+func (s *IdempotencyStore) Reserve(key, fingerprint string) (*IdempotencyRecord, bool) {
+	record := &IdempotencyRecord{Fingerprint: fingerprint}
+	if err := s.cache.Add(key, record, s.ttl); err == nil {
+		return nil, true
+	}
+
+	if val, found := s.cache.Get(key); found {
+		if existing, ok := val.(*IdempotencyRecord); ok {
+			return existing, false
+		}
+	}
+
+	// The key expired between Add and Get; try once more
+	if err := s.cache.Add(key, record, s.ttl); err == nil {
+		return nil, true
+	}
+	return &IdempotencyRecord{}, false
+}
+
+// Complete stores the result for a previously reserved key
+func (s *IdempotencyStore) Complete(key, fingerprint string, value interface{}) {
+	s.cache.Set(key, &IdempotencyRecord{
+		Fingerprint: fingerprint,
+		Value:       value,
+	}, s.ttl)
+}
+
+// Release drops a reservation so the request can be retried
+func (s *IdempotencyStore) Release(key string) {
+	s.cache.Delete(key)
+}
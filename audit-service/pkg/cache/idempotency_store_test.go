@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyStore_ReserveAndComplete(t *testing.T) {
+	store := NewIdempotencyStore(1*time.Minute, 10*time.Minute)
+
+	// First reservation succeeds
+	record, reserved := store.Reserve("key-1", "fingerprint-a")
+	assert.True(t, reserved)
+	assert.Nil(t, record)
+
+	// Second reservation while in flight returns the pending record
+	record, reserved = store.Reserve("key-1", "fingerprint-a")
+	assert.False(t, reserved)
+	assert.Equal(t, "fingerprint-a", record.Fingerprint)
+	assert.Nil(t, record.Value)
+
+	// Completed record is returned on replay
+	store.Complete("key-1", "fingerprint-a", "result")
+	record, reserved = store.Reserve("key-1", "fingerprint-b")
+	assert.False(t, reserved)
+	assert.Equal(t, "fingerprint-a", record.Fingerprint)
+	assert.Equal(t, "result", record.Value)
+}
+
+func TestIdempotencyStore_Release(t *testing.T) {
+	store := NewIdempotencyStore(1*time.Minute, 10*time.Minute)
+
+	_, reserved := store.Reserve("key-1", "fingerprint-a")
+	assert.True(t, reserved)
+
+	store.Release("key-1")
+
+	_, reserved = store.Reserve("key-1", "fingerprint-a")
+	assert.True(t, reserved)
+}
+
+func TestIdempotencyStore_Expiration(t *testing.T) {
+	store := NewIdempotencyStore(50*time.Millisecond, 10*time.Minute)
+
+	store.Complete("key-1", "fingerprint-a", "result")
+	time.Sleep(100 * time.Millisecond)
+
+	record, reserved := store.Reserve("key-1", "fingerprint-b")
+	assert.True(t, reserved)
+	assert.Nil(t, record)
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// ResponseCache keeps recent responses around for a short time. Responses
+// are grouped (e.g. by session) and only the maxGroups most recently used
+// groups are kept, so many distinct groups can't grow the cache without bound.
+type ResponseCache struct {
+	cache     *cache.Cache
+	maxGroups int
+
+	mu sync.Mutex
+	// order lists groups from most to least recently used
+	order  *list.List
+	groups map[string]*list.Element
+}
+
+// responseGroup tracks the keys cached for one group
+type responseGroup struct {
+	name string
+	keys map[string]struct{}
+}
+
+// groupedResponse is what is stored in the underlying cache
+type groupedResponse struct {
+	group string
+	value interface{}
+}
+
+// NewResponseCache creates a new response cache instance holding at most
+// maxGroups groups; maxGroups <= 0 means no limit
+func NewResponseCache(ttl, cleanupInterval time.Duration, maxGroups int) *ResponseCache {
+	return &ResponseCache{
+		cache:     cache.New(ttl, cleanupInterval),
+		maxGroups: maxGroups,
+		order:     list.New(),
+		groups:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the cached response for key, if any, marking its group as recently used
+func (c *ResponseCache) Get(key string) (interface{}, bool) {
+	cached, found := c.cache.Get(key)
+	if !found {
+		return nil, false
+	}
+	entry := cached.(groupedResponse)
+
+	c.mu.Lock()
+	if elem, ok := c.groups[entry.group]; ok {
+		c.order.MoveToFront(elem)
+	}
+	c.mu.Unlock()
+
+	return entry.value, true
+}
+
+// Set stores a response under key in group using the default TTL, evicting
+// the least recently used group when the limit is exceeded
+func (c *ResponseCache) Set(group, key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.groups[group]
+	if ok {
+		c.order.MoveToFront(elem)
+	} else {
+		elem = c.order.PushFront(&responseGroup{name: group, keys: make(map[string]struct{})})
+		c.groups[group] = elem
+	}
+	elem.Value.(*responseGroup).keys[key] = struct{}{}
+	c.cache.Set(key, groupedResponse{group: group, value: value}, cache.DefaultExpiration)
+
+	for c.maxGroups > 0 && c.order.Len() > c.maxGroups {
+		c.evict(c.order.Back())
+	}
+}
+
+// Invalidate drops every response cached for group
+func (c *ResponseCache) Invalidate(group string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.groups[group]; ok {
+		c.evict(elem)
+	}
+}
+
+// Len returns the number of groups currently tracked
+func (c *ResponseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// evict drops a group and all of its responses; the caller must hold mu
+func (c *ResponseCache) evict(elem *list.Element) {
+	group := c.order.Remove(elem).(*responseGroup)
+	delete(c.groups, group.name)
+	for key := range group.keys {
+		c.cache.Delete(key)
+	}
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseCache_SetAndGet(t *testing.T) {
+	c := NewResponseCache(1*time.Minute, 10*time.Minute, 0)
+
+	_, found := c.Get("missing")
+	assert.False(t, found)
+
+	c.Set("session-1", "key-1", "response")
+	value, found := c.Get("key-1")
+	assert.True(t, found)
+	assert.Equal(t, "response", value)
+}
+
+func TestResponseCache_Expiry(t *testing.T) {
+	c := NewResponseCache(20*time.Millisecond, 10*time.Minute, 0)
+
+	c.Set("session-1", "key-1", "response")
+	time.Sleep(30 * time.Millisecond)
+
+	_, found := c.Get("key-1")
+	assert.False(t, found)
+}
+
+func TestResponseCache_EvictsLeastRecentlyUsedGroup(t *testing.T) {
+	c := NewResponseCache(1*time.Minute, 10*time.Minute, 2)
+
+	c.Set("session-1", "session-1|page-1", "a1")
+	c.Set("session-1", "session-1|page-2", "a2")
+	c.Set("session-2", "session-2|page-1", "b1")
+
+	// Reading session-1 makes session-2 the least recently used
+	_, found := c.Get("session-1|page-1")
+	assert.True(t, found)
+
+	c.Set("session-3", "session-3|page-1", "c1")
+
+	assert.Equal(t, 2, c.Len())
+	_, found = c.Get("session-2|page-1")
+	assert.False(t, found, "least recently used session should be evicted")
+	for _, key := range []string{"session-1|page-1", "session-1|page-2", "session-3|page-1"} {
+		_, found = c.Get(key)
+		assert.True(t, found, key)
+	}
+
+	// Adding pages to a cached session doesn't evict anything
+	c.Set("session-3", "session-3|page-2", "c2")
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestResponseCache_Invalidate(t *testing.T) {
+	c := NewResponseCache(1*time.Minute, 10*time.Minute, 0)
+
+	c.Set("session-1", "session-1|page-1", "a1")
+	c.Set("session-1", "session-1|page-2", "a2")
+	c.Set("session-2", "session-2|page-1", "b1")
+
+	c.Invalidate("session-1")
+	c.Invalidate("session-unknown")
+
+	for _, key := range []string{"session-1|page-1", "session-1|page-2"} {
+		_, found := c.Get(key)
+		assert.False(t, found, key)
+	}
+	_, found := c.Get("session-2|page-1")
+	assert.True(t, found, "other sessions keep their pages")
+	assert.Equal(t, 1, c.Len())
+}
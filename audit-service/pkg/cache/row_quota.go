@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// RowQuota counts rows served per key (e.g. a share token fingerprint) over a
+// fixed window that starts with the first rows counted for the key
+type RowQuota struct {
+	cache   *cache.Cache
+	maxRows int
+	window  time.Duration
+}
+
+// NewRowQuota creates a quota allowing maxRows rows per key in each window
+func NewRowQuota(maxRows int, window, cleanupInterval time.Duration) *RowQuota {
+	return &RowQuota{
+		cache:   cache.New(window, cleanupInterval),
+		maxRows: maxRows,
+		window:  window,
+	}
+}
+
+// Exceeded reports whether key has used up its rows, and how long until its window resets
+func (q *RowQuota) Exceeded(key string) (time.Duration, bool) {
+	used, expiresAt, found := q.cache.GetWithExpiration(key)
+	if !found || used.(int) < q.maxRows {
+		return 0, false
+	}
+	return time.Until(expiresAt), true
+}
+
+// Add counts rows against key, starting a new window if none is open
+func (q *RowQuota) Add(key string, rows int) {
+	if rows <= 0 {
+		return
+	}
+	if _, err := q.cache.IncrementInt(key, rows); err == nil {
+		return
+	}
+	if err := q.cache.Add(key, rows, q.window); err != nil {
+		// Another request opened the window first
+		_, _ = q.cache.IncrementInt(key, rows)
+	}
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRowQuota_Exceeded(t *testing.T) {
+	q := NewRowQuota(10, time.Minute, 10*time.Minute)
+
+	_, exceeded := q.Exceeded("token-a")
+	assert.False(t, exceeded, "unused key")
+
+	q.Add("token-a", 9)
+	_, exceeded = q.Exceeded("token-a")
+	assert.False(t, exceeded, "one row left")
+
+	q.Add("token-a", 1)
+	retryAfter, exceeded := q.Exceeded("token-a")
+	assert.True(t, exceeded, "cap reached")
+	assert.InDelta(t, time.Minute.Seconds(), retryAfter.Seconds(), 1)
+
+	_, exceeded = q.Exceeded("token-b")
+	assert.False(t, exceeded, "keys are counted separately")
+}
+
+func TestRowQuota_WindowResets(t *testing.T) {
+	q := NewRowQuota(5, 50*time.Millisecond, 10*time.Millisecond)
+
+	q.Add("token-a", 5)
+	_, exceeded := q.Exceeded("token-a")
+	assert.True(t, exceeded)
+
+	time.Sleep(80 * time.Millisecond)
+
+	_, exceeded = q.Exceeded("token-a")
+	assert.False(t, exceeded)
+	q.Add("token-a", 3)
+	_, exceeded = q.Exceeded("token-a")
+	assert.False(t, exceeded, "a new window starts from zero")
+}
+
+func TestRowQuota_ConcurrentAdds(t *testing.T) {
+	q := NewRowQuota(100, time.Minute, 10*time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.Add("token-a", 1)
+		}()
+	}
+	wg.Wait()
+
+	_, exceeded := q.Exceeded("token-a")
+	assert.True(t, exceeded, "no increments are lost")
+}
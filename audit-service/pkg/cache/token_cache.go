@@ -1,11 +1,16 @@
 package cache
 
 import (
+	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/patrickmn/go-cache"
+	"go.uber.org/zap"
 )
 
 // TokenCache provides caching for validated tokens
@@ -13,21 +18,61 @@ type TokenCache struct {
 	cache *cache.Cache
 	jwtTTL time.Duration
 	shareTokenTTL time.Duration
+	expirySkew time.Duration
+
+	// userKeys indexes cached JWT keys by user ID so they can be invalidated together
+	mu       sync.Mutex
+	userKeys map[string]map[string]struct{}
+
+	// evictReasons records why keys are being deleted explicitly; entries
+	// evicted without one have expired. Guarded by mu.
+	evictReasons map[string]string
+	logger       *zap.Logger
 }
 
+// Eviction reasons reported in the debug log
+const (
+	evictExpired      = "expired"
+	evictTokenExpired = "token_expired"
+	evictInvalidated  = "invalidated"
+)
+
 // NewTokenCache creates a new token cache instance
 func NewTokenCache(jwtTTL, shareTokenTTL, cleanupInterval time.Duration) *TokenCache {
-	return &TokenCache{
+	tc := &TokenCache{
 		cache:         cache.New(cache.NoExpiration, cleanupInterval),
 		jwtTTL:        jwtTTL,
 		shareTokenTTL: shareTokenTTL,
+		userKeys:      make(map[string]map[string]struct{}),
+		evictReasons:  make(map[string]string),
+		logger:        zap.NewNop(),
 	}
+	tc.cache.OnEvicted(tc.onEvicted)
+	return tc
+}
+
+// SetExpirySkew skips caching tokens that expire within skew, since they would
+// be rejected again almost immediately
+func (tc *TokenCache) SetExpirySkew(skew time.Duration) {
+	tc.expirySkew = skew
+}
+
+// SetLogger logs evictions and expirations at debug level, identifying
+// entries by a hash of their key rather than the token
+func (tc *TokenCache) SetLogger(logger *zap.Logger) {
+	tc.logger = logger
+}
+
+// expiresWithinSkew reports whether a non-zero expiry falls inside the skew window
+func (tc *TokenCache) expiresWithinSkew(expiresAt time.Time) bool {
+	return !expiresAt.IsZero() && time.Until(expiresAt) <= tc.expirySkew
 }
 
 // CachedTokenInfo stores the validated token information
 type CachedTokenInfo struct {
 	UserID    string
 	SessionID string
+	TokenID   string
 	ExpiresAt time.Time
 }
 
@@ -41,16 +86,95 @@ func (tc *TokenCache) GetJWT(token string) (*CachedTokenInfo, bool) {
 				return info, true
 			}
 			// Remove expired entry
-			tc.cache.Delete(key)
+			tc.deleteKey(key, evictTokenExpired)
 		}
 	}
 	return nil, false
 }
 
-// SetJWT caches a JWT validation result
+// SetJWT caches a JWT validation result unless the token is about to expire
 func (tc *TokenCache) SetJWT(token string, info *CachedTokenInfo) {
+	if tc.expiresWithinSkew(info.ExpiresAt) {
+		return
+	}
+
 	key := tc.getJWTKey(token)
 	tc.cache.Set(key, info, tc.jwtTTL)
+
+	if info.UserID != "" {
+		tc.mu.Lock()
+		if tc.userKeys[info.UserID] == nil {
+			tc.userKeys[info.UserID] = make(map[string]struct{})
+		}
+		tc.userKeys[info.UserID][key] = struct{}{}
+		tc.mu.Unlock()
+	}
+}
+
+// InvalidateUser removes every cached JWT belonging to the user and
+// returns how many were dropped
+func (tc *TokenCache) InvalidateUser(userID string) int {
+	tc.mu.Lock()
+	keys := tc.userKeys[userID]
+	delete(tc.userKeys, userID)
+	tc.mu.Unlock()
+
+	for key := range keys {
+		tc.deleteKey(key, evictInvalidated)
+	}
+	return len(keys)
+}
+
+// deleteKey removes key from the cache, recording why for the eviction log
+func (tc *TokenCache) deleteKey(key, reason string) {
+	tc.mu.Lock()
+	tc.evictReasons[key] = reason
+	tc.mu.Unlock()
+
+	tc.cache.Delete(key)
+
+	// Delete only evicts keys that are present, so don't leave the reason behind
+	tc.mu.Lock()
+	delete(tc.evictReasons, key)
+	tc.mu.Unlock()
+}
+
+// onEvicted logs the eviction and keeps the user index in sync when JWT
+// entries expire or are deleted
+func (tc *TokenCache) onEvicted(key string, value interface{}) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	reason, ok := tc.evictReasons[key]
+	if !ok {
+		reason = evictExpired
+	}
+	delete(tc.evictReasons, key)
+	if ce := tc.logger.Check(zap.DebugLevel, "token cache entry evicted"); ce != nil {
+		keyType, _, _ := strings.Cut(key, ":")
+		// Share keys embed the raw token, so only a hash of the key is logged
+		hash := sha256.Sum256([]byte(key))
+		ce.Write(
+			zap.String("key_type", keyType),
+			zap.String("reason", reason),
+			zap.String("key_hash", fmt.Sprintf("%x", hash[:6])),
+		)
+	}
+
+	if !strings.HasPrefix(key, "jwt:") {
+		return
+	}
+	info, ok := value.(*CachedTokenInfo)
+	if !ok || info.UserID == "" {
+		return
+	}
+
+	if keys, found := tc.userKeys[info.UserID]; found {
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(tc.userKeys, info.UserID)
+		}
+	}
 }
 
 // GetShareToken retrieves a cached share token validation result
@@ -58,28 +182,73 @@ func (tc *TokenCache) GetShareToken(token, sessionID string) (*CachedTokenInfo,
 	key := tc.getShareTokenKey(token, sessionID)
 	if val, found := tc.cache.Get(key); found {
 		if info, ok := val.(*CachedTokenInfo); ok {
-			return info, true
+			// A zero expiry means the token does not expire
+			if info.ExpiresAt.IsZero() || time.Now().Before(info.ExpiresAt) {
+				return info, true
+			}
+			// Remove expired entry
+			tc.deleteKey(key, evictTokenExpired)
 		}
 	}
 	return nil, false
 }
 
-// SetShareToken caches a share token validation result
+// SetShareToken caches a share token validation result. The entry never
+// outlives the token itself, so tokens close to expiry get a shorter TTL.
 func (tc *TokenCache) SetShareToken(token, sessionID string, info *CachedTokenInfo) {
 	key := tc.getShareTokenKey(token, sessionID)
-	tc.cache.Set(key, info, tc.shareTokenTTL)
+
+	ttl := tc.shareTokenTTL
+	if !info.ExpiresAt.IsZero() {
+		remaining := time.Until(info.ExpiresAt)
+		if remaining <= 0 || tc.expiresWithinSkew(info.ExpiresAt) {
+			return
+		}
+		if remaining < ttl {
+			ttl = remaining
+		}
+	}
+
+	tc.cache.Set(key, info, ttl)
 }
 
 // InvalidateJWT removes a JWT from the cache
 func (tc *TokenCache) InvalidateJWT(token string) {
 	key := tc.getJWTKey(token)
-	tc.cache.Delete(key)
+	tc.deleteKey(key, evictInvalidated)
 }
 
 // InvalidateShareToken removes a share token from the cache
 func (tc *TokenCache) InvalidateShareToken(token, sessionID string) {
 	key := tc.getShareTokenKey(token, sessionID)
-	tc.cache.Delete(key)
+	tc.deleteKey(key, evictInvalidated)
+}
+
+// RevokeJWT blocks the token with the given jti until expiresAt.
+// A zero expiresAt keeps the revocation until the process restarts.
+func (tc *TokenCache) RevokeJWT(tokenID string, expiresAt time.Time) {
+	ttl := cache.NoExpiration
+	if !expiresAt.IsZero() {
+		ttl = time.Until(expiresAt)
+		if ttl <= 0 {
+			return
+		}
+	}
+	tc.cache.Set(tc.getRevokedKey(tokenID), true, ttl)
+}
+
+// IsJWTRevoked reports whether the token with the given jti has been revoked
+func (tc *TokenCache) IsJWTRevoked(tokenID string) bool {
+	if tokenID == "" {
+		return false
+	}
+	_, found := tc.cache.Get(tc.getRevokedKey(tokenID))
+	return found
+}
+
+// getRevokedKey generates a cache key for revoked token IDs
+func (tc *TokenCache) getRevokedKey(tokenID string) string {
+	return fmt.Sprintf("revoked:%s", tokenID)
 }
 
 // getJWTKey generates a cache key for JWT tokens
@@ -104,7 +273,28 @@ func (tc *TokenCache) Stats() map[string]interface{} {
 	}
 }
 
+// probeKey is written and read back by Ping. It never expires, so probing
+// doesn't churn the eviction log.
+const probeKey = "probe:ready"
+
+// Ping round-trips a probe entry through the cache for the readiness probe
+func (tc *TokenCache) Ping(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	now := time.Now().UnixNano()
+	tc.cache.Set(probeKey, now, cache.NoExpiration)
+	if value, found := tc.cache.Get(probeKey); !found || value != now {
+		return errors.New("token cache probe entry was not read back")
+	}
+	return nil
+}
+
 // Clear removes all items from the cache
 func (tc *TokenCache) Clear() {
 	tc.cache.Flush()
+
+	tc.mu.Lock()
+	tc.userKeys = make(map[string]map[string]struct{})
+	tc.mu.Unlock()
 } 
\ No newline at end of file
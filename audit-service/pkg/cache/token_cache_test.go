@@ -1,10 +1,14 @@
 package cache
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestNewTokenCache(t *testing.T) {
@@ -77,6 +81,73 @@ func TestTokenCache_ShareToken_Operations(t *testing.T) {
 	assert.Nil(t, info)
 }
 
+func TestTokenCache_ShareToken_TTLTracksExpiry(t *testing.T) {
+	tests := []struct {
+		name        string
+		expiresIn   time.Duration
+		zeroExpiry  bool
+		expectFound bool
+		expectedTTL time.Duration
+	}{
+		{
+			name:        "expiring_before_ttl",
+			expiresIn:   2 * time.Minute,
+			expectFound: true,
+			expectedTTL: 2 * time.Minute,
+		},
+		{
+			name:        "expiring_after_ttl",
+			expiresIn:   24 * time.Hour,
+			expectFound: true,
+			expectedTTL: 5 * time.Minute,
+		},
+		{
+			name:        "no_expiry",
+			zeroExpiry:  true,
+			expectFound: true,
+			expectedTTL: 5 * time.Minute,
+		},
+		{
+			name:        "already_expired",
+			expiresIn:   -1 * time.Minute,
+			expectFound: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewTokenCache(5*time.Minute, 5*time.Minute, 10*time.Minute)
+
+			info := &CachedTokenInfo{SessionID: "session-123"}
+			if !tt.zeroExpiry {
+				info.ExpiresAt = time.Now().Add(tt.expiresIn)
+			}
+			cache.SetShareToken("share-token", "session-123", info)
+
+			_, found := cache.GetShareToken("share-token", "session-123")
+			assert.Equal(t, tt.expectFound, found)
+			if !tt.expectFound {
+				return
+			}
+
+			_, expiration, ok := cache.cache.GetWithExpiration(cache.getShareTokenKey("share-token", "session-123"))
+			assert.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(tt.expectedTTL), expiration, 5*time.Second)
+		})
+	}
+}
+
+func TestTokenCache_ShareToken_ExpiredEntryNotReturned(t *testing.T) {
+	cache := NewTokenCache(5*time.Minute, 5*time.Minute, 10*time.Minute)
+
+	// Store an entry directly so it outlives its token
+	info := &CachedTokenInfo{SessionID: "session-123", ExpiresAt: time.Now().Add(-1 * time.Second)}
+	cache.cache.Set(cache.getShareTokenKey("share-token", "session-123"), info, time.Hour)
+
+	_, found := cache.GetShareToken("share-token", "session-123")
+	assert.False(t, found)
+}
+
 func TestTokenCache_JWT_Expiration(t *testing.T) {
 	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
 	token := "expired-jwt-token"
@@ -94,6 +165,74 @@ func TestTokenCache_JWT_Expiration(t *testing.T) {
 	assert.Nil(t, info)
 }
 
+func TestTokenCache_ExpirySkew(t *testing.T) {
+	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	cache.SetExpirySkew(5 * time.Second)
+
+	// Expires inside the skew window, so it is not cached
+	cache.SetJWT("expiring-jwt", &CachedTokenInfo{UserID: "user-123", ExpiresAt: time.Now().Add(1 * time.Second)})
+	_, found := cache.GetJWT("expiring-jwt")
+	assert.False(t, found)
+	assert.Equal(t, 0, cache.cache.ItemCount())
+
+	cache.SetShareToken("expiring-share", "session-123", &CachedTokenInfo{SessionID: "session-123", ExpiresAt: time.Now().Add(1 * time.Second)})
+	_, found = cache.GetShareToken("expiring-share", "session-123")
+	assert.False(t, found)
+
+	// Expires well outside the skew window
+	cache.SetJWT("fresh-jwt", &CachedTokenInfo{UserID: "user-123", ExpiresAt: time.Now().Add(time.Hour)})
+	_, found = cache.GetJWT("fresh-jwt")
+	assert.True(t, found)
+}
+
+func TestTokenCache_RevokeJWT(t *testing.T) {
+	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+
+	assert.False(t, cache.IsJWTRevoked("jti-1"))
+	assert.False(t, cache.IsJWTRevoked(""))
+
+	cache.RevokeJWT("jti-1", time.Time{})
+	cache.RevokeJWT("jti-2", time.Now().Add(time.Hour))
+	cache.RevokeJWT("jti-3", time.Now().Add(-time.Hour)) // already expired, nothing to block
+
+	assert.True(t, cache.IsJWTRevoked("jti-1"))
+	assert.True(t, cache.IsJWTRevoked("jti-2"))
+	assert.False(t, cache.IsJWTRevoked("jti-3"))
+}
+
+func TestTokenCache_InvalidateUser(t *testing.T) {
+	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	expiresAt := time.Now().Add(time.Hour)
+
+	cache.SetJWT("token-a", &CachedTokenInfo{UserID: "user-1", ExpiresAt: expiresAt})
+	cache.SetJWT("token-b", &CachedTokenInfo{UserID: "user-1", ExpiresAt: expiresAt})
+	cache.SetJWT("token-c", &CachedTokenInfo{UserID: "user-2", ExpiresAt: expiresAt})
+
+	assert.Equal(t, 2, cache.InvalidateUser("user-1"))
+
+	_, found := cache.GetJWT("token-a")
+	assert.False(t, found)
+	_, found = cache.GetJWT("token-b")
+	assert.False(t, found)
+
+	// Other users are unaffected
+	_, found = cache.GetJWT("token-c")
+	assert.True(t, found)
+
+	// Nothing left to invalidate
+	assert.Equal(t, 0, cache.InvalidateUser("user-1"))
+}
+
+func TestTokenCache_InvalidateUser_IndexFollowsEviction(t *testing.T) {
+	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+
+	cache.SetJWT("token-a", &CachedTokenInfo{UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)})
+	cache.InvalidateJWT("token-a")
+
+	assert.Empty(t, cache.userKeys)
+	assert.Equal(t, 0, cache.InvalidateUser("user-1"))
+}
+
 func TestTokenCache_JWTKeyGeneration(t *testing.T) {
 	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
 
@@ -174,3 +313,49 @@ func TestTokenCache_Clear(t *testing.T) {
 	_, found = cache.GetShareToken("share-token", "session1")
 	assert.False(t, found)
 }
+
+func TestTokenCache_Ping(t *testing.T) {
+	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+
+	assert.NoError(t, cache.Ping(context.Background()))
+	// Repeated probes reuse a single entry
+	assert.NoError(t, cache.Ping(context.Background()))
+	assert.Equal(t, 1, cache.Stats()["items"])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, cache.Ping(ctx), context.Canceled)
+}
+
+func TestTokenCache_LogsEvictions(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	cache := NewTokenCache(time.Millisecond, 5*time.Minute, 10*time.Minute)
+	cache.SetLogger(zap.New(core))
+
+	cache.SetJWT("jwt-token", &CachedTokenInfo{UserID: "user1", ExpiresAt: time.Now().Add(time.Hour)})
+	cache.SetShareToken("share-token", "session1", &CachedTokenInfo{SessionID: "session1"})
+
+	// The JWT entry outlives its TTL and is swept by the janitor
+	time.Sleep(5 * time.Millisecond)
+	cache.cache.DeleteExpired()
+	cache.InvalidateShareToken("share-token", "session1")
+	// Nothing cached under this key, so nothing is evicted
+	cache.InvalidateShareToken("other-token", "session1")
+
+	entries := logs.FilterMessage("token cache entry evicted").All()
+	require.Len(t, entries, 2)
+
+	jwtFields := entries[0].ContextMap()
+	assert.Equal(t, "jwt", jwtFields["key_type"])
+	assert.Equal(t, "expired", jwtFields["reason"])
+	assert.Len(t, jwtFields["key_hash"], 12)
+
+	shareFields := entries[1].ContextMap()
+	assert.Equal(t, "share", shareFields["key_type"])
+	assert.Equal(t, "invalidated", shareFields["reason"])
+	assert.NotContains(t, shareFields["key_hash"], "share-token")
+
+	// The user index still follows the eviction
+	assert.Empty(t, cache.userKeys)
+	assert.Empty(t, cache.evictReasons)
+}
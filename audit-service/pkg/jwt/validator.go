@@ -3,8 +3,11 @@ package jwt
 import (
 	"context"
 	"crypto/rsa"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -13,7 +16,8 @@ import (
 // Claims represents the JWT claims we care about
 type Claims struct {
 	jwt.RegisteredClaims
-	UserID string // UserID is populated from Subject claim
+	UserID  string // UserID is populated from Subject claim
+	TokenID string // TokenID is populated from the jti claim
 }
 
 // TokenValidator defines the interface for JWT token validation
@@ -25,6 +29,9 @@ type TokenValidator interface {
 // tokenValidator implements the TokenValidator interface
 type tokenValidator struct {
 	verifyKey *rsa.PublicKey
+	// hmacSecret is the fallback secret for HMAC-signed tokens. It is read on
+	// every validation, so it is swapped atomically rather than guarded by a lock.
+	hmacSecret atomic.Pointer[[]byte]
 }
 
 // NewTokenValidator creates a new JWT token validator
@@ -34,9 +41,9 @@ func NewTokenValidator(jwtSecret string) (TokenValidator, error) {
 	if err != nil {
 		// If RSA parsing fails, try as HMAC secret for backward compatibility
 		// In production, Supabase uses RS256
-		return &tokenValidator{
-			verifyKey: nil,
-		}, nil
+		v := &tokenValidator{}
+		v.SetHMACSecret(jwtSecret)
+		return v, nil
 	}
 
 	return &tokenValidator{
@@ -44,6 +51,26 @@ func NewTokenValidator(jwtSecret string) (TokenValidator, error) {
 	}, nil
 }
 
+// SetHMACSecret replaces the HMAC fallback secret. It is safe to call while
+// tokens are being validated, e.g. to rotate the secret at runtime.
+func (v *tokenValidator) SetHMACSecret(secret string) {
+	key := []byte(secret)
+	v.hmacSecret.Store(&key)
+}
+
+// DecodeBase64Secret decodes an HMAC secret given in base64, as Supabase's
+// legacy JWT secret sometimes is. Standard and URL-safe encodings are
+// accepted, with or without padding.
+func DecodeBase64Secret(secret string) (string, error) {
+	secret = strings.TrimSpace(secret)
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if decoded, err := encoding.DecodeString(secret); err == nil && len(decoded) > 0 {
+			return string(decoded), nil
+		}
+	}
+	return "", errors.New("JWT secret is not valid base64")
+}
+
 // ValidateToken validates a JWT token and returns the claims
 func (v *tokenValidator) ValidateToken(ctx context.Context, tokenString string) (*Claims, error) {
 	// Parse the token
@@ -61,7 +88,11 @@ func (v *tokenValidator) ValidateToken(ctx context.Context, tokenString string)
 				return nil, errors.New("token signed with HMAC but RSA key configured")
 			}
 			// Return the raw secret for HMAC
-			return []byte(jwtSecret), nil
+			secret := v.hmacSecret.Load()
+			if secret == nil || len(*secret) == 0 {
+				return nil, errors.New("no HMAC secret configured")
+			}
+			return *secret, nil
 		default:
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
@@ -97,6 +128,9 @@ func (v *tokenValidator) ValidateToken(ctx context.Context, tokenString string)
 		claims.UserID = claims.Subject
 	}
 
+	// Extract token ID from jti claim
+	claims.TokenID = claims.ID
+
 	return claims, nil
 }
 
@@ -113,11 +147,3 @@ func (v *tokenValidator) ExtractUserID(ctx context.Context, tokenString string)
 
 	return claims.UserID, nil
 }
-
-// For HMAC fallback, we need to store the secret
-var jwtSecret string
-
-// SetHMACSecret sets the HMAC secret for fallback authentication
-func SetHMACSecret(secret string) {
-	jwtSecret = secret
-}
@@ -5,12 +5,15 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Test constants
@@ -115,8 +118,7 @@ func TestTokenValidator_ValidateToken(t *testing.T) {
 	rsaValidator, err := NewTokenValidator(publicKeyPEM)
 	assert.NoError(t, err)
 
-	SetHMACSecret(testHMACSecret)
-	hmacValidator, err := NewTokenValidator("invalid-rsa-key")
+	hmacValidator, err := NewTokenValidator(testHMACSecret)
 	assert.NoError(t, err)
 
 	tests := []struct {
@@ -136,6 +138,7 @@ func TestTokenValidator_ValidateToken(t *testing.T) {
 						ExpiresAt: jwt.NewNumericDate(time.Now().Add(1 * time.Hour)),
 						IssuedAt:  jwt.NewNumericDate(time.Now().Add(-5 * time.Minute)),
 						Issuer:    "test-issuer",
+						ID:        "token-id-123",
 					},
 				}
 				token, _ := createTestRSAToken(claims, privateKey)
@@ -146,7 +149,8 @@ func TestTokenValidator_ValidateToken(t *testing.T) {
 					Subject: testUserID,
 					Issuer:  "test-issuer",
 				},
-				UserID: testUserID,
+				UserID:  testUserID,
+				TokenID: "token-id-123",
 			},
 			expectedError: "",
 		},
@@ -272,6 +276,7 @@ func TestTokenValidator_ValidateToken(t *testing.T) {
 				assert.Equal(t, tt.expectedClaims.Subject, claims.Subject)
 				assert.Equal(t, tt.expectedClaims.UserID, claims.UserID)
 				assert.Equal(t, tt.expectedClaims.Issuer, claims.Issuer)
+				assert.Equal(t, tt.expectedClaims.TokenID, claims.TokenID)
 			}
 		})
 	}
@@ -373,13 +378,114 @@ func TestTokenValidator_ExtractUserID(t *testing.T) {
 	}
 }
 
-func TestSetHMACSecret(t *testing.T) {
-	testSecret := "new-test-secret"
+func TestTokenValidator_SetHMACSecret(t *testing.T) {
+	validator, err := NewTokenValidator(testHMACSecret)
+	assert.NoError(t, err)
+
+	claims := &Claims{RegisteredClaims: jwt.RegisteredClaims{
+		Subject:   testUserID,
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}}
+	oldToken, err := createTestHMACToken(claims, testHMACSecret)
+	assert.NoError(t, err)
+	newToken, err := createTestHMACToken(claims, "new-test-secret")
+	assert.NoError(t, err)
+
+	validator.(*tokenValidator).SetHMACSecret("new-test-secret")
+
+	_, err = validator.ValidateToken(context.Background(), oldToken)
+	assert.Error(t, err)
+	userID, err := validator.ExtractUserID(context.Background(), newToken)
+	assert.NoError(t, err)
+	assert.Equal(t, testUserID, userID)
+}
+
+func TestDecodeBase64Secret(t *testing.T) {
+	// Raw key bytes that aren't valid UTF-8, as a generated HMAC key may be
+	rawSecret := string([]byte{0xfb, 0xff, 0x00, 0x9c, 't', 'e', 's', 't', 0xfe})
 
-	SetHMACSecret(testSecret)
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr bool
+	}{
+		{name: "standard", encoded: base64.StdEncoding.EncodeToString([]byte(rawSecret))},
+		{name: "standard_unpadded", encoded: base64.RawStdEncoding.EncodeToString([]byte(rawSecret))},
+		{name: "url_safe", encoded: base64.URLEncoding.EncodeToString([]byte(rawSecret))},
+		{name: "surrounding_whitespace", encoded: " " + base64.StdEncoding.EncodeToString([]byte(rawSecret)) + "\n"},
+		{name: "not_base64", encoded: "not base64!", wantErr: true},
+		{name: "empty", encoded: "", wantErr: true},
+	}
 
-	// Verify the secret was set by checking it's used in validation
-	assert.Equal(t, testSecret, jwtSecret)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeBase64Secret(tt.encoded)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, rawSecret, decoded)
+		})
+	}
+}
+
+func TestTokenValidator_Base64Secret(t *testing.T) {
+	rawSecret := string([]byte{0xfb, 0xff, 0x00, 0x9c, 't', 'e', 's', 't', 0xfe})
+	encoded := base64.StdEncoding.EncodeToString([]byte(rawSecret))
+
+	claims := &Claims{RegisteredClaims: jwt.RegisteredClaims{
+		Subject:   testUserID,
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}}
+	// Supabase signs with the decoded key
+	token, err := createTestHMACToken(claims, rawSecret)
+	require.NoError(t, err)
+
+	// Using the encoded string as the key fails verification
+	validator, err := NewTokenValidator(encoded)
+	require.NoError(t, err)
+	_, err = validator.ValidateToken(context.Background(), token)
+	assert.Error(t, err)
+
+	decoded, err := DecodeBase64Secret(encoded)
+	require.NoError(t, err)
+	validator, err = NewTokenValidator(decoded)
+	require.NoError(t, err)
+	userID, err := validator.ExtractUserID(context.Background(), token)
+	assert.NoError(t, err)
+	assert.Equal(t, testUserID, userID)
+}
+
+// TestTokenValidator_SetHMACSecret_Concurrent rotates the secret while tokens
+// are validated; run with -race to catch unsynchronized access
+func TestTokenValidator_SetHMACSecret_Concurrent(t *testing.T) {
+	validator, err := NewTokenValidator(testHMACSecret)
+	assert.NoError(t, err)
+	token, err := createTestHMACToken(&Claims{RegisteredClaims: jwt.RegisteredClaims{
+		Subject:   testUserID,
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}}, testHMACSecret)
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				validator.(*tokenValidator).SetHMACSecret(testHMACSecret)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, err := validator.ValidateToken(context.Background(), token)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
 }
 
 func TestClaims(t *testing.T) {
@@ -3,10 +3,64 @@ package logger
 import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-// New creates a new Zap logger instance
-func New(level string) (*zap.Logger, error) {
+// Supported log encodings
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// FileOptions configures optional rotated file output. An empty Path disables it.
+type FileOptions struct {
+	Path       string
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+	Compress   bool
+}
+
+// New creates a new Zap logger instance using the given encoding
+// ("json" or "console"). Unknown formats fall back to JSON. Logs always go
+// to stdout and, when file.Path is set, also to a size-rotated file.
+func New(level, format string, file FileOptions) (*zap.Logger, error) {
+	config := newConfig(level, format)
+
+	var opts []zap.Option
+	if file.Path != "" {
+		fileCore := zapcore.NewCore(
+			zapcore.NewJSONEncoder(config.EncoderConfig),
+			zapcore.AddSync(newRotatingWriter(file)),
+			config.Level,
+		)
+		opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(core, fileCore)
+		}))
+	}
+
+	// Build logger
+	logger, err := config.Build(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return logger, nil
+}
+
+// newRotatingWriter returns a writer that rotates the log file by size and age
+func newRotatingWriter(file FileOptions) *lumberjack.Logger {
+	return &lumberjack.Logger{
+		Filename:   file.Path,
+		MaxSize:    file.MaxSizeMB,
+		MaxBackups: file.MaxBackups,
+		MaxAge:     file.MaxAgeDays,
+		Compress:   file.Compress,
+	}
+}
+
+// newConfig builds the zap configuration for the given level and format
+func newConfig(level, format string) zap.Config {
 	// Parse log level
 	zapLevel, err := zapcore.ParseLevel(level)
 	if err != nil {
@@ -17,7 +71,7 @@ func New(level string) (*zap.Logger, error) {
 	config := zap.Config{
 		Level:       zap.NewAtomicLevelAt(zapLevel),
 		Development: false,
-		Encoding:    "json",
+		Encoding:    FormatJSON,
 		EncoderConfig: zapcore.EncoderConfig{
 			TimeKey:        "timestamp",
 			LevelKey:       "level",
@@ -36,13 +90,13 @@ func New(level string) (*zap.Logger, error) {
 		ErrorOutputPaths: []string{"stderr"},
 	}
 
-	// Build logger
-	logger, err := config.Build()
-	if err != nil {
-		return nil, err
+	// Human-readable output for local development
+	if format == FormatConsole {
+		config.Encoding = FormatConsole
+		config.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
 	}
 
-	return logger, nil
+	return config
 }
 
 // NewDevelopment creates a development logger with console output
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNew_Formats(t *testing.T) {
+	tests := []struct {
+		name       string
+		format     string
+		expectJSON bool
+	}{
+		{name: "json", format: FormatJSON, expectJSON: true},
+		{name: "console", format: FormatConsole, expectJSON: false},
+		{name: "unknown_defaults_to_json", format: "xml", expectJSON: true},
+		{name: "empty_defaults_to_json", format: "", expectJSON: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputPath := filepath.Join(t.TempDir(), "out.log")
+
+			config := newConfig("info", tt.format)
+			config.OutputPaths = []string{outputPath}
+			logger, err := config.Build()
+			require.NoError(t, err)
+
+			logger.Info("hello world")
+			_ = logger.Sync()
+
+			data, err := os.ReadFile(outputPath)
+			require.NoError(t, err)
+			line := strings.TrimSpace(string(data))
+			assert.Contains(t, line, "hello world")
+
+			var entry map[string]interface{}
+			err = json.Unmarshal([]byte(line), &entry)
+			if tt.expectJSON {
+				assert.NoError(t, err)
+				assert.Equal(t, "hello world", entry["message"])
+				assert.Equal(t, "info", entry["level"])
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, line, "INFO")
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	logger, err := New("debug", FormatConsole, FileOptions{})
+	assert.NoError(t, err)
+	assert.NotNil(t, logger)
+}
+
+func TestNew_FileRotation(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "audit.log")
+
+	logger, err := New("info", FormatJSON, FileOptions{
+		Path:       logFile,
+		MaxSizeMB:  1,
+		MaxBackups: 2,
+	})
+	require.NoError(t, err)
+
+	// Write a bit over 1MB so the file has to roll over
+	payload := strings.Repeat("x", 1024)
+	for i := 0; i < 1100; i++ {
+		logger.Info("filler", zap.String("payload", payload))
+	}
+	_ = logger.Sync()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var rolled []string
+	for _, entry := range entries {
+		if entry.Name() != "audit.log" && strings.HasPrefix(entry.Name(), "audit-") {
+			rolled = append(rolled, entry.Name())
+		}
+	}
+	assert.NotEmpty(t, rolled, "expected a rotated log file in %s", dir)
+	assert.FileExists(t, logFile)
+}
@@ -0,0 +1,66 @@
+package timing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recorder accumulates named durations for one request, e.g. time spent
+// waiting on Supabase, so they can be reported in a Server-Timing header
+type Recorder struct {
+	mu    sync.Mutex
+	names []string
+	durs  map[string]time.Duration
+}
+
+// NewRecorder creates an empty recorder
+func NewRecorder() *Recorder {
+	return &Recorder{durs: make(map[string]time.Duration)}
+}
+
+// Add adds d to the total recorded under name
+func (r *Recorder) Add(name string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.durs[name]; !ok {
+		r.names = append(r.names, name)
+	}
+	r.durs[name] += d
+}
+
+// Header formats the recorded durations, in the order first recorded, followed
+// by total as a Server-Timing header value: "supabase;dur=12.3, total;dur=15.0"
+func (r *Recorder) Header(total time.Duration) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	metrics := make([]string, 0, len(r.names)+1)
+	for _, name := range r.names {
+		metrics = append(metrics, formatMetric(name, r.durs[name]))
+	}
+	metrics = append(metrics, formatMetric("total", total))
+	return strings.Join(metrics, ", ")
+}
+
+// formatMetric renders one metric with its duration in milliseconds
+func formatMetric(name string, d time.Duration) string {
+	return fmt.Sprintf("%s;dur=%.1f", name, float64(d)/float64(time.Millisecond))
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying r
+func NewContext(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// Record adds the time since start under name to the recorder in ctx, if any
+func Record(ctx context.Context, name string, start time.Time) {
+	if r, ok := ctx.Value(contextKey{}).(*Recorder); ok {
+		r.Add(name, time.Since(start))
+	}
+}
@@ -0,0 +1,29 @@
+package timing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder_Header(t *testing.T) {
+	r := NewRecorder()
+	r.Add("supabase", 10*time.Millisecond)
+	r.Add("supabase", 2500*time.Microsecond)
+
+	assert.Equal(t, "supabase;dur=12.5, total;dur=15.0", r.Header(15*time.Millisecond))
+	assert.Equal(t, "total;dur=0.3", NewRecorder().Header(300*time.Microsecond))
+}
+
+func TestRecord(t *testing.T) {
+	r := NewRecorder()
+	ctx := NewContext(context.Background(), r)
+
+	Record(ctx, "supabase", time.Now().Add(-5*time.Millisecond))
+	assert.Regexp(t, `^supabase;dur=\d+\.\d, total;dur=1\.0$`, r.Header(time.Millisecond))
+
+	// Contexts without a recorder are ignored
+	Record(context.Background(), "supabase", time.Now())
+}
@@ -0,0 +1,24 @@
+package version
+
+// Build information, overridden at build time with -ldflags "-X"
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string `json:"version" example:"1.0.0"`
+	Commit    string `json:"commit" example:"3f2c1ab"`
+	BuildTime string `json:"buildTime" example:"2024-01-07T12:00:00Z"`
+}
+
+// Get returns the build information of the running binary
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+	}
+}